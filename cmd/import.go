@@ -0,0 +1,69 @@
+package main
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runImport - подкоманда import: загружает CSV-выгрузку Toggl или Clockify из файла и печатает отчёт.
+// Файл "-" читается из stdin
+func runImport(ctx context.Context, args []string, is models.ImportService, out io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+
+	file := flags.String("file", "-", "CSV export of Toggl or Clockify, - for stdin")
+	format := flags.String("format", "", "toggl or clockify, by default detected from the header")
+	tz := flags.String("tz", "", "timezone of the times in the file, default UTC")
+	dryRun := flags.Bool("dry-run", false, "check the file and roll back")
+	createUsers := flags.Bool("create-users", true, "create users that are not found")
+	skipInvalid := flags.Bool("skip-invalid", false, "import the rest of the rows if some cannot be imported")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	importFormat, err := services.ParseImportFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	input := io.Reader(os.Stdin)
+
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		input = f
+	}
+
+	report, importErr := is.ImportEntries(ctx, input, models.ImportOptions{
+		Format:      importFormat,
+		Timezone:    *tz,
+		DryRun:      *dryRun,
+		CreateUsers: *createUsers,
+		SkipInvalid: *skipInvalid,
+	})
+	if importErr != nil && !errors.Is(importErr, services.ErrImportRejected) {
+		return importErr
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("print report: %w", err)
+	}
+
+	return importErr
+}
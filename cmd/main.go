@@ -1,10 +1,14 @@
 package main
 
 import (
+	"EMTask/internal/enrichers"
 	"EMTask/internal/handlers"
 	"EMTask/internal/middleware"
+	"EMTask/internal/models"
 	"EMTask/internal/repos"
 	"EMTask/internal/services"
+	"EMTask/internal/workers"
+	"EMTask/pkg/people"
 	"EMTask/pkg/storage/connect"
 	"EMTask/pkg/storage/migrate"
 	"context"
@@ -13,6 +17,7 @@ import (
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata"
 )
 
 // @title Time Tracker
@@ -52,14 +57,122 @@ func main() {
 		Timeout: time.Second,
 	}
 
+	peopleConfig, err := people.ParseConfig(
+		os.Getenv("API_URL"),
+		os.Getenv("PEOPLE_API_RETRIES"),
+		os.Getenv("PEOPLE_API_BACKOFF"),
+		os.Getenv("PEOPLE_API_BREAKER_THRESHOLD"),
+		os.Getenv("PEOPLE_API_BREAKER_TIMEOUT"),
+	)
+	if err != nil {
+		logger.Fatal("Invalid PEOPLE_API settings: ", err)
+	}
+
+	enricher, err := enrichers.New(
+		os.Getenv("ENRICHERS"),
+		os.Getenv("ENRICH_FILE_PATH"),
+		people.NewClient(peopleConfig, &client),
+	)
+	if err != nil {
+		logger.Fatal("Invalid ENRICHERS settings: ", err)
+	}
+
+	cacheConfig, err := enrichers.ParseCacheConfig(
+		os.Getenv("ENRICH_CACHE_SIZE"),
+		os.Getenv("ENRICH_CACHE_TTL"),
+		os.Getenv("ENRICH_CACHE_NEGATIVE_TTL"),
+		os.Getenv("ENRICH_CACHE_POSTGRES"),
+		os.Getenv("ENRICH_CACHE_SECRET"),
+	)
+	if err != nil {
+		logger.Fatal("Invalid ENRICH_CACHE settings: ", err)
+	}
+
+	timerPolicy, err := services.ParseTimerPolicy(os.Getenv("TIMER_POLICY"))
+	if err != nil {
+		logger.Fatal("Invalid TIMER_POLICY: ", err)
+	}
+
+	autoStopRule, reaperInterval, err := workers.ParseAutoStopRule(
+		os.Getenv("AUTOSTOP_MAX_DURATION"),
+		os.Getenv("AUTOSTOP_CUTOFF"),
+		os.Getenv("AUTOSTOP_INTERVAL"),
+	)
+	if err != nil {
+		logger.Fatal("Invalid AUTOSTOP settings: ", err)
+	}
+
+	enrichmentConfig, err := workers.ParseEnrichmentConfig(
+		os.Getenv("ENRICH_WORKERS"),
+		os.Getenv("ENRICH_MAX_ATTEMPTS"),
+		os.Getenv("ENRICH_INTERVAL"),
+		os.Getenv("ENRICH_BACKOFF"),
+	)
+	if err != nil {
+		logger.Fatal("Invalid ENRICH settings: ", err)
+	}
+
 	userRepo := repos.NewUsersRepository(postgreConn)
 	taskRepo := repos.NewTasksRepository(postgreConn)
+	entriesRepo := repos.NewTimeEntriesRepository(postgreConn)
+	reportRepo := repos.NewReportsRepository(postgreConn)
+	calendarRepo := repos.NewCalendarRepository(postgreConn)
+	projectRepo := repos.NewProjectsRepository(postgreConn)
+	tagRepo := repos.NewTagsRepository(postgreConn)
+	enrichmentRepo := repos.NewEnrichmentRepository(postgreConn)
+	transactor := repos.NewTransactor(postgreConn)
+
+	// обновление юзеров получает enricher до обёртки кэшем ниже: ему нужны свежие данные
+	us := services.NewUserService(userRepo, transactor, enricher)
+	ts := services.NewTaskService(taskRepo, entriesRepo, timerPolicy)
+	rs := services.NewReportService(reportRepo)
+	es := services.NewTimeEntryService(entriesRepo)
+	cs := services.NewCalendarService(calendarRepo)
+	is := services.NewImportService(transactor, userRepo, taskRepo, entriesRepo)
+	ps := services.NewProjectService(projectRepo)
+	tgs := services.NewTagService(tagRepo)
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		err = runImport(context.Background(), os.Args[2:], is, os.Stdout)
+		if err != nil {
+			logger.Fatal("Import failed: ", err)
+		}
+
+		return
+	}
+
+	reaper := workers.NewTimerReaper(entriesRepo, autoStopRule, reaperInterval, logger)
+	if reaper.Enabled() {
+		go reaper.Run(context.Background())
+	}
+
+	var enrichmentCache models.EnrichmentCache
+
+	var store models.EnrichmentCacheRepo
+
+	if cacheConfig.Enabled() {
+		if cacheConfig.Postgres {
+			store = enrichmentRepo
+			go workers.NewCachePurger(store, logger).Run(context.Background())
+		}
+
+		cached := enrichers.NewCachedEnricher(enricher, store, cacheConfig)
+		enricher, enrichmentCache = cached, cached
+	}
 
-	us := services.NewUserService(userRepo)
-	ts := services.NewTaskService(taskRepo)
+	enrichmentWorker := workers.NewEnrichmentWorker(enrichmentRepo, enricher, enrichmentConfig, logger)
+	go enrichmentWorker.Run(context.Background())
 
-	uh := handlers.NewUserHandler(us, logger, &client)
+	uh := handlers.NewUserHandler(us, logger)
 	th := handlers.NewTaskHandler(ts, logger)
+	rh := handlers.NewReportHandler(rs, logger)
+	eh := handlers.NewEntryHandler(es, logger)
+	ch := handlers.NewCalendarHandler(cs, logger)
+	ih := handlers.NewImportHandler(is, logger)
+	clh := handlers.NewClientHandler(ps, logger)
+	ph := handlers.NewProjectHandler(ps, logger)
+	tgh := handlers.NewTagHandler(tgs, logger)
+	enh := handlers.NewEnrichmentHandler(enrichmentCache, logger)
 
 	r := mux.NewRouter()
 
@@ -71,14 +184,62 @@ func main() {
 	r.HandleFunc("/user/{user_id}", uh.DeleteUser).Methods(http.MethodDelete)
 	r.HandleFunc("/user/{user_id}", uh.UpdateUser).Methods(http.MethodPatch)
 	r.HandleFunc("/user", uh.AddUser).Methods(http.MethodPost)
+	r.HandleFunc("/user/{user_id}/refresh", uh.RefreshUser).Methods(http.MethodPost)
+	r.HandleFunc("/users/refresh", uh.RefreshUsers).Methods(http.MethodPost)
+	r.HandleFunc("/enrichment/cache/stats", enh.GetCacheStats).Methods(http.MethodGet)
 
 	r.HandleFunc("/tasks", th.CreateTask).Methods(http.MethodPost)
+	r.HandleFunc("/tasks/over-budget", th.GetOverBudgetTasks).Methods(http.MethodGet)
 	r.HandleFunc("/tasks/{task_id}", th.GetTaskByID).Methods(http.MethodGet)
 	r.HandleFunc("/tasks/{task_id}", th.DeleteTaskByID).Methods(http.MethodDelete)
 	r.HandleFunc("/user/tasks", th.GetUsersTasks).Methods(http.MethodGet)
 	r.HandleFunc("/user/task/track/{user_id}/{task_id}", th.StartTracker).Methods(http.MethodPost)
+	r.HandleFunc("/user/task/pause/{user_id}/{task_id}", th.PauseTracker).Methods(http.MethodPost)
+	r.HandleFunc("/user/task/resume/{user_id}/{task_id}", th.ResumeTracker).Methods(http.MethodPost)
 	r.HandleFunc("/user/task/stop/{user_id}/{task_id}", th.StopTracker).Methods(http.MethodPost)
 	r.HandleFunc("/tasks", th.GetAllTasks).Methods(http.MethodGet)
+	r.HandleFunc("/tasks/{task_id}/project", th.SetTaskProject).Methods(http.MethodPut)
+	r.HandleFunc("/tasks/{task_id}/status", th.SetTaskStatus).Methods(http.MethodPut)
+	r.HandleFunc("/tasks/{task_id}/parent", th.SetTaskParent).Methods(http.MethodPut)
+	r.HandleFunc("/tasks/{task_id}/estimate", th.SetTaskEstimate).Methods(http.MethodPut)
+	r.HandleFunc("/tasks/{task_id}/subtasks", th.CreateSubtask).Methods(http.MethodPost)
+	r.HandleFunc("/tasks/{task_id}/assignees/{user_id}", th.AssignTask).Methods(http.MethodPut)
+	r.HandleFunc("/tasks/{task_id}/assignees/{user_id}", th.UnassignTask).Methods(http.MethodDelete)
+
+	r.HandleFunc("/user/{user_id}/tasks/{task_id}/entries", eh.CreateEntry).Methods(http.MethodPost)
+	r.HandleFunc("/user/{user_id}/entries/{entry_id}", eh.UpdateEntry).Methods(http.MethodPatch)
+	r.HandleFunc("/user/{user_id}/entries/{entry_id}", eh.DeleteEntry).Methods(http.MethodDelete)
+	r.HandleFunc("/timers/active", eh.GetActiveTimers).Methods(http.MethodGet)
+	r.HandleFunc("/users/{user_id}/timers/active", eh.GetUserActiveTimers).Methods(http.MethodGet)
+
+	r.HandleFunc("/users/{user_id}/workload", rh.GetWorkload).Methods(http.MethodGet)
+	r.HandleFunc("/users/{user_id}/summary", rh.GetSummary).Methods(http.MethodGet)
+	r.HandleFunc("/users/{user_id}/timesheet", rh.GetTimesheet).Methods(http.MethodGet)
+	r.HandleFunc("/users/{user_id}/timesheet/pdf", rh.GetTimesheetPDF).Methods(http.MethodGet)
+
+	r.HandleFunc("/users/{user_id}/calendar/token", ch.IssueCalendarToken).Methods(http.MethodPost)
+	r.HandleFunc("/users/{user_id}/calendar/token", ch.RevokeCalendarToken).Methods(http.MethodDelete)
+	r.HandleFunc("/users/{user_id}/calendar.ics", ch.GetCalendar).Methods(http.MethodGet)
+
+	r.HandleFunc("/import/entries", ih.ImportEntries).Methods(http.MethodPost)
+
+	r.HandleFunc("/clients", clh.CreateClient).Methods(http.MethodPost)
+	r.HandleFunc("/clients", clh.GetClients).Methods(http.MethodGet)
+	r.HandleFunc("/clients/{client_id}", clh.GetClientByID).Methods(http.MethodGet)
+	r.HandleFunc("/clients/{client_id}", clh.UpdateClient).Methods(http.MethodPatch)
+	r.HandleFunc("/clients/{client_id}", clh.DeleteClient).Methods(http.MethodDelete)
+
+	r.HandleFunc("/projects", ph.CreateProject).Methods(http.MethodPost)
+	r.HandleFunc("/projects", ph.GetProjects).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{project_id}", ph.GetProjectByID).Methods(http.MethodGet)
+	r.HandleFunc("/projects/{project_id}", ph.UpdateProject).Methods(http.MethodPatch)
+	r.HandleFunc("/projects/{project_id}", ph.DeleteProject).Methods(http.MethodDelete)
+
+	r.HandleFunc("/tags", tgh.CreateTag).Methods(http.MethodPost)
+	r.HandleFunc("/tags", tgh.GetTags).Methods(http.MethodGet)
+	r.HandleFunc("/tags/{tag_id}", tgh.DeleteTag).Methods(http.MethodDelete)
+	r.HandleFunc("/tasks/{task_id}/tags/{tag_id}", tgh.AttachTag).Methods(http.MethodPut)
+	r.HandleFunc("/tasks/{task_id}/tags/{tag_id}", tgh.DetachTag).Methods(http.MethodDelete)
 
 	addr := ":" + os.Getenv("PORT")
 	logger.Infow("starting server",
@@ -0,0 +1,43 @@
+package enrichers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/pkg/people"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// APIEnricher - данные из People API, эндпоинт /info
+type APIEnricher struct {
+	client *people.Client
+}
+
+func NewAPIEnricher(client *people.Client) *APIEnricher {
+	return &APIEnricher{client: client}
+}
+
+// Enrich - 400 от API значит, что такого паспорта там нет. Остальные ошибки клиента временные
+func (ae *APIEnricher) Enrich(ctx context.Context, passport string) (models.APIResponse, error) {
+	serie, number, ok := strings.Cut(passport, " ")
+	if !ok {
+		return models.APIResponse{}, fmt.Errorf("%w: invalid passport number %q", ErrPersonNotFound, passport)
+	}
+
+	person, err := ae.client.Info(ctx, serie, number)
+	if errors.Is(err, people.ErrBadRequest) {
+		return models.APIResponse{}, fmt.Errorf("%w: %w", ErrPersonNotFound, err)
+	}
+
+	if err != nil {
+		return models.APIResponse{}, err
+	}
+
+	return models.APIResponse{
+		Surname:    person.Surname,
+		Name:       person.Name,
+		Patronymic: person.Patronymic,
+		Address:    person.Address,
+	}, nil
+}
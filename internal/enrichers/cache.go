@@ -0,0 +1,282 @@
+package enrichers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultCacheSize        = 1000
+	defaultCacheTTL         = 24 * time.Hour
+	defaultCacheNegativeTTL = time.Hour
+
+	// minCacheSecretLen - секрет ключа кэша короче 128 бит не защищает от перебора паспортов
+	minCacheSecretLen = 16
+)
+
+// CacheConfig - Size записей держится в памяти, вытесняются давно не запрошенные. Найденные люди
+// живут TTL, ненайденные - NegativeTTL. Postgres - дублировать кэш в таблицу enrichment_cache,
+// чтобы он переживал перезапуск и был общим для копий сервиса. Secret - ключ HMAC для ключей кэша
+type CacheConfig struct {
+	Size        int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	Postgres    bool
+	Secret      []byte
+}
+
+// Enabled - TTL 0 выключает кэш
+func (cfg CacheConfig) Enabled() bool {
+	return cfg.TTL > 0
+}
+
+// ParseCacheConfig - разбирает размер кэша, TTL в формате time.ParseDuration, флаг Postgres и секрет.
+// Пустые значения - по умолчанию. Кэшу в Postgres секрет обязателен: ключи должны совпадать у всех копий
+// сервиса и после перезапуска. Кэшу только в памяти без секрета достаётся случайный ключ на время работы
+func ParseCacheConfig(size, ttl, negativeTTL, postgres, secret string) (CacheConfig, error) {
+	cfg := CacheConfig{
+		Size:        defaultCacheSize,
+		TTL:         defaultCacheTTL,
+		NegativeTTL: defaultCacheNegativeTTL,
+	}
+
+	if size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 {
+			return CacheConfig{}, fmt.Errorf("%w: cache size %q", ErrInvalidEnricherConfig, size)
+		}
+
+		cfg.Size = n
+	}
+
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < 0 {
+			return CacheConfig{}, fmt.Errorf("%w: cache ttl %q", ErrInvalidEnricherConfig, ttl)
+		}
+
+		cfg.TTL = d
+	}
+
+	if negativeTTL != "" {
+		d, err := time.ParseDuration(negativeTTL)
+		if err != nil || d < 0 {
+			return CacheConfig{}, fmt.Errorf("%w: cache negative ttl %q", ErrInvalidEnricherConfig, negativeTTL)
+		}
+
+		cfg.NegativeTTL = d
+	}
+
+	if postgres != "" {
+		b, err := strconv.ParseBool(postgres)
+		if err != nil {
+			return CacheConfig{}, fmt.Errorf("%w: cache postgres %q", ErrInvalidEnricherConfig, postgres)
+		}
+
+		cfg.Postgres = b
+	}
+
+	switch {
+	case secret != "" && len(secret) < minCacheSecretLen:
+		return CacheConfig{}, fmt.Errorf("%w: cache secret is shorter than %d bytes", ErrInvalidEnricherConfig, minCacheSecretLen)
+	case secret != "":
+		cfg.Secret = []byte(secret)
+	case cfg.Postgres && cfg.Enabled():
+		return CacheConfig{}, fmt.Errorf("%w: cache secret is required for postgres cache", ErrInvalidEnricherConfig)
+	default:
+		cfg.Secret = make([]byte, sha256.Size)
+
+		_, err := rand.Read(cfg.Secret)
+		if err != nil {
+			return CacheConfig{}, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// PassportHash - ключ кэша: HMAC-SHA256 от серии и номера паспорта без пробелов. Без секрета
+// номер по ключу не подобрать, хотя паспортов всего 10^10
+func PassportHash(secret []byte, passport string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(strings.Fields(passport), "")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CachedEnricher - кэш перед другим enricher. Ошибки кроме ErrPersonNotFound не кэшируются,
+// а сбои таблицы кэша только считаются и не мешают сходить к провайдеру
+type CachedEnricher struct {
+	next  models.Enricher
+	store models.EnrichmentCacheRepo
+	cfg   CacheConfig
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+	storeErrors  atomic.Int64
+}
+
+// NewCachedEnricher - store может быть nil, тогда кэш только в памяти
+func NewCachedEnricher(next models.Enricher, store models.EnrichmentCacheRepo, cfg CacheConfig) *CachedEnricher {
+	return &CachedEnricher{
+		next:  next,
+		store: store,
+		cfg:   cfg,
+		lru:   list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (ce *CachedEnricher) Enrich(ctx context.Context, passport string) (models.APIResponse, error) {
+	hash := PassportHash(ce.cfg.Secret, passport)
+
+	entry, ok := ce.lookup(ctx, hash)
+	if ok {
+		if entry.NotFound {
+			ce.negativeHits.Add(1)
+			return models.APIResponse{}, fmt.Errorf("%w: cached", ErrPersonNotFound)
+		}
+
+		ce.hits.Add(1)
+
+		return entry.Person, nil
+	}
+
+	ce.misses.Add(1)
+
+	person, err := ce.next.Enrich(ctx, passport)
+
+	switch {
+	case err == nil:
+		ce.save(ctx, models.EnrichmentCacheEntry{
+			PassportHash: hash,
+			Person:       person,
+			ExpiresAt:    time.Now().Add(ce.cfg.TTL),
+		})
+	case errors.Is(err, ErrPersonNotFound) && ce.cfg.NegativeTTL > 0:
+		ce.save(ctx, models.EnrichmentCacheEntry{
+			PassportHash: hash,
+			NotFound:     true,
+			ExpiresAt:    time.Now().Add(ce.cfg.NegativeTTL),
+		})
+	}
+
+	return person, err
+}
+
+// Stats - счётчики для GET /enrichment/cache/stats
+func (ce *CachedEnricher) Stats() models.EnrichmentCacheStats {
+	stats := models.EnrichmentCacheStats{
+		Hits:         ce.hits.Load(),
+		NegativeHits: ce.negativeHits.Load(),
+		Misses:       ce.misses.Load(),
+		StoreErrors:  ce.storeErrors.Load(),
+	}
+
+	total := stats.Hits + stats.NegativeHits + stats.Misses
+	if total > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+
+	ce.mu.Lock()
+	stats.Size = ce.lru.Len()
+	ce.mu.Unlock()
+
+	return stats
+}
+
+// lookup - сначала память, затем таблица. Найденное в таблице поднимается в память
+func (ce *CachedEnricher) lookup(ctx context.Context, hash string) (models.EnrichmentCacheEntry, bool) {
+	entry, ok := ce.memoryGet(hash)
+	if ok || ce.store == nil {
+		return entry, ok
+	}
+
+	entry, err := ce.store.FindEnrichmentCache(ctx, hash)
+	if err != nil {
+		if !errors.Is(err, repos.ErrEnrichmentCacheMiss) {
+			ce.storeErrors.Add(1)
+		}
+
+		return models.EnrichmentCacheEntry{}, false
+	}
+
+	ce.memoryPut(entry)
+
+	return entry, true
+}
+
+func (ce *CachedEnricher) save(ctx context.Context, entry models.EnrichmentCacheEntry) {
+	ce.memoryPut(entry)
+
+	if ce.store == nil {
+		return
+	}
+
+	err := ce.store.SaveEnrichmentCache(ctx, entry)
+	if err != nil {
+		ce.storeErrors.Add(1)
+	}
+}
+
+func (ce *CachedEnricher) memoryGet(hash string) (models.EnrichmentCacheEntry, bool) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	elem, ok := ce.items[hash]
+	if !ok {
+		return models.EnrichmentCacheEntry{}, false
+	}
+
+	entry := elem.Value.(models.EnrichmentCacheEntry)
+
+	if !time.Now().Before(entry.ExpiresAt) {
+		ce.lru.Remove(elem)
+		delete(ce.items, hash)
+
+		return models.EnrichmentCacheEntry{}, false
+	}
+
+	ce.lru.MoveToFront(elem)
+
+	return entry, true
+}
+
+func (ce *CachedEnricher) memoryPut(entry models.EnrichmentCacheEntry) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	elem, ok := ce.items[entry.PassportHash]
+	if ok {
+		elem.Value = entry
+		ce.lru.MoveToFront(elem)
+
+		return
+	}
+
+	ce.items[entry.PassportHash] = ce.lru.PushFront(entry)
+
+	for ce.lru.Len() > ce.cfg.Size {
+		oldest := ce.lru.Back()
+		ce.lru.Remove(oldest)
+		delete(ce.items, oldest.Value.(models.EnrichmentCacheEntry).PassportHash)
+	}
+}
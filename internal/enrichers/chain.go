@@ -0,0 +1,43 @@
+package enrichers
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"errors"
+)
+
+// Chain - опрашивает провайдеров по порядку и отдаёт ответ первого, кто знает паспорт
+type Chain []models.Enricher
+
+// Enrich - если человека не нашёл никто, ошибка - ErrPersonNotFound. Если хоть один провайдер
+// был недоступен, отдаются только такие ошибки: при повторе человек может найтись
+func (c Chain) Enrich(ctx context.Context, passport string) (models.APIResponse, error) {
+	var notFound, unavailable []error
+
+	for _, enricher := range c {
+		person, err := enricher.Enrich(ctx, passport)
+		if err == nil {
+			return person, nil
+		}
+
+		if ctx.Err() != nil {
+			return models.APIResponse{}, ctx.Err()
+		}
+
+		if errors.Is(err, ErrPersonNotFound) {
+			notFound = append(notFound, err)
+		} else {
+			unavailable = append(unavailable, err)
+		}
+	}
+
+	if len(unavailable) > 0 {
+		return models.APIResponse{}, errors.Join(unavailable...)
+	}
+
+	if len(notFound) == 0 {
+		return models.APIResponse{}, ErrPersonNotFound
+	}
+
+	return models.APIResponse{}, errors.Join(notFound...)
+}
@@ -0,0 +1,59 @@
+package enrichers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/pkg/people"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ProviderAPI  = "api"
+	ProviderFile = "file"
+
+	defaultProviders = ProviderAPI
+)
+
+var (
+	// ErrPersonNotFound - провайдер точно не знает этот паспорт, повтор не поможет
+	ErrPersonNotFound        = errors.New("person not found")
+	ErrInvalidEnricherConfig = errors.New("invalid enricher config")
+)
+
+// New - источник данных юзеров по списку провайдеров через запятую, например "file,api".
+// Несколько провайдеров опрашиваются цепочкой в указанном порядке. Пустой список - только People API.
+// filePath нужен провайдеру file: CSV-файл или каталог с CSV-файлами
+func New(providers, filePath string, client *people.Client) (models.Enricher, error) {
+	if strings.TrimSpace(providers) == "" {
+		providers = defaultProviders
+	}
+
+	var chain Chain
+
+	for _, name := range strings.Split(providers, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case ProviderAPI:
+			if client == nil {
+				return nil, fmt.Errorf("%w: provider %q needs a people api client", ErrInvalidEnricherConfig, name)
+			}
+
+			chain = append(chain, NewAPIEnricher(client))
+		case ProviderFile:
+			fe, err := NewFileEnricher(filePath)
+			if err != nil {
+				return nil, err
+			}
+
+			chain = append(chain, fe)
+		default:
+			return nil, fmt.Errorf("%w: unknown provider %q", ErrInvalidEnricherConfig, name)
+		}
+	}
+
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,138 @@
+package enrichers
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Колонки CSV-справочника. patronymic необязательна
+const (
+	fileColPassport   = "passport_number"
+	fileColSurname    = "surname"
+	fileColName       = "name"
+	fileColPatronymic = "patronymic"
+	fileColAddress    = "address"
+)
+
+// FileEnricher - данные из CSV-справочника для окружений без доступа к People API.
+// Справочник читается целиком при старте, изменения файлов подхватываются только перезапуском
+type FileEnricher struct {
+	people map[string]models.APIResponse
+}
+
+// NewFileEnricher - path - CSV-файл или каталог, из которого читаются все *.csv по алфавиту.
+// Один паспорт в двух строках - ошибка конфигурации
+func NewFileEnricher(path string) (*FileEnricher, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: file provider needs a path", ErrInvalidEnricherConfig)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnricherConfig, err)
+	}
+
+	files := []string{path}
+
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.csv"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEnricherConfig, err)
+		}
+	}
+
+	fe := &FileEnricher{people: map[string]models.APIResponse{}}
+
+	for _, name := range files {
+		err = fe.load(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fe, nil
+}
+
+func (fe *FileEnricher) load(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnricherConfig, err)
+	}
+
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidEnricherConfig, name, err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, col := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\xEF\xBB\xBF")))] = i
+	}
+
+	for _, col := range []string{fileColPassport, fileColSurname, fileColName, fileColAddress} {
+		if _, ok := columns[col]; !ok {
+			return fmt.Errorf("%w: %s: no %q column", ErrInvalidEnricherConfig, name, col)
+		}
+	}
+
+	field := func(record []string, col string) string {
+		i, ok := columns[col]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidEnricherConfig, name, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		passport := field(record, fileColPassport)
+
+		if passport == "" {
+			return fmt.Errorf("%w: %s:%d: empty passport", ErrInvalidEnricherConfig, name, line)
+		}
+
+		if _, ok := fe.people[passport]; ok {
+			return fmt.Errorf("%w: %s:%d: duplicate passport %q", ErrInvalidEnricherConfig, name, line, passport)
+		}
+
+		fe.people[passport] = models.APIResponse{
+			Surname:    field(record, fileColSurname),
+			Name:       field(record, fileColName),
+			Patronymic: field(record, fileColPatronymic),
+			Address:    field(record, fileColAddress),
+		}
+	}
+}
+
+func (fe *FileEnricher) Enrich(_ context.Context, passport string) (models.APIResponse, error) {
+	person, ok := fe.people[passport]
+	if !ok {
+		return models.APIResponse{}, fmt.Errorf("%w: %q is not in the file", ErrPersonNotFound, passport)
+	}
+
+	return person, nil
+}
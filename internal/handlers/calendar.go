@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/pkg/export"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// calendarDefaultDays - без from календарь отдаёт сессии за последние 90 дней
+const calendarDefaultDays = 90
+
+type CalendarHandler struct {
+	CalendarService models.CalendarService
+	ZapLogger       *zap.SugaredLogger
+}
+
+func NewCalendarHandler(cs models.CalendarService, logger *zap.SugaredLogger) *CalendarHandler {
+	return &CalendarHandler{cs, logger}
+}
+
+// calendarURL - ссылка для подписки, схема берётся из TLS или X-Forwarded-Proto за прокси
+func calendarURL(r *http.Request, userID int, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/users/%d/calendar.ics?token=%s", scheme, r.Host, userID, token)
+}
+
+func calendarEvents(events []models.CalendarEvent, now time.Time) []export.Event {
+	result := make([]export.Event, 0, len(events))
+
+	for _, event := range events {
+		item := export.Event{
+			UID:     fmt.Sprintf("entry-%d@emtask", event.EntryID),
+			Summary: event.TaskName,
+			Start:   event.Start,
+			End:     now,
+		}
+
+		if event.End != nil {
+			item.End = *event.End
+		} else {
+			item.Summary += " (идёт)"
+		}
+
+		item.Description = fmt.Sprintf("Задача #%d, сессия #%d", event.TaskID, event.EntryID)
+		switch event.Source {
+		case "manual":
+			item.Description += ", ручной ввод"
+		case "import":
+			item.Description += ", импорт"
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// @Summary Issue calendar token
+// @Description Выпуск секретного токена для подписки на календарь сессий юзера. Прежний токен перестаёт действовать,
+// @Description новый показывается только в этом ответе
+// @Tags calendar
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 201 {object} models.CalendarToken
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 404 {string} string "User not Found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/calendar/token [post]
+func (ch *CalendarHandler) IssueCalendarToken(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" IssueCalendarToken Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	token, err := ch.CalendarService.IssueToken(ctxWthTimeout, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrUsrNotExists) {
+			ch.ZapLogger.Infof(reqIDString+" IssueCalendarToken User not found: ", err)
+			http.Error(w, "User not Found", http.StatusNotFound)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" IssueCalendarToken CalendarService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(models.CalendarToken{Token: token, URL: calendarURL(r, userID, token)})
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" IssueCalendarToken Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Revoke calendar token
+// @Description Отзыв токена подписки, ссылка на календарь перестаёт работать
+// @Tags calendar
+// @Param user_id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 404 {string} string "Calendar token not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/calendar/token [delete]
+func (ch *CalendarHandler) RevokeCalendarToken(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" RevokeCalendarToken Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = ch.CalendarService.RevokeToken(ctxWthTimeout, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrCalendarTokenNotFound) {
+			ch.ZapLogger.Infof(reqIDString+" RevokeCalendarToken Token not found: ", err)
+			http.Error(w, "Calendar token not found", http.StatusNotFound)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" RevokeCalendarToken CalendarService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get user calendar
+// @Description Сессии трекинга юзера в формате iCalendar для подписки в календаре: событие на каждую сессию,
+// @Description название задачи в заголовке. Идущая сессия заканчивается текущим моментом.
+// @Description Без from - последние 90 дней. Доступ по токену из POST /users/{user_id}/calendar/token
+// @Tags calendar
+// @Produce text/calendar
+// @Param user_id path int true "User ID"
+// @Param token query string true "Calendar token"
+// @Param from query string false "Period start (RFC3339 or YYYY-MM-DD in UTC)"
+// @Param to query string false "Period end (RFC3339 or YYYY-MM-DD in UTC), default now"
+// @Success 200 {string} string "iCalendar"
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid period"
+// @Failure 403 {string} string "Invalid calendar token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/calendar.ics [get]
+func (ch *CalendarHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" GetCalendar Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	from, to, err := parsePeriod(r.URL.Query(), time.UTC)
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" GetCalendar Invalid period: ", err)
+		http.Error(w, "Invalid period", http.StatusBadRequest)
+
+		return
+	}
+
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -calendarDefaultDays)
+	}
+
+	filter := models.CalendarFilter{UserID: userID, From: from, To: to}
+
+	events, err := ch.CalendarService.GetEvents(ctxWthTimeout, r.URL.Query().Get("token"), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCalendarToken) {
+			ch.ZapLogger.Infof(reqIDString+" GetCalendar Invalid token: ", err)
+			http.Error(w, "Invalid calendar token", http.StatusForbidden)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" GetCalendar CalendarService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	now := time.Now()
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="calendar_%d.ics"`, userID))
+
+	err = export.WriteICS(w, export.Calendar{
+		Name:   fmt.Sprintf("Трекинг времени, юзер %d", userID),
+		Stamp:  now,
+		Events: calendarEvents(events, now),
+	})
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" GetCalendar Export Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type ClientHandler struct {
+	ProjectService models.ProjectService
+	ZapLogger      *zap.SugaredLogger
+}
+
+func NewClientHandler(ps models.ProjectService, logger *zap.SugaredLogger) *ClientHandler {
+	return &ClientHandler{ps, logger}
+}
+
+// projectErrorStatus - код ответа для ошибок клиентов и проектов, 0 - ошибка неизвестна
+func projectErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidProjectName):
+		return http.StatusBadRequest
+	case errors.Is(err, repos.ErrClientNotFound), errors.Is(err, repos.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repos.ErrClientExists), errors.Is(err, repos.ErrProjectExists),
+		errors.Is(err, repos.ErrClientHasProjects):
+		return http.StatusConflict
+	default:
+		return 0
+	}
+}
+
+// @Summary Create client
+// @Description Создание клиента, название уникально без учёта регистра
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param client body models.ClientRequest true "Client"
+// @Success 201 {object} models.Client
+// @Failure 400 {string} string "Invalid input"
+// @Failure 409 {string} string "client with this name already exists"
+// @Failure 500 {string} string "Internal server error"
+// @Router /clients [post]
+func (ch *ClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	var clientRequest models.ClientRequest
+
+	err := json.NewDecoder(r.Body).Decode(&clientRequest)
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" CreateClient Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	client, err := ch.ProjectService.CreateClient(ctxWthTimeout, clientRequest)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ch.ZapLogger.Infof(reqIDString+" CreateClient Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" CreateClient ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(client)
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" CreateClient Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get clients
+// @Description Все клиенты с отслеженным временем по задачам их проектов
+// @Tags clients
+// @Produce json
+// @Success 200 {array} models.Client
+// @Failure 500 {string} string "Internal server error"
+// @Router /clients [get]
+func (ch *ClientHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	clients, err := ch.ProjectService.GetClients(ctxWthTimeout)
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" GetClients ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(clients)
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" GetClients Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get client by ID
+// @Description Клиент с отслеженным временем по задачам его проектов
+// @Tags clients
+// @Produce json
+// @Param client_id path int true "Client ID"
+// @Success 200 {object} models.Client
+// @Failure 400 {string} string "Invalid client_id"
+// @Failure 404 {string} string "client not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /clients/{client_id} [get]
+func (ch *ClientHandler) GetClientByID(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	clientID, err := strconv.Atoi(mux.Vars(r)["client_id"])
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" GetClientByID Invalid client_id: ", err)
+		http.Error(w, "Invalid client_id", http.StatusBadRequest)
+
+		return
+	}
+
+	client, err := ch.ProjectService.GetClientByID(ctxWthTimeout, clientID)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ch.ZapLogger.Infof(reqIDString+" GetClientByID Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" GetClientByID ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(client)
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" GetClientByID Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Update client
+// @Description Переименование клиента
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param client_id path int true "Client ID"
+// @Param client body models.ClientRequest true "Client"
+// @Success 200 {object} models.Client
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "client not found"
+// @Failure 409 {string} string "client with this name already exists"
+// @Failure 500 {string} string "Internal server error"
+// @Router /clients/{client_id} [patch]
+func (ch *ClientHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	clientID, err := strconv.Atoi(mux.Vars(r)["client_id"])
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" UpdateClient Invalid client_id: ", err)
+		http.Error(w, "Invalid client_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var clientRequest models.ClientRequest
+
+	err = json.NewDecoder(r.Body).Decode(&clientRequest)
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" UpdateClient Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	client, err := ch.ProjectService.UpdateClient(ctxWthTimeout, clientID, clientRequest)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ch.ZapLogger.Infof(reqIDString+" UpdateClient Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" UpdateClient ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(client)
+	if err != nil {
+		ch.ZapLogger.Error(reqIDString+" UpdateClient Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Delete client
+// @Description Удаление клиента. Клиента, у которого есть проекты, удалить нельзя
+// @Tags clients
+// @Param client_id path int true "Client ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid client_id"
+// @Failure 404 {string} string "client not found"
+// @Failure 409 {string} string "client has projects"
+// @Failure 500 {string} string "Internal server error"
+// @Router /clients/{client_id} [delete]
+func (ch *ClientHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	clientID, err := strconv.Atoi(mux.Vars(r)["client_id"])
+	if err != nil {
+		ch.ZapLogger.Infof(reqIDString+" DeleteClient Invalid client_id: ", err)
+		http.Error(w, "Invalid client_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = ch.ProjectService.DeleteClient(ctxWthTimeout, clientID)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ch.ZapLogger.Infof(reqIDString+" DeleteClient Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ch.ZapLogger.Error(reqIDString+" DeleteClient ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
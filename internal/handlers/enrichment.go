@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type EnrichmentHandler struct {
+	Cache     models.EnrichmentCache
+	ZapLogger *zap.SugaredLogger
+}
+
+// NewEnrichmentHandler - cache nil, если кэш выключен
+func NewEnrichmentHandler(cache models.EnrichmentCache, logger *zap.SugaredLogger) *EnrichmentHandler {
+	return &EnrichmentHandler{cache, logger}
+}
+
+// @Summary Enrichment cache stats
+// @Description Счётчики кэша данных юзеров с момента старта: попадания, попадания в отрицательный кэш
+// @Description (паспорт никто не знает), промахи, сбои таблицы кэша и доля запросов, обслуженных кэшем
+// @Tags users
+// @Produce json
+// @Success 200 {object} models.EnrichmentCacheStats
+// @Failure 404 {string} string "Enrichment cache is disabled"
+// @Failure 500 {string} string "Internal server error"
+// @Router /enrichment/cache/stats [get]
+func (eh *EnrichmentHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	if eh.Cache == nil {
+		eh.ZapLogger.Infof(reqIDString + "GetCacheStats Cache Disabled")
+		http.Error(w, "Enrichment cache is disabled", http.StatusNotFound)
+
+		return
+	}
+
+	err := json.NewEncoder(w).Encode(eh.Cache.Stats())
+	if err != nil {
+		eh.ZapLogger.Error(reqIDString+"GetCacheStats Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
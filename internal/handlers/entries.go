@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type EntryHandler struct {
+	EntryService models.TimeEntryService
+	ZapLogger    *zap.SugaredLogger
+}
+
+func NewEntryHandler(es models.TimeEntryService, logger *zap.SugaredLogger) *EntryHandler {
+	return &EntryHandler{es, logger}
+}
+
+// entryErrorStatus - код ответа для ошибок ручной правки сессий, 0 - ошибка неизвестна
+func entryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidEntry):
+		return http.StatusBadRequest
+	case errors.Is(err, repos.ErrTaskNotFound), errors.Is(err, repos.ErrEntryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repos.ErrEntryOverlap), errors.Is(err, repos.ErrEntryRunning),
+		errors.Is(err, repos.ErrTaskStatusConflict):
+		return http.StatusConflict
+	default:
+		return 0
+	}
+}
+
+// @Summary Create manual time entry
+// @Description Ручное добавление закрытой сессии по задаче (например, забыли запустить таймер). Сессия не должна пересекаться с другими сессиями юзера, причина обязательна. Как и таймер, доступно только для задач в статусе todo или in_progress
+// @Tags entries
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param task_id path int true "Task ID"
+// @Param entry body models.TimeEntryRequest true "Time entry"
+// @Success 201 {object} models.TimeEntry
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Task not Found"
+// @Failure 409 {string} string "Time entry overlaps another entry of the user or task status does not allow tracking"
+// @Failure 500 {string} string "Internal server error"
+// @Router /user/{user_id}/tasks/{task_id}/entries [post]
+func (eh *EntryHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" CreateEntry Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" CreateEntry Invalid task_id: ", err)
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var entryRequest models.TimeEntryRequest
+
+	err = json.NewDecoder(r.Body).Decode(&entryRequest)
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" CreateEntry Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	entry, err := eh.EntryService.CreateEntry(ctxWthTimeout, taskID, userID, entryRequest)
+	if err != nil {
+		if status := entryErrorStatus(err); status != 0 {
+			eh.ZapLogger.Infof(reqIDString+" CreateEntry Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		eh.ZapLogger.Error(reqIDString+" CreateEntry EntryService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(entry)
+	if err != nil {
+		eh.ZapLogger.Error(reqIDString+" CreateEntry Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Update time entry
+// @Description Исправление границ закрытой сессии. Старые границы и причина правки сохраняются в журнал
+// @Tags entries
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param entry_id path int true "Entry ID"
+// @Param entry body models.TimeEntryRequest true "Time entry"
+// @Success 200 {object} models.TimeEntry
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Time entry not found"
+// @Failure 409 {string} string "Time entry overlaps another entry of the user"
+// @Failure 409 {string} string "Time entry is still running"
+// @Failure 500 {string} string "Internal server error"
+// @Router /user/{user_id}/entries/{entry_id} [patch]
+func (eh *EntryHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" UpdateEntry Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	entryID, err := strconv.Atoi(mux.Vars(r)["entry_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" UpdateEntry Invalid entry_id: ", err)
+		http.Error(w, "Invalid entry_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var entryRequest models.TimeEntryRequest
+
+	err = json.NewDecoder(r.Body).Decode(&entryRequest)
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" UpdateEntry Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	entry, err := eh.EntryService.UpdateEntry(ctxWthTimeout, entryID, userID, entryRequest)
+	if err != nil {
+		if status := entryErrorStatus(err); status != 0 {
+			eh.ZapLogger.Infof(reqIDString+" UpdateEntry Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		eh.ZapLogger.Error(reqIDString+" UpdateEntry EntryService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(entry)
+	if err != nil {
+		eh.ZapLogger.Error(reqIDString+" UpdateEntry Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Delete time entry
+// @Description Удаление закрытой сессии. Границы удалённой сессии и причина сохраняются в журнал
+// @Tags entries
+// @Param user_id path int true "User ID"
+// @Param entry_id path int true "Entry ID"
+// @Param reason query string true "Reason of deletion"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Time entry not found"
+// @Failure 409 {string} string "Time entry is still running"
+// @Failure 500 {string} string "Internal server error"
+// @Router /user/{user_id}/entries/{entry_id} [delete]
+func (eh *EntryHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" DeleteEntry Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	entryID, err := strconv.Atoi(mux.Vars(r)["entry_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" DeleteEntry Invalid entry_id: ", err)
+		http.Error(w, "Invalid entry_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = eh.EntryService.DeleteEntry(ctxWthTimeout, entryID, userID, r.URL.Query().Get("reason"))
+	if err != nil {
+		if status := entryErrorStatus(err); status != 0 {
+			eh.ZapLogger.Infof(reqIDString+" DeleteEntry Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		eh.ZapLogger.Error(reqIDString+" DeleteEntry EntryService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get active timers
+// @Description Кто над чем работает прямо сейчас: идущие сессии с юзером и прошедшим временем, с пагинацией и фильтрацией по юзеру
+// @Tags entries
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param surname query string false "Иванов"
+// @Param name query string false "Иван"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Limit per page"
+// @Success 200 {array} models.ActiveTimer
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid Page or Limit param"
+// @Failure 500 {string} string "Internal server error"
+// @Router /timers/active [get]
+func (eh *EntryHandler) GetActiveTimers(w http.ResponseWriter, r *http.Request) {
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	queryParams := r.URL.Query()
+	filter := models.ActiveTimerFilter{
+		Surname: queryParams.Get("surname"),
+		Name:    queryParams.Get("name"),
+	}
+
+	if userIDStr := queryParams.Get("user_id"); userIDStr != "" {
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			eh.ZapLogger.Infof(reqIDString+" GetActiveTimers Invalid user_id: ", err)
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+			return
+		}
+
+		filter.UserID = userID
+	}
+
+	eh.writeActiveTimers(w, r, filter, "GetActiveTimers")
+}
+
+// @Summary Get user active timers
+// @Description Идущие сессии юзера с прошедшим временем, с пагинацией
+// @Tags entries
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Limit per page"
+// @Success 200 {array} models.ActiveTimer
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid Page or Limit param"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/timers/active [get]
+func (eh *EntryHandler) GetUserActiveTimers(w http.ResponseWriter, r *http.Request) {
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		eh.ZapLogger.Infof(reqIDString+" GetUserActiveTimers Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	eh.writeActiveTimers(w, r, models.ActiveTimerFilter{UserID: userID}, "GetUserActiveTimers")
+}
+
+// writeActiveTimers - общая часть списков идущих таймеров: пагинация как у GetUsers, запрос и ответ
+func (eh *EntryHandler) writeActiveTimers(
+	w http.ResponseWriter,
+	r *http.Request,
+	filter models.ActiveTimerFilter,
+	method string,
+) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	queryParams := r.URL.Query()
+
+	page, err := strconv.Atoi(queryParams.Get("page"))
+	if err != nil || page < 1 {
+		eh.ZapLogger.Infof(reqIDString+method+" Invalid Page param: ", queryParams)
+		http.Error(w, " Invalid Page param", http.StatusBadRequest)
+
+		return
+	}
+
+	limit, err := strconv.Atoi(queryParams.Get("limit"))
+	if err != nil || limit < 1 {
+		eh.ZapLogger.Infof(reqIDString+method+" Invalid Limit param: ", queryParams)
+		http.Error(w, "Invalid Limit param", http.StatusBadRequest)
+
+		return
+	}
+
+	timers, err := eh.EntryService.GetActiveTimers(ctxWthTimeout, filter, page, limit)
+	if err != nil {
+		eh.ZapLogger.Error(reqIDString+method+" EntryService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(timers)
+	if err != nil {
+		eh.ZapLogger.Error(reqIDString+method+" Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/pkg/export"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type exportFormat string
+
+const (
+	formatJSON exportFormat = "json"
+	formatCSV  exportFormat = "csv"
+	formatXLSX exportFormat = "xlsx"
+)
+
+const (
+	contentTypeCSV  = "text/csv"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+var errInvalidFormat = errors.New("format must be json, csv or xlsx")
+
+// negotiateFormat - формат ответа из параметра format, а без него - из заголовка Accept. По умолчанию JSON
+func negotiateFormat(r *http.Request) (exportFormat, error) {
+	switch format := exportFormat(r.URL.Query().Get("format")); format {
+	case "":
+	case formatJSON, formatCSV, formatXLSX:
+		return format, nil
+	default:
+		return "", errInvalidFormat
+	}
+
+	accept := r.Header.Get("Accept")
+
+	switch {
+	case strings.Contains(accept, contentTypeXLSX):
+		return formatXLSX, nil
+	case strings.Contains(accept, contentTypeCSV):
+		return formatCSV, nil
+	}
+
+	return formatJSON, nil
+}
+
+// writeExport - отдаёт таблицу файлом для скачивания в формате CSV или XLSX
+func writeExport(w http.ResponseWriter, format exportFormat, filename string, table export.Table) error {
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+
+	if format == formatXLSX {
+		w.Header().Set("Content-Type", contentTypeXLSX)
+		return export.WriteXLSX(w, table)
+	}
+
+	w.Header().Set("Content-Type", contentTypeCSV+"; charset=utf-8")
+
+	return export.WriteCSV(w, table)
+}
+
+// userFullName - ФИО через пробел, пустые части пропускаются
+func userFullName(user models.User) string {
+	return strings.Join(strings.Fields(user.Surname+" "+user.Name+" "+user.Patronymic), " ")
+}
+
+// groupTitle - заголовок столбца с названиями строк отчёта
+func groupTitle(group models.ReportGroup) string {
+	switch group {
+	case models.ReportGroupProject:
+		return "Проект"
+	case models.ReportGroupClient:
+		return "Клиент"
+	case models.ReportGroupTag:
+		return "Тег"
+	default:
+		return "Задача"
+	}
+}
+
+func workloadTable(fullName string, group models.ReportGroup, workload []models.TaskWorkload) export.Table {
+	table := export.Table{
+		Sheet:   "Трудозатраты",
+		Header:  []string{"Сотрудник", groupTitle(group), "Время", "Часы"},
+		Numeric: []bool{false, false, false, true},
+	}
+
+	var total int64
+
+	for _, item := range workload {
+		table.Rows = append(table.Rows, []string{
+			fullName,
+			item.Name,
+			export.FormatDuration(item.TotalSeconds),
+			export.FormatHours(item.TotalSeconds),
+		})
+		total += item.TotalSeconds
+	}
+
+	table.Totals = []string{"Итого", "", export.FormatDuration(total), export.FormatHours(total)}
+
+	return table
+}
+
+// summaryTable - строка на период, а с group_by - строка на каждую группу периода
+func summaryTable(fullName string, group models.ReportGroup, summary []models.PeriodSummary) export.Table {
+	if group != "" {
+		return groupedSummaryTable(fullName, group, summary)
+	}
+
+	table := export.Table{
+		Sheet:   "Сводка",
+		Header:  []string{"Сотрудник", "Период", "Время", "Часы"},
+		Numeric: []bool{false, false, false, true},
+	}
+
+	var total int64
+
+	for _, item := range summary {
+		table.Rows = append(table.Rows, []string{
+			fullName,
+			item.Period,
+			export.FormatDuration(item.TotalSeconds),
+			export.FormatHours(item.TotalSeconds),
+		})
+		total += item.TotalSeconds
+	}
+
+	table.Totals = []string{"Итого", "", export.FormatDuration(total), export.FormatHours(total)}
+
+	return table
+}
+
+func groupedSummaryTable(fullName string, group models.ReportGroup, summary []models.PeriodSummary) export.Table {
+	table := export.Table{
+		Sheet:   "Сводка",
+		Header:  []string{"Сотрудник", "Период", groupTitle(group), "Время", "Часы"},
+		Numeric: []bool{false, false, false, false, true},
+	}
+
+	var total int64
+
+	for _, item := range summary {
+		for _, g := range item.Groups {
+			table.Rows = append(table.Rows, []string{
+				fullName,
+				item.Period,
+				g.Name,
+				export.FormatDuration(g.TotalSeconds),
+				export.FormatHours(g.TotalSeconds),
+			})
+		}
+
+		total += item.TotalSeconds
+	}
+
+	table.Totals = []string{"Итого", "", "", export.FormatDuration(total), export.FormatHours(total)}
+
+	return table
+}
+
+// timesheetTable - табель в том же виде, что и JSON: строки по задачам или группам, столбцы по дням,
+// итоги внизу и справа
+func timesheetTable(fullName string, group models.ReportGroup, sheet models.Timesheet) export.Table {
+	table := export.Table{Sheet: "Табель " + sheet.Period}
+
+	table.Header = append([]string{"Сотрудник", groupTitle(group)}, sheet.Days...)
+	table.Header = append(table.Header, "Итого")
+
+	for _, row := range sheet.Rows {
+		cells := []string{fullName, row.Name}
+
+		for _, minutes := range row.Minutes {
+			cells = append(cells, export.FormatDuration(minutes*60))
+		}
+
+		table.Rows = append(table.Rows, append(cells, export.FormatDuration(row.TotalMinutes*60)))
+	}
+
+	table.Totals = []string{"Итого", ""}
+
+	for _, minutes := range sheet.DayTotals {
+		table.Totals = append(table.Totals, export.FormatDuration(minutes*60))
+	}
+
+	table.Totals = append(table.Totals, export.FormatDuration(sheet.TotalMinutes*60))
+
+	return table
+}
+
+func tasksTable(tasks []models.Task) export.Table {
+	table := export.Table{
+		Sheet:   "Задачи",
+		Header:  []string{"Сотрудник", "Задача", "Начало", "Конец", "Состояние", "Время", "Часы"},
+		Numeric: []bool{false, false, false, false, false, false, true},
+	}
+
+	var total int64
+
+	for _, task := range tasks {
+		table.Rows = append(table.Rows, []string{
+			task.UserFullName,
+			task.Name,
+			formatExportTime(task.StartTime),
+			formatExportTime(task.EndTime),
+			string(task.State),
+			export.FormatDuration(task.TotalSeconds),
+			export.FormatHours(task.TotalSeconds),
+		})
+		total += task.TotalSeconds
+	}
+
+	table.Totals = []string{"Итого", "", "", "", "", export.FormatDuration(total), export.FormatHours(total)}
+
+	return table
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.DateTime)
+}
+
+// timesheetDocument - табель на подпись: строка на каждую задачу или группу в каждый день, когда по ней было время.
+// Документ уходит заказчику, поэтому паспортных данных в нём нет
+func timesheetDocument(user models.User, group models.ReportGroup, sheet models.Timesheet) export.Document {
+	period := sheet.Period
+	if len(sheet.Days) > 0 {
+		period = sheet.Days[0] + " — " + sheet.Days[len(sheet.Days)-1]
+	}
+
+	doc := export.Document{
+		Title: "Табель учёта рабочего времени",
+		Fields: []export.Field{
+			{Label: "Сотрудник", Value: userFullName(user)},
+			{Label: "Период", Value: period},
+			{Label: "Часовой пояс", Value: sheet.Timezone},
+			{Label: "Всего", Value: export.FormatDuration(sheet.TotalMinutes * 60)},
+		},
+		Table: export.Table{
+			Header:  []string{"Дата", groupTitle(group), "Время", "Часы"},
+			Numeric: []bool{false, false, false, true},
+		},
+		Widths:     []float64{1.3, 5, 1.5, 1},
+		Signatures: []string{"Исполнитель", "Заказчик"},
+	}
+
+	for day, date := range sheet.Days {
+		for _, row := range sheet.Rows {
+			if row.Minutes[day] == 0 {
+				continue
+			}
+
+			doc.Table.Rows = append(doc.Table.Rows, []string{
+				date,
+				row.Name,
+				export.FormatDuration(row.Minutes[day] * 60),
+				export.FormatHours(row.Minutes[day] * 60),
+			})
+		}
+	}
+
+	doc.Table.Totals = []string{
+		"Итого",
+		"",
+		export.FormatDuration(sheet.TotalMinutes * 60),
+		export.FormatHours(sheet.TotalMinutes * 60),
+	}
+
+	return doc
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportTimeoutTime - выгрузка за несколько лет грузится дольше обычного запроса
+var ImportTimeoutTime = 30 * time.Second
+
+// importMaxBytes - предельный размер загружаемого CSV
+const importMaxBytes = 10 << 20
+
+type ImportHandler struct {
+	ImportService models.ImportService
+	ZapLogger     *zap.SugaredLogger
+}
+
+func NewImportHandler(is models.ImportService, logger *zap.SugaredLogger) *ImportHandler {
+	return &ImportHandler{is, logger}
+}
+
+func parseImportBool(query url.Values, name string, def bool) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return def, nil
+	}
+
+	return strconv.ParseBool(value)
+}
+
+// parseImportOptions - параметры импорта из query. Без create_users ненайденные юзеры создаются
+func parseImportOptions(query url.Values) (models.ImportOptions, error) {
+	format, err := services.ParseImportFormat(query.Get("format"))
+	if err != nil {
+		return models.ImportOptions{}, err
+	}
+
+	opts := models.ImportOptions{Format: format, Timezone: query.Get("tz")}
+
+	opts.DryRun, err = parseImportBool(query, "dry_run", false)
+	if err != nil {
+		return models.ImportOptions{}, err
+	}
+
+	opts.CreateUsers, err = parseImportBool(query, "create_users", true)
+	if err != nil {
+		return models.ImportOptions{}, err
+	}
+
+	opts.SkipInvalid, err = parseImportBool(query, "skip_invalid", false)
+	if err != nil {
+		return models.ImportOptions{}, err
+	}
+
+	return opts, nil
+}
+
+// importBody - CSV из поля file multipart-формы или из тела запроса целиком
+func importBody(r *http.Request) (io.Reader, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, nil
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// @Summary Import time entries
+// @Description Перенос сессий из детальной CSV-выгрузки Toggl или Clockify одной транзакцией. Юзер ищется по email,
+// @Description затем по ФИО, задача - по названию у юзера; ненайденные создаются. Время в файле читается в поясе tz.
+// @Description dry_run=true только проверяет файл и показывает, что будет загружено. Если есть строки, которые
+// @Description не разобрать, без юзера или пересекающиеся с уже отслеженным временем, импорт откатывается с 409,
+// @Description skip_invalid=true загружает остальные строки
+// @Tags import
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "toggl or clockify, by default detected from the header"
+// @Param tz query string false "Timezone of the times in the file, default UTC"
+// @Param dry_run query bool false "Check the file and roll back"
+// @Param create_users query bool false "Create users that are not found, default true"
+// @Param skip_invalid query bool false "Import the rest of the rows if some cannot be imported"
+// @Param file formData file false "CSV file, if the body is not the CSV itself"
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {string} string "Invalid params"
+// @Failure 400 {string} string "Invalid import file"
+// @Failure 409 {object} models.ImportReport
+// @Failure 413 {string} string "File too large"
+// @Failure 500 {string} string "Internal server error"
+// @Router /import/entries [post]
+func (ih *ImportHandler) ImportEntries(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), ImportTimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	opts, err := parseImportOptions(r.URL.Query())
+	if err != nil {
+		ih.ZapLogger.Infof(reqIDString+" ImportEntries Invalid params: ", err)
+		http.Error(w, "Invalid params", http.StatusBadRequest)
+
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	body, err := importBody(r)
+	if err != nil {
+		ih.ZapLogger.Infof(reqIDString+" ImportEntries Invalid form: ", err)
+		http.Error(w, "Invalid import file", http.StatusBadRequest)
+
+		return
+	}
+
+	report, err := ih.ImportService.ImportEntries(ctxWthTimeout, body, opts)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &maxBytesErr):
+			ih.ZapLogger.Infof(reqIDString+" ImportEntries File too large: ", err)
+			http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+
+			return
+		case errors.Is(err, services.ErrInvalidImport), errors.Is(err, services.ErrInvalidTimezone):
+			ih.ZapLogger.Infof(reqIDString+" ImportEntries Invalid import: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		case errors.Is(err, services.ErrImportRejected):
+			ih.ZapLogger.Infof(reqIDString+" ImportEntries Rejected: ", err)
+			w.WriteHeader(http.StatusConflict)
+		default:
+			ih.ZapLogger.Error(reqIDString+" ImportEntries ImportService Error: ", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		ih.ZapLogger.Error(reqIDString+" ImportEntries Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
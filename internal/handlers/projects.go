@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type ProjectHandler struct {
+	ProjectService models.ProjectService
+	ZapLogger      *zap.SugaredLogger
+}
+
+func NewProjectHandler(ps models.ProjectService, logger *zap.SugaredLogger) *ProjectHandler {
+	return &ProjectHandler{ps, logger}
+}
+
+// @Summary Create project
+// @Description Создание проекта. client_id необязателен: проект без клиента - внутренний.
+// @Description Название уникально у клиента без учёта регистра
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param project body models.ProjectRequest true "Project"
+// @Success 201 {object} models.Project
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "client not found"
+// @Failure 409 {string} string "project with this name already exists for the client"
+// @Failure 500 {string} string "Internal server error"
+// @Router /projects [post]
+func (ph *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	var projectRequest models.ProjectRequest
+
+	err := json.NewDecoder(r.Body).Decode(&projectRequest)
+	if err != nil {
+		ph.ZapLogger.Infof(reqIDString+" CreateProject Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	project, err := ph.ProjectService.CreateProject(ctxWthTimeout, projectRequest)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ph.ZapLogger.Infof(reqIDString+" CreateProject Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ph.ZapLogger.Error(reqIDString+" CreateProject ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(project)
+	if err != nil {
+		ph.ZapLogger.Error(reqIDString+" CreateProject Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get projects
+// @Description Проекты с клиентом и отслеженным временем по всем задачам проекта
+// @Tags projects
+// @Produce json
+// @Param client_id query int false "Only projects of the client"
+// @Success 200 {array} models.Project
+// @Failure 400 {string} string "Invalid client_id"
+// @Failure 500 {string} string "Internal server error"
+// @Router /projects [get]
+func (ph *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	var filter models.ProjectFilter
+
+	var err error
+
+	if clientID := r.URL.Query().Get("client_id"); clientID != "" {
+		filter.ClientID, err = strconv.Atoi(clientID)
+		if err != nil {
+			ph.ZapLogger.Infof(reqIDString+" GetProjects Invalid client_id: ", err)
+			http.Error(w, "Invalid client_id", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	projects, err := ph.ProjectService.GetProjects(ctxWthTimeout, filter)
+	if err != nil {
+		ph.ZapLogger.Error(reqIDString+" GetProjects ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(projects)
+	if err != nil {
+		ph.ZapLogger.Error(reqIDString+" GetProjects Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get project by ID
+// @Description Проект с клиентом и отслеженным временем по всем задачам проекта
+// @Tags projects
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 400 {string} string "Invalid project_id"
+// @Failure 404 {string} string "project not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /projects/{project_id} [get]
+func (ph *ProjectHandler) GetProjectByID(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	projectID, err := strconv.Atoi(mux.Vars(r)["project_id"])
+	if err != nil {
+		ph.ZapLogger.Infof(reqIDString+" GetProjectByID Invalid project_id: ", err)
+		http.Error(w, "Invalid project_id", http.StatusBadRequest)
+
+		return
+	}
+
+	project, err := ph.ProjectService.GetProjectByID(ctxWthTimeout, projectID)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ph.ZapLogger.Infof(reqIDString+" GetProjectByID Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ph.ZapLogger.Error(reqIDString+" GetProjectByID ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(project)
+	if err != nil {
+		ph.ZapLogger.Error(reqIDString+" GetProjectByID Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Update project
+// @Description Замена названия и клиента проекта, client_id: null делает проект внутренним
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param project body models.ProjectRequest true "Project"
+// @Success 200 {object} models.Project
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "project not found"
+// @Failure 404 {string} string "client not found"
+// @Failure 409 {string} string "project with this name already exists for the client"
+// @Failure 500 {string} string "Internal server error"
+// @Router /projects/{project_id} [patch]
+func (ph *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	projectID, err := strconv.Atoi(mux.Vars(r)["project_id"])
+	if err != nil {
+		ph.ZapLogger.Infof(reqIDString+" UpdateProject Invalid project_id: ", err)
+		http.Error(w, "Invalid project_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var projectRequest models.ProjectRequest
+
+	err = json.NewDecoder(r.Body).Decode(&projectRequest)
+	if err != nil {
+		ph.ZapLogger.Infof(reqIDString+" UpdateProject Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	project, err := ph.ProjectService.UpdateProject(ctxWthTimeout, projectID, projectRequest)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ph.ZapLogger.Infof(reqIDString+" UpdateProject Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ph.ZapLogger.Error(reqIDString+" UpdateProject ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(project)
+	if err != nil {
+		ph.ZapLogger.Error(reqIDString+" UpdateProject Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Delete project
+// @Description Удаление проекта. Задачи проекта остаются без проекта вместе с отслеженным временем
+// @Tags projects
+// @Param project_id path int true "Project ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid project_id"
+// @Failure 404 {string} string "project not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /projects/{project_id} [delete]
+func (ph *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	projectID, err := strconv.Atoi(mux.Vars(r)["project_id"])
+	if err != nil {
+		ph.ZapLogger.Infof(reqIDString+" DeleteProject Invalid project_id: ", err)
+		http.Error(w, "Invalid project_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = ph.ProjectService.DeleteProject(ctxWthTimeout, projectID)
+	if err != nil {
+		if status := projectErrorStatus(err); status != 0 {
+			ph.ZapLogger.Infof(reqIDString+" DeleteProject Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		ph.ZapLogger.Error(reqIDString+" DeleteProject ProjectService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
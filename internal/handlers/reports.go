@@ -0,0 +1,650 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/pkg/export"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var errInvalidPeriod = errors.New("from must be before to")
+var errInvalidWeek = errors.New("week must be in ISO format 2006-W01")
+var errInvalidMonth = errors.New("month must be in format 2006-01")
+var errInvalidProjectID = errors.New("project_id must be a positive integer")
+var errInvalidClientID = errors.New("client_id must be a positive integer")
+var errInvalidGroupBy = errors.New("group_by must be task, project, client or tag")
+
+type ReportHandler struct {
+	ReportService models.ReportService
+	ZapLogger     *zap.SugaredLogger
+}
+
+func NewReportHandler(rs models.ReportService, logger *zap.SugaredLogger) *ReportHandler {
+	return &ReportHandler{rs, logger}
+}
+
+// parseReportTime - метка в формате RFC3339 или дата "2006-01-02", которая означает начало дня в поясе loc
+func parseReportTime(value string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation(time.DateOnly, value, loc)
+}
+
+// parsePeriod - разбирает границы периода from/to, без from период открыт слева,
+// без to - заканчивается текущим моментом
+func parsePeriod(query url.Values, loc *time.Location) (time.Time, time.Time, error) {
+	var from time.Time
+
+	to := time.Now()
+
+	var err error
+
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err = parseReportTime(fromStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if toStr := query.Get("to"); toStr != "" {
+		to, err = parseReportTime(toStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errInvalidPeriod
+	}
+
+	return from, to, nil
+}
+
+// parseReportScope - отбор по проекту project_id и клиенту client_id и группировка group_by
+func parseReportScope(query url.Values, filter *models.ReportFilter) error {
+	var err error
+
+	if projectID := query.Get("project_id"); projectID != "" {
+		filter.ProjectID, err = strconv.Atoi(projectID)
+		if err != nil || filter.ProjectID <= 0 {
+			return errInvalidProjectID
+		}
+	}
+
+	if clientID := query.Get("client_id"); clientID != "" {
+		filter.ClientID, err = strconv.Atoi(clientID)
+		if err != nil || filter.ClientID <= 0 {
+			return errInvalidClientID
+		}
+	}
+
+	switch group := models.ReportGroup(query.Get("group_by")); group {
+	case "", models.ReportGroupTask, models.ReportGroupProject, models.ReportGroupClient, models.ReportGroupTag:
+		filter.GroupBy = group
+	default:
+		return errInvalidGroupBy
+	}
+
+	return nil
+}
+
+// parseISOWeek - понедельник ISO-недели "2006-W01" в поясе loc
+func parseISOWeek(value string, loc *time.Location) (time.Time, error) {
+	var year, week int
+
+	_, err := fmt.Sscanf(value, "%4d-W%2d", &year, &week)
+	if err != nil || len(value) != len("2006-W01") {
+		return time.Time{}, errInvalidWeek
+	}
+
+	// 4 января всегда попадает в первую ISO-неделю года
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7+(week-1)*7)
+
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, errInvalidWeek
+	}
+
+	return monday, nil
+}
+
+// resolveLocation - часовой пояс отчёта из tz или профиля юзера. При ошибке ответ уже записан
+func (rh *ReportHandler) resolveLocation(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	userID int,
+	method string,
+) (*time.Location, bool) {
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	loc, err := rh.ReportService.ResolveLocation(ctx, userID, r.URL.Query().Get("tz"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			rh.ZapLogger.Infof(reqIDString+" "+method+" Invalid timezone: ", err)
+			http.Error(w, "Invalid timezone", http.StatusBadRequest)
+
+			return nil, false
+		}
+
+		if errors.Is(err, repos.ErrUsrNotExists) {
+			rh.ZapLogger.Infof(reqIDString+" "+method+" User not found: ", err)
+			http.Error(w, "User not Found", http.StatusNotFound)
+
+			return nil, false
+		}
+
+		rh.ZapLogger.Error(reqIDString+" "+method+" ResolveLocation Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	return loc, true
+}
+
+// exportReport - выгружает отчёт юзера файлом, ФИО юзера подставляется в строки таблицы
+func (rh *ReportHandler) exportReport(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	userID int,
+	format exportFormat,
+	filename string,
+	method string,
+	build func(fullName string) export.Table,
+) {
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	user, err := rh.ReportService.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrUsrNotExists) {
+			rh.ZapLogger.Infof(reqIDString+" "+method+" User not found: ", err)
+			http.Error(w, "User not Found", http.StatusNotFound)
+
+			return
+		}
+
+		rh.ZapLogger.Error(reqIDString+" "+method+" GetUser Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = writeExport(w, format, filename, build(userFullName(user)))
+	if err != nil {
+		rh.ZapLogger.Error(reqIDString+" "+method+" Export Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get user workload
+// @Description Трудозатраты юзера за период: задача - сумма часов и минут, с сортировкой от большей затраты к меньшей.
+// @Description Даты без времени считаются началом дня в часовом поясе юзера или tz
+// @Tags reports
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param user_id path int true "User ID"
+// @Param from query string false "Period start (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Period end (RFC3339 or YYYY-MM-DD), default now"
+// @Param tz query string false "IANA timezone, default user's timezone"
+// @Param project_id query int false "Only tasks of the project"
+// @Param client_id query int false "Only tasks of the client's projects"
+// @Param group_by query string false "task, project, client or tag, default task"
+// @Param format query string false "json, csv or xlsx, default from Accept header"
+// @Success 200 {array} models.TaskWorkload
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid period"
+// @Failure 400 {string} string "Invalid timezone"
+// @Failure 400 {string} string "Invalid project_id, client_id or group_by"
+// @Failure 400 {string} string "Invalid format"
+// @Failure 404 {string} string "User not Found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/workload [get]
+func (rh *ReportHandler) GetWorkload(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetWorkload Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	format, err := negotiateFormat(r)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetWorkload Invalid format: ", err)
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+
+		return
+	}
+
+	loc, ok := rh.resolveLocation(ctxWthTimeout, w, r, userID, "GetWorkload")
+	if !ok {
+		return
+	}
+
+	from, to, err := parsePeriod(r.URL.Query(), loc)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetWorkload Invalid period: ", err)
+		http.Error(w, "Invalid period", http.StatusBadRequest)
+
+		return
+	}
+
+	filter := models.ReportFilter{UserID: userID, From: from, To: to, Location: loc}
+
+	err = parseReportScope(r.URL.Query(), &filter)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetWorkload Invalid params: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	workload, err := rh.ReportService.GetWorkload(ctxWthTimeout, filter)
+	if err != nil {
+		rh.ZapLogger.Error(reqIDString+" GetWorkload ReportService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if format != formatJSON {
+		build := func(fullName string) export.Table {
+			return workloadTable(fullName, filter.GroupBy, workload)
+		}
+
+		rh.exportReport(ctxWthTimeout, w, r, userID, format, fmt.Sprintf("workload_%d", userID), "GetWorkload", build)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(workload)
+	if err != nil {
+		rh.ZapLogger.Error(reqIDString+" GetWorkload Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get user time summary
+// @Description Затраты юзера по дням или неделям. Границы дней и недель (с понедельника) считаются
+// @Description в часовом поясе юзера, tz позволяет посмотреть отчёт в другом поясе
+// @Tags reports
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param user_id path int true "User ID"
+// @Param from query string true "Period start (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Period end (RFC3339 or YYYY-MM-DD), default now"
+// @Param group query string false "day or week, default day"
+// @Param tz query string false "IANA timezone, default user's timezone"
+// @Param project_id query int false "Only tasks of the project"
+// @Param client_id query int false "Only tasks of the client's projects"
+// @Param group_by query string false "task, project or client breakdown of each period, default none"
+// @Param format query string false "json, csv or xlsx, default from Accept header"
+// @Success 200 {array} models.PeriodSummary
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid period"
+// @Failure 400 {string} string "Invalid timezone"
+// @Failure 400 {string} string "Invalid project_id, client_id or group_by"
+// @Failure 400 {string} string "Invalid format"
+// @Failure 400 {string} string "group must be day or week"
+// @Failure 404 {string} string "User not Found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/summary [get]
+func (rh *ReportHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetSummary Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	format, err := negotiateFormat(r)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetSummary Invalid format: ", err)
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+
+		return
+	}
+
+	loc, ok := rh.resolveLocation(ctxWthTimeout, w, r, userID, "GetSummary")
+	if !ok {
+		return
+	}
+
+	from, to, err := parsePeriod(r.URL.Query(), loc)
+	if err == nil && from.IsZero() {
+		err = errInvalidPeriod
+	}
+
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetSummary Invalid period: ", err)
+		http.Error(w, "Invalid period", http.StatusBadRequest)
+
+		return
+	}
+
+	bucket := models.ReportBucket(r.URL.Query().Get("group"))
+	if bucket == "" {
+		bucket = models.ReportBucketDay
+	}
+
+	filter := models.ReportFilter{UserID: userID, From: from, To: to, Location: loc, Bucket: bucket}
+
+	err = parseReportScope(r.URL.Query(), &filter)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetSummary Invalid params: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	summary, err := rh.ReportService.GetSummary(ctxWthTimeout, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReportBucket) || errors.Is(err, services.ErrReportPeriodTooLong) ||
+			errors.Is(err, services.ErrTagGroupUnsupported) {
+			rh.ZapLogger.Infof(reqIDString+" GetSummary Invalid params: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		rh.ZapLogger.Error(reqIDString+" GetSummary ReportService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if format != formatJSON {
+		build := func(fullName string) export.Table {
+			return summaryTable(fullName, filter.GroupBy, summary)
+		}
+
+		rh.exportReport(ctxWthTimeout, w, r, userID, format, fmt.Sprintf("summary_%d", userID), "GetSummary", build)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(summary)
+	if err != nil {
+		rh.ZapLogger.Error(reqIDString+" GetSummary Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get user timesheet
+// @Description Табель юзера за неделю (week) или день (date): строки по задачам, столбцы по дням в минутах,
+// @Description итоги по строкам и столбцам. Сессии через полночь делятся между днями в часовом поясе юзера или tz.
+// @Description Без параметров - текущая неделя
+// @Tags reports
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param user_id path int true "User ID"
+// @Param week query string false "ISO week, e.g. 2026-W42"
+// @Param date query string false "Single day, YYYY-MM-DD"
+// @Param tz query string false "IANA timezone, default user's timezone"
+// @Param project_id query int false "Only tasks of the project"
+// @Param client_id query int false "Only tasks of the client's projects"
+// @Param group_by query string false "Rows by task, project or client, default task"
+// @Param format query string false "json, csv or xlsx, default from Accept header"
+// @Success 200 {object} models.Timesheet
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid week or date"
+// @Failure 400 {string} string "Invalid timezone"
+// @Failure 400 {string} string "Invalid project_id, client_id or group_by"
+// @Failure 400 {string} string "Invalid format"
+// @Failure 404 {string} string "User not Found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/timesheet [get]
+func (rh *ReportHandler) GetTimesheet(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheet Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	format, err := negotiateFormat(r)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheet Invalid format: ", err)
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+
+		return
+	}
+
+	loc, ok := rh.resolveLocation(ctxWthTimeout, w, r, userID, "GetTimesheet")
+	if !ok {
+		return
+	}
+
+	filter := models.ReportFilter{UserID: userID, Location: loc}
+
+	var period string
+
+	switch week, date := r.URL.Query().Get("week"), r.URL.Query().Get("date"); {
+	case date != "":
+		filter.From, err = time.ParseInLocation(time.DateOnly, date, loc)
+		filter.To = filter.From.AddDate(0, 0, 1)
+		period = date
+	case week != "":
+		filter.From, err = parseISOWeek(week, loc)
+		filter.To = filter.From.AddDate(0, 0, 7)
+		period = week
+	default:
+		year, num := time.Now().In(loc).ISOWeek()
+		period = fmt.Sprintf("%d-W%02d", year, num)
+		filter.From, err = parseISOWeek(period, loc)
+		filter.To = filter.From.AddDate(0, 0, 7)
+	}
+
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheet Invalid week or date: ", err)
+		http.Error(w, "Invalid week or date", http.StatusBadRequest)
+
+		return
+	}
+
+	err = parseReportScope(r.URL.Query(), &filter)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheet Invalid params: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	sheet, err := rh.ReportService.GetTimesheet(ctxWthTimeout, filter, period)
+	if err != nil {
+		if errors.Is(err, services.ErrTagGroupUnsupported) {
+			rh.ZapLogger.Infof(reqIDString+" GetTimesheet Invalid params: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		rh.ZapLogger.Error(reqIDString+" GetTimesheet ReportService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if format != formatJSON {
+		build := func(fullName string) export.Table {
+			return timesheetTable(fullName, filter.GroupBy, sheet)
+		}
+
+		rh.exportReport(ctxWthTimeout, w, r, userID, format, fmt.Sprintf("timesheet_%d_%s", userID, period), "GetTimesheet", build)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(sheet)
+	if err != nil {
+		rh.ZapLogger.Error(reqIDString+" GetTimesheet Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// parseSignOffPeriod - период табеля на подпись: месяц month, иначе from/to, без параметров - текущий месяц
+func parseSignOffPeriod(query url.Values, loc *time.Location) (time.Time, time.Time, string, error) {
+	if month := query.Get("month"); month != "" {
+		from, err := time.ParseInLocation("2006-01", month, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", errInvalidMonth
+		}
+
+		return from, from.AddDate(0, 1, 0), month, nil
+	}
+
+	if query.Get("from") == "" {
+		now := time.Now().In(loc)
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+
+		return from, from.AddDate(0, 1, 0), from.Format("2006-01"), nil
+	}
+
+	from, to, err := parsePeriod(query, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+
+	// табель строится по целым дням
+	from = time.Date(from.In(loc).Year(), from.In(loc).Month(), from.In(loc).Day(), 0, 0, 0, 0, loc)
+
+	return from, to, from.Format(time.DateOnly) + "_" + to.In(loc).Format(time.DateOnly), nil
+}
+
+// @Summary Get user timesheet PDF for sign-off
+// @Description Табель юзера за месяц (month) или период (from/to) в PDF: шапка с ФИО юзера и периодом, таблица
+// @Description по дням и задачам, итог и блок подписей исполнителя и заказчика. Без параметров - текущий месяц.
+// @Description Дни считаются в часовом поясе юзера или tz
+// @Tags reports
+// @Produce application/pdf
+// @Param user_id path int true "User ID"
+// @Param month query string false "Month, e.g. 2026-10"
+// @Param from query string false "Period start (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Period end (RFC3339 or YYYY-MM-DD), default now"
+// @Param tz query string false "IANA timezone, default user's timezone"
+// @Param project_id query int false "Only tasks of the project"
+// @Param client_id query int false "Only tasks of the client's projects"
+// @Param group_by query string false "Rows by task, project or client, default task"
+// @Success 200 {file} file
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid period"
+// @Failure 400 {string} string "Invalid timezone"
+// @Failure 400 {string} string "Invalid project_id, client_id or group_by"
+// @Failure 404 {string} string "User not Found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{user_id}/timesheet/pdf [get]
+func (rh *ReportHandler) GetTimesheetPDF(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheetPDF Invalid user_id: ", err)
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	loc, ok := rh.resolveLocation(ctxWthTimeout, w, r, userID, "GetTimesheetPDF")
+	if !ok {
+		return
+	}
+
+	from, to, period, err := parseSignOffPeriod(r.URL.Query(), loc)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheetPDF Invalid period: ", err)
+		http.Error(w, "Invalid period", http.StatusBadRequest)
+
+		return
+	}
+
+	filter := models.ReportFilter{UserID: userID, From: from, To: to, Location: loc}
+
+	err = parseReportScope(r.URL.Query(), &filter)
+	if err != nil {
+		rh.ZapLogger.Infof(reqIDString+" GetTimesheetPDF Invalid params: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	sheet, err := rh.ReportService.GetTimesheet(ctxWthTimeout, filter, period)
+	if err != nil {
+		if errors.Is(err, services.ErrReportPeriodTooLong) || errors.Is(err, services.ErrTagGroupUnsupported) {
+			rh.ZapLogger.Infof(reqIDString+" GetTimesheetPDF Invalid params: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		rh.ZapLogger.Error(reqIDString+" GetTimesheetPDF ReportService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	user, err := rh.ReportService.GetUser(ctxWthTimeout, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrUsrNotExists) {
+			rh.ZapLogger.Infof(reqIDString+" GetTimesheetPDF User not found: ", err)
+			http.Error(w, "User not Found", http.StatusNotFound)
+
+			return
+		}
+
+		rh.ZapLogger.Error(reqIDString+" GetTimesheetPDF GetUser Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="timesheet_%d_%s.pdf"`, userID, period))
+
+	err = export.WritePDF(w, timesheetDocument(user, filter.GroupBy, sheet))
+	if err != nil {
+		rh.ZapLogger.Error(reqIDString+" GetTimesheetPDF Export Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
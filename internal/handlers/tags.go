@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type TagHandler struct {
+	TagService models.TagService
+	ZapLogger  *zap.SugaredLogger
+}
+
+func NewTagHandler(ts models.TagService, logger *zap.SugaredLogger) *TagHandler {
+	return &TagHandler{ts, logger}
+}
+
+// tagErrorStatus - код ответа для ошибок тегов, 0 - ошибка неизвестна
+func tagErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidTagName):
+		return http.StatusBadRequest
+	case errors.Is(err, repos.ErrTagNotFound), errors.Is(err, repos.ErrTaskNotFound),
+		errors.Is(err, repos.ErrTagNotAttached):
+		return http.StatusNotFound
+	case errors.Is(err, repos.ErrTagExists):
+		return http.StatusConflict
+	default:
+		return 0
+	}
+}
+
+// taskTagIDs - задача и тег из пути /tasks/{task_id}/tags/{tag_id}
+func taskTagIDs(r *http.Request) (int, int, error) {
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid task_id: %w", err)
+	}
+
+	tagID, err := strconv.Atoi(mux.Vars(r)["tag_id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tag_id: %w", err)
+	}
+
+	return taskID, tagID, nil
+}
+
+// @Summary Create tag
+// @Description Создание тега, имя уникально без учёта регистра и не содержит запятых
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body models.TagRequest true "Tag"
+// @Success 201 {object} models.Tag
+// @Failure 400 {string} string "Invalid input"
+// @Failure 409 {string} string "tag with this name already exists"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tags [post]
+func (tgh *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	var tagRequest models.TagRequest
+
+	err := json.NewDecoder(r.Body).Decode(&tagRequest)
+	if err != nil {
+		tgh.ZapLogger.Infof(reqIDString+" CreateTag Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	tag, err := tgh.TagService.CreateTag(ctxWthTimeout, tagRequest)
+	if err != nil {
+		if status := tagErrorStatus(err); status != 0 {
+			tgh.ZapLogger.Infof(reqIDString+" CreateTag Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		tgh.ZapLogger.Error(reqIDString+" CreateTag TagService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(tag)
+	if err != nil {
+		tgh.ZapLogger.Error(reqIDString+" CreateTag Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get tags
+// @Description Все теги с отслеженным временем по задачам с этим тегом
+// @Tags tags
+// @Produce json
+// @Success 200 {array} models.Tag
+// @Failure 500 {string} string "Internal server error"
+// @Router /tags [get]
+func (tgh *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	tags, err := tgh.TagService.GetTags(ctxWthTimeout)
+	if err != nil {
+		tgh.ZapLogger.Error(reqIDString+" GetTags TagService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(tags)
+	if err != nil {
+		tgh.ZapLogger.Error(reqIDString+" GetTags Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Delete tag
+// @Description Удаление тега, он снимается со всех задач
+// @Tags tags
+// @Param tag_id path int true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid tag_id"
+// @Failure 404 {string} string "tag not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tags/{tag_id} [delete]
+func (tgh *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	tagID, err := strconv.Atoi(mux.Vars(r)["tag_id"])
+	if err != nil {
+		tgh.ZapLogger.Infof(reqIDString+" DeleteTag Invalid tag_id: ", err)
+		http.Error(w, "Invalid tag_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = tgh.TagService.DeleteTag(ctxWthTimeout, tagID)
+	if err != nil {
+		if status := tagErrorStatus(err); status != 0 {
+			tgh.ZapLogger.Infof(reqIDString+" DeleteTag Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		tgh.ZapLogger.Error(reqIDString+" DeleteTag TagService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Attach tag to task
+// @Description Вешает тег на задачу, повторный запрос ничего не меняет
+// @Tags tags
+// @Param task_id path int true "Task ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid task_id or tag_id"
+// @Failure 404 {string} string "task not found"
+// @Failure 404 {string} string "tag not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/tags/{tag_id} [put]
+func (tgh *TagHandler) AttachTag(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, tagID, err := taskTagIDs(r)
+	if err != nil {
+		tgh.ZapLogger.Infof(reqIDString+" AttachTag Invalid path: ", err)
+		http.Error(w, "Invalid task_id or tag_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = tgh.TagService.AttachTag(ctxWthTimeout, taskID, tagID)
+	if err != nil {
+		if status := tagErrorStatus(err); status != 0 {
+			tgh.ZapLogger.Infof(reqIDString+" AttachTag Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		tgh.ZapLogger.Error(reqIDString+" AttachTag TagService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Detach tag from task
+// @Description Снимает тег с задачи
+// @Tags tags
+// @Param task_id path int true "Task ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid task_id or tag_id"
+// @Failure 404 {string} string "tag is not attached to the task"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/tags/{tag_id} [delete]
+func (tgh *TagHandler) DetachTag(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, tagID, err := taskTagIDs(r)
+	if err != nil {
+		tgh.ZapLogger.Infof(reqIDString+" DetachTag Invalid path: ", err)
+		http.Error(w, "Invalid task_id or tag_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = tgh.TagService.DetachTag(ctxWthTimeout, taskID, tagID)
+	if err != nil {
+		if status := tagErrorStatus(err); status != 0 {
+			tgh.ZapLogger.Infof(reqIDString+" DetachTag Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		tgh.ZapLogger.Error(reqIDString+" DetachTag TagService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
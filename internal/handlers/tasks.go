@@ -3,6 +3,7 @@ package handlers
 import (
 	"EMTask/internal/models"
 	"EMTask/internal/repos"
+	"EMTask/internal/services"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -11,7 +12,9 @@ import (
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -24,14 +27,64 @@ func NewTaskHandler(ts models.TaskService, logger *zap.SugaredLogger) *TaskHandl
 	return &TaskHandler{ts, logger}
 }
 
+// queryList - значения параметра name через запятую, nil - параметр не задан
+func queryList(query url.Values, name string) []string {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+func taskAssigneeIDs(r *http.Request) (int, int, error) {
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid task_id: %w", err)
+	}
+
+	usrID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user_id: %w", err)
+	}
+
+	return taskID, usrID, nil
+}
+
+// assigneeErrorStatus - код ответа для ошибок назначения исполнителей, 0 - ошибка неизвестна
+func assigneeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repos.ErrTaskNotFound), errors.Is(err, repos.ErrUsrNotExists),
+		errors.Is(err, repos.ErrTaskNotAssigned):
+		return http.StatusNotFound
+	case errors.Is(err, repos.ErrTaskOwnerAssignee):
+		return http.StatusConflict
+	default:
+		return 0
+	}
+}
+
+// parseTaskFilter - отбор задач в списках по параметрам tags и status
+func parseTaskFilter(query url.Values) models.TaskFilter {
+	var filter models.TaskFilter
+
+	filter.Tags = queryList(query, "tags")
+
+	for _, status := range queryList(query, "status") {
+		filter.Statuses = append(filter.Statuses, models.TaskStatus(strings.TrimSpace(status)))
+	}
+
+	return filter
+}
+
 // @Summary Create a new task
-// @Description Создание новой задачи
+// @Description Создание новой задачи, с parent_id - подзадачи
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param task body models.NewTaskRequest true "New Task"
 // @Success 200 {object} models.Task
-// @Failure 400 {string} string "Invalid input"
+// @Failure 400 {string} string "Invalid input, user_id, project_id, parent_id or estimate_seconds"
 // @Failure 500 {string} string "Internal server error"
 // @Router /tasks [post]
 func (th *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -50,8 +103,22 @@ func (th *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := th.TaskService.CreateTask(ctxWthTimeout, newTaskRequest.Name, newTaskRequest.UserID)
+	user, err := th.TaskService.CreateTask(
+		ctxWthTimeout,
+		newTaskRequest.Name,
+		newTaskRequest.UserID,
+		newTaskRequest.ProjectID,
+		newTaskRequest.ParentID,
+		newTaskRequest.EstimateSeconds,
+	)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidEstimate) {
+			th.ZapLogger.Infof(reqIDString+"CreateTask Invalid estimate: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
 		if errors.Is(err, repos.ErrUsrNotExists) {
 			th.ZapLogger.Error(reqIDString+"CreateTask Error: ", err)
 			http.Error(w, "Invalid user_id", http.StatusBadRequest)
@@ -59,6 +126,20 @@ func (th *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, repos.ErrProjectNotFound) {
+			th.ZapLogger.Infof(reqIDString+"CreateTask Error: ", err)
+			http.Error(w, "Invalid project_id", http.StatusBadRequest)
+
+			return
+		}
+
+		if errors.Is(err, repos.ErrParentTaskNotFound) {
+			th.ZapLogger.Infof(reqIDString+"CreateTask Error: ", err)
+			http.Error(w, "Invalid parent_id", http.StatusBadRequest)
+
+			return
+		}
+
 		th.ZapLogger.Error(reqIDString+"CreateTask Service Error: ", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 
@@ -74,13 +155,159 @@ func (th *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// @Summary Create subtask
+// @Description Создание подзадачи. Без project_id подзадача попадает в проект родителя
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param task_id path int true "Parent task ID"
+// @Param task body models.NewTaskRequest true "New Task, parent_id is taken from the path"
+// @Success 201 {object} models.Task
+// @Failure 400 {string} string "Invalid input, user_id, project_id or estimate_seconds"
+// @Failure 404 {string} string "parent task not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/subtasks [post]
+func (th *TaskHandler) CreateSubtask(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	parentID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" CreateSubtask Invalid task_id: ", err)
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var newTaskRequest models.NewTaskRequest
+
+	err = json.NewDecoder(r.Body).Decode(&newTaskRequest)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" CreateSubtask Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := th.TaskService.CreateTask(
+		ctxWthTimeout,
+		newTaskRequest.Name,
+		newTaskRequest.UserID,
+		newTaskRequest.ProjectID,
+		&parentID,
+		newTaskRequest.EstimateSeconds,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidEstimate):
+			th.ZapLogger.Infof(reqIDString+" CreateSubtask Invalid estimate: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repos.ErrUsrNotExists):
+			th.ZapLogger.Infof(reqIDString+" CreateSubtask Error: ", err)
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		case errors.Is(err, repos.ErrProjectNotFound):
+			th.ZapLogger.Infof(reqIDString+" CreateSubtask Error: ", err)
+			http.Error(w, "Invalid project_id", http.StatusBadRequest)
+		case errors.Is(err, repos.ErrParentTaskNotFound):
+			th.ZapLogger.Infof(reqIDString+" CreateSubtask Not Found: ", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			th.ZapLogger.Error(reqIDString+" CreateSubtask TaskService Error: ", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" CreateSubtask Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Move task
+// @Description Перенос задачи под другую задачу, parent_id null делает её задачей верхнего уровня.
+// @Description Перенести задачу под неё саму или её подзадачу нельзя
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param task_id path int true "Task ID"
+// @Param parent body models.TaskParentRequest true "Parent task"
+// @Success 200 {object} models.Task
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "task not found"
+// @Failure 404 {string} string "parent task not found"
+// @Failure 409 {string} string "task can't be moved under itself or its subtask"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/parent [put]
+func (th *TaskHandler) SetTaskParent(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskParent Invalid task_id: ", err)
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var parentRequest models.TaskParentRequest
+
+	err = json.NewDecoder(r.Body).Decode(&parentRequest)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskParent Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := th.TaskService.SetTaskParent(ctxWthTimeout, taskID, parentRequest.ParentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repos.ErrTaskNotFound), errors.Is(err, repos.ErrParentTaskNotFound):
+			th.ZapLogger.Infof(reqIDString+" SetTaskParent Not Found: ", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, repos.ErrTaskCycle):
+			th.ZapLogger.Infof(reqIDString+" SetTaskParent Conflict: ", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			th.ZapLogger.Error(reqIDString+" SetTaskParent TaskService Error: ", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" SetTaskParent Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
 // @Summary Get task by ID
-// @Description Получение задачи по ID
+// @Description Получение задачи по ID вместе с историей сессий, суммарным временем
+// @Description и временем каждого исполнителя. Состояние таймера и total_seconds - по всем исполнителям. С include=subtasks - дерево подзадач, rollup_seconds
+// @Description включает время всех потомков
 // @Tags tasks
 // @Produce json
 // @Param task_id path int true "Task ID"
+// @Param include query string false "subtasks - add subtask tree"
 // @Success 200 {object} models.Task
 // @Failure 400 {string} string "Invalid task_id"
+// @Failure 400 {string} string "include must be subtasks"
 // @Failure 404 {string} string "Not Found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /tasks/{task_id} [get]
@@ -98,7 +325,15 @@ func (th *TaskHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := th.TaskService.GetTaskByID(ctxWthTimeout, taskID)
+	include := r.URL.Query().Get("include")
+	if include != "" && include != "subtasks" {
+		th.ZapLogger.Infof(reqIDString+" GetTaskByID Invalid include: ", include)
+		http.Error(w, "include must be subtasks", http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := th.TaskService.GetTaskByID(ctxWthTimeout, taskID, include == "subtasks")
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			th.ZapLogger.Infof(reqIDString+" GetTaskByID Not Found: ", err)
@@ -157,16 +392,356 @@ func (th *TaskHandler) DeleteTaskByID(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// @Summary Get tasks by user
-// @Description Получение задач юзера по его id с сортировкой по трудозатратам
+// @Summary Set task project
+// @Description Перенос задачи в проект, project_id: null отвязывает задачу от проекта
 // @Tags tasks
+// @Accept json
 // @Produce json
+// @Param task_id path int true "Task ID"
+// @Param project body models.TaskProjectRequest true "Project"
+// @Success 200 {object} models.Task
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "task not found or project not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/project [put]
+func (th *TaskHandler) SetTaskProject(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskProject Invalid task_id: ", err)
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var projectRequest models.TaskProjectRequest
+
+	err = json.NewDecoder(r.Body).Decode(&projectRequest)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskProject Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := th.TaskService.SetTaskProject(ctxWthTimeout, taskID, projectRequest.ProjectID)
+	if err != nil {
+		if errors.Is(err, repos.ErrTaskNotFound) || errors.Is(err, repos.ErrProjectNotFound) {
+			th.ZapLogger.Infof(reqIDString+" SetTaskProject Not Found: ", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		th.ZapLogger.Error(reqIDString+" SetTaskProject TaskService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" SetTaskProject Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Set task estimate
+// @Description Оценка задачи в секундах, estimate_seconds: null убирает оценку. В ответе
+// @Description remaining_seconds - остаток оценки, отрицательный при перерасходе
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param task_id path int true "Task ID"
+// @Param estimate body models.TaskEstimateRequest true "Estimate"
+// @Success 200 {object} models.Task
+// @Failure 400 {string} string "Invalid input"
+// @Failure 400 {string} string "estimate_seconds must be positive"
+// @Failure 404 {string} string "task not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/estimate [put]
+func (th *TaskHandler) SetTaskEstimate(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskEstimate Invalid task_id: ", err)
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var estimateRequest models.TaskEstimateRequest
+
+	err = json.NewDecoder(r.Body).Decode(&estimateRequest)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskEstimate Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := th.TaskService.SetTaskEstimate(ctxWthTimeout, taskID, estimateRequest.EstimateSeconds)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidEstimate):
+			th.ZapLogger.Infof(reqIDString+" SetTaskEstimate Invalid estimate: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repos.ErrTaskNotFound):
+			th.ZapLogger.Infof(reqIDString+" SetTaskEstimate Not Found: ", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			th.ZapLogger.Error(reqIDString+" SetTaskEstimate TaskService Error: ", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" SetTaskEstimate Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Get over-budget tasks
+// @Description Задачи с оценкой, на которые потратили не меньше threshold процентов оценки,
+// @Description сначала самые перерасходованные. По умолчанию порог 100 - оценка исчерпана
+// @Tags tasks
+// @Produce json
+// @Param threshold query int false "Percent of the estimate, default 100"
+// @Success 200 {array} models.TaskBudget
+// @Failure 400 {string} string "Invalid threshold"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/over-budget [get]
+func (th *TaskHandler) GetOverBudgetTasks(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	threshold := services.DefaultBudgetThreshold
+
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		var err error
+
+		threshold, err = strconv.Atoi(value)
+		if err != nil {
+			th.ZapLogger.Infof(reqIDString+"GetOverBudgetTasks Invalid threshold: ", err)
+			http.Error(w, "Invalid threshold", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	tasks, err := th.TaskService.GetOverBudgetTasks(ctxWthTimeout, threshold)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBudgetThreshold) {
+			th.ZapLogger.Infof(reqIDString+"GetOverBudgetTasks Invalid threshold: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		th.ZapLogger.Error(reqIDString+"GetOverBudgetTasks Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(tasks)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+"GetOverBudgetTasks Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Set task status
+// @Description Перевод задачи по статусам todo, in_progress, done, archived. Завершённую задачу можно
+// @Description вернуть в работу, архивную - только в todo. Завершение или архивация останавливает таймер задачи
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param task_id path int true "Task ID"
+// @Param status body models.TaskStatusRequest true "Status"
+// @Success 200 {object} models.Task
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "task not found"
+// @Failure 409 {string} string "invalid status transition"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/status [put]
+func (th *TaskHandler) SetTaskStatus(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskStatus Invalid task_id: ", err)
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	var statusRequest models.TaskStatusRequest
+
+	err = json.NewDecoder(r.Body).Decode(&statusRequest)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" SetTaskStatus Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := th.TaskService.SetTaskStatus(ctxWthTimeout, taskID, statusRequest.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownTaskStatus):
+			th.ZapLogger.Infof(reqIDString+" SetTaskStatus Invalid status: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repos.ErrTaskNotFound):
+			th.ZapLogger.Infof(reqIDString+" SetTaskStatus Not Found: ", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrTaskStatusTransition):
+			th.ZapLogger.Infof(reqIDString+" SetTaskStatus Conflict: ", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			th.ZapLogger.Error(reqIDString+" SetTaskStatus TaskService Error: ", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" SetTaskStatus Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Assign user to task
+// @Description Добавляет юзера в исполнители задачи, каждый исполнитель трекает время независимо.
+// @Description Повторный запрос ничего не меняет
+// @Tags tasks
+// @Param task_id path int true "Task ID"
+// @Param user_id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid task_id or user_id"
+// @Failure 404 {string} string "task not found"
+// @Failure 404 {string} string "user not exists"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/assignees/{user_id} [put]
+func (th *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, usrID, err := taskAssigneeIDs(r)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" AssignTask Invalid path: ", err)
+		http.Error(w, "Invalid task_id or user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = th.TaskService.AssignTask(ctxWthTimeout, taskID, usrID)
+	if err != nil {
+		if status := assigneeErrorStatus(err); status != 0 {
+			th.ZapLogger.Infof(reqIDString+" AssignTask Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		th.ZapLogger.Error(reqIDString+" AssignTask TaskService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Unassign user from task
+// @Description Убирает юзера из исполнителей задачи и останавливает его таймер по ней.
+// @Description Отслеженное время остаётся, автора задачи убрать нельзя
+// @Tags tasks
+// @Param task_id path int true "Task ID"
+// @Param user_id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid task_id or user_id"
+// @Failure 404 {string} string "task not found"
+// @Failure 404 {string} string "user is not assigned to the task"
+// @Failure 409 {string} string "task owner can't be unassigned"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tasks/{task_id}/assignees/{user_id} [delete]
+func (th *TaskHandler) UnassignTask(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	taskID, usrID, err := taskAssigneeIDs(r)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" UnassignTask Invalid path: ", err)
+		http.Error(w, "Invalid task_id or user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = th.TaskService.UnassignTask(ctxWthTimeout, taskID, usrID)
+	if err != nil {
+		if status := assigneeErrorStatus(err); status != 0 {
+			th.ZapLogger.Infof(reqIDString+" UnassignTask Rejected: ", err)
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		th.ZapLogger.Error(reqIDString+" UnassignTask TaskService Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get tasks by user
+// @Description Получение задач юзера по его id с сортировкой по трудозатратам.
+// @Description Состояние таймера и total_seconds - только по сессиям этого юзера,
+// @Description remaining_seconds - по всем исполнителям
+// @Tags tasks
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param user_id query int true "User ID"
 // @Param start_time query string false "Start Time (RFC3339 format)"
 // @Param end_time query string false "End Time (RFC3339 format)"
+// @Param tags query string false "Comma-separated tags, task must have all of them"
+// @Param status query string false "Comma-separated statuses: todo, in_progress, done, archived"
+// @Param format query string false "json, csv or xlsx, default from Accept header"
 // @Success 200 {array} models.Task
 // @Failure 400 {string} string "Invalid user_id"
 // @Failure 400 {string} string "Invalid time format"
+// @Failure 400 {string} string "Invalid format"
 // @Failure 500 {string} string "Internal server error"
 // @Router /user/tasks [get]
 func (th *TaskHandler) GetUsersTasks(w http.ResponseWriter, r *http.Request) {
@@ -187,6 +762,14 @@ func (th *TaskHandler) GetUsersTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format, err := negotiateFormat(r)
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" GetUsersTasks Invalid format: ", err)
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+
+		return
+	}
+
 	if startTime != "" {
 		if _, err = time.Parse(time.RFC3339, startTime); err != nil {
 			th.ZapLogger.Infof(reqIDString+" GetUsersTasks Invalid start_time: ", err)
@@ -205,14 +788,31 @@ func (th *TaskHandler) GetUsersTasks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tasks, err := th.TaskService.GetTasksByUserID(ctxWthTimeout, usrID, startTime, endTime)
+	tasks, err := th.TaskService.GetTasksByUserID(ctxWthTimeout, usrID, startTime, endTime, parseTaskFilter(r.URL.Query()))
 	if err != nil {
+		if errors.Is(err, services.ErrUnknownTaskStatus) {
+			th.ZapLogger.Infof(reqIDString+" GetUsersTasks Invalid status: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
 		th.ZapLogger.Error(reqIDString+" GetUsersTasks Error: ", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 
 		return
 	}
 
+	if format != formatJSON {
+		err = writeExport(w, format, fmt.Sprintf("tasks_%d", usrID), tasksTable(tasks))
+		if err != nil {
+			th.ZapLogger.Error(reqIDString+" GetUsersTasks Export Error: ", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
 	err = json.NewEncoder(w).Encode(tasks)
 	if err != nil {
 		th.ZapLogger.Error(reqIDString+" GetUsersTasks Encode Error: ", err)
@@ -223,13 +823,16 @@ func (th *TaskHandler) GetUsersTasks(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Start task tracker
-// @Description Запуск таймера на задачу юзера
+// @Description Запуск таймера на задачу юзера. По политике TIMER_POLICY остальные таймеры юзера
+// @Description останавливаются (switch) или запуск отклоняется (reject), остановленные задачи возвращаются в ответе
 // @Tags tasks
+// @Produce json
 // @Param user_id path int true "User ID"
 // @Param task_id path int true "Task ID"
-// @Success 204 "No Content"
+// @Success 200 {object} models.TimerStart
 // @Failure 400 {string} string "Invalid user_id or task_id"
 // @Failure 404 {string} string "Task not found"
+// @Failure 409 {string} string "Timer is already running or paused, or another timer is running"
 // @Failure 500 {string} string "Internal server error"
 // @Router /user/task/track/{user_id}/{task_id} [post]
 func (th *TaskHandler) StartTracker(w http.ResponseWriter, r *http.Request) {
@@ -254,7 +857,7 @@ func (th *TaskHandler) StartTracker(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = th.TaskService.StartTimeTracker(ctxWthTimeout, taskID, userID)
+	timerStart, err := th.TaskService.StartTimeTracker(ctxWthTimeout, taskID, userID)
 	if err != nil {
 		if errors.Is(err, repos.ErrTaskNotFound) {
 			th.ZapLogger.Infof(reqIDString+" StartTimeTracker TaskNotFound: ", err)
@@ -263,15 +866,151 @@ func (th *TaskHandler) StartTracker(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, services.ErrTimerTransition) {
+			th.ZapLogger.Infof(reqIDString+" StartTimeTracker Conflict: ", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
 		th.ZapLogger.Error(reqIDString+" StartTimeTracker Error: ", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 
 		return
 	}
 
+	err = json.NewEncoder(w).Encode(timerStart)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" StartTracker Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Pause task tracker
+// @Description Пауза таймера по задаче юзера, время на паузе не учитывается
+// @Tags tasks
+// @Param user_id path int true "User ID"
+// @Param task_id path int true "Task ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid user_id or task_id"
+// @Failure 404 {string} string "Task not found"
+// @Failure 409 {string} string "Timer is not running"
+// @Failure 500 {string} string "Internal server error"
+// @Router /user/task/pause/{user_id}/{task_id} [post]
+func (th *TaskHandler) PauseTracker(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" PauseTracker Atoi Error: ", r.URL.Query())
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" PauseTracker Atoi Error: ", r.URL.Query())
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	err = th.TaskService.PauseTimeTracker(ctxWthTimeout, taskID, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrTaskNotFound) {
+			th.ZapLogger.Infof(reqIDString+" PauseTimeTracker TaskNotFound: ", err)
+			http.Error(w, "Task not Found", http.StatusNotFound)
+
+			return
+		}
+
+		if errors.Is(err, services.ErrTimerTransition) {
+			th.ZapLogger.Infof(reqIDString+" PauseTimeTracker Conflict: ", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
+		th.ZapLogger.Error(reqIDString+" PauseTimeTracker Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// @Summary Resume task tracker
+// @Description Продолжение таймера по задаче юзера после паузы, политика TIMER_POLICY применяется как при запуске
+// @Tags tasks
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param task_id path int true "Task ID"
+// @Success 200 {object} models.TimerStart
+// @Failure 400 {string} string "Invalid user_id or task_id"
+// @Failure 404 {string} string "Task not found"
+// @Failure 409 {string} string "Timer is not paused, or another timer is running"
+// @Failure 500 {string} string "Internal server error"
+// @Router /user/task/resume/{user_id}/{task_id} [post]
+func (th *TaskHandler) ResumeTracker(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), TimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" ResumeTracker Atoi Error: ", r.URL.Query())
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	taskID, err := strconv.Atoi(mux.Vars(r)["task_id"])
+	if err != nil {
+		th.ZapLogger.Infof(reqIDString+" ResumeTracker Atoi Error: ", r.URL.Query())
+		http.Error(w, "Invalid task_id", http.StatusBadRequest)
+
+		return
+	}
+
+	timerStart, err := th.TaskService.ResumeTimeTracker(ctxWthTimeout, taskID, userID)
+	if err != nil {
+		if errors.Is(err, repos.ErrTaskNotFound) {
+			th.ZapLogger.Infof(reqIDString+" ResumeTimeTracker TaskNotFound: ", err)
+			http.Error(w, "Task not Found", http.StatusNotFound)
+
+			return
+		}
+
+		if errors.Is(err, services.ErrTimerTransition) {
+			th.ZapLogger.Infof(reqIDString+" ResumeTimeTracker Conflict: ", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
+		th.ZapLogger.Error(reqIDString+" ResumeTimeTracker Error: ", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(timerStart)
+	if err != nil {
+		th.ZapLogger.Error(reqIDString+" ResumeTracker Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
 // @Summary Stop task tracker
 // @Description Остановка таймера по задаче юзера
 // @Tags tasks
@@ -280,6 +1019,7 @@ func (th *TaskHandler) StartTracker(w http.ResponseWriter, r *http.Request) {
 // @Success 204 "No Content"
 // @Failure 400 {string} string "Invalid user_id or task_id"
 // @Failure 404 {string} string "Task not found"
+// @Failure 409 {string} string "Timer is not running or already finished"
 // @Failure 500 {string} string "Internal server error"
 // @Router /user/task/stop/{user_id}/{task_id} [post]
 func (th *TaskHandler) StopTracker(w http.ResponseWriter, r *http.Request) {
@@ -313,6 +1053,13 @@ func (th *TaskHandler) StopTracker(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, services.ErrTimerTransition) {
+			th.ZapLogger.Infof(reqIDString+" StopTimeTracker Conflict: ", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
 		th.ZapLogger.Error(reqIDString+" StopTimeTracker Error: ", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 
@@ -323,10 +1070,14 @@ func (th *TaskHandler) StopTracker(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Get all tasks
-// @Description Получение списка всех задач
+// @Description Получение списка всех задач. Состояние таймера и total_seconds - по всем исполнителям
 // @Tags tasks
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param tags query string false "Comma-separated tags, task must have all of them"
+// @Param status query string false "Comma-separated statuses: todo, in_progress, done, archived"
+// @Param format query string false "json, csv or xlsx, default from Accept header"
 // @Success 200 {array} models.Task
+// @Failure 400 {string} string "Invalid format"
 // @Failure 500 {string} string "Internal server error"
 // @Router /tasks [get]
 func (th *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
@@ -335,14 +1086,39 @@ func (th *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
 
 	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
 
-	users, err := th.TaskService.GetAllTasks(ctxWthTimeout)
+	format, err := negotiateFormat(r)
 	if err != nil {
+		th.ZapLogger.Infof(reqIDString+"GetAllTasks Invalid format: ", err)
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+
+		return
+	}
+
+	users, err := th.TaskService.GetAllTasks(ctxWthTimeout, parseTaskFilter(r.URL.Query()))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownTaskStatus) {
+			th.ZapLogger.Infof(reqIDString+"GetAllTasks Invalid status: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
 		th.ZapLogger.Error(reqIDString+"GetAllTasks Error: ", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 
 		return
 	}
 
+	if format != formatJSON {
+		err = writeExport(w, format, "tasks", tasksTable(users))
+		if err != nil {
+			th.ZapLogger.Error(reqIDString+"GetAllTasks Export Error: ", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
 	err = json.NewEncoder(w).Encode(users)
 	if err != nil {
 		th.ZapLogger.Error(reqIDString+"GetAllTasks Encode Error: ", err)
@@ -1,60 +1,54 @@
 package handlers
 
 import (
+	"EMTask/internal/enrichers"
 	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/pkg/people"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
-	"os"
 	"regexp"
 	"strconv"
 	"time"
 )
 
 var TimeoutTime = 500 * time.Millisecond
+
+// RefreshTimeoutTime - обновление ходит к провайдерам с повторами, это дольше обычного запроса
+var RefreshTimeoutTime = 30 * time.Second
 var passportNumberPattern = `^\d{4} \d{6}$`
 
 type UserHandler struct {
 	UserService models.UserService
 	ZapLogger   *zap.SugaredLogger
-	Client      *http.Client
 }
 
-func NewUserHandler(us models.UserService, logger *zap.SugaredLogger, client *http.Client) *UserHandler {
-	return &UserHandler{us, logger, client}
+func NewUserHandler(us models.UserService, logger *zap.SugaredLogger) *UserHandler {
+	return &UserHandler{us, logger}
 }
 
-func (uh *UserHandler) getPeopleInfo(passportNumber string) (models.APIResponse, error) {
-	apiURL := fmt.Sprintf(
-		"%s/info?passportSerie=%s&passportNumber=%s",
-		os.Getenv("API_URL"),
-		passportNumber[:4],
-		passportNumber[5:],
-	)
-
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, apiURL, nil)
-	if err != nil {
-		return models.APIResponse{}, err
-	}
-
-	resp, err := uh.Client.Do(req)
-	if err != nil {
-		return models.APIResponse{}, err
-	}
-
-	defer resp.Body.Close()
-
-	var apiResponse models.APIResponse
-
-	err = json.NewDecoder(resp.Body).Decode(&apiResponse)
-	if err != nil {
-		return models.APIResponse{}, err
+// refreshErrorStatus - код ответа для ошибок обновления юзера из провайдеров, 0 - ошибка неизвестна
+func refreshErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repos.ErrUsrNotExists):
+		return http.StatusNotFound
+	case errors.Is(err, enrichers.ErrPersonNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, people.ErrUnavailable), errors.Is(err, people.ErrInvalidResponse):
+		return http.StatusBadGateway
+	case errors.Is(err, people.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return 0
 	}
-
-	return apiResponse, nil
 }
 
 // @Summary Get Users
@@ -66,6 +60,7 @@ func (uh *UserHandler) getPeopleInfo(passportNumber string) (models.APIResponse,
 // @Param name query string false "Иван"
 // @Param patronymic query string false "Иванович"
 // @Param address query string false "г. Москва, ул. Ленина, д. 5, кв. 1"
+// @Param enrichment_status query string false "pending, done или failed"
 // @Param page query int false "Page number (default 1)"
 // @Param limit query int false "Limit per page"
 // @Success 200 {array} models.User
@@ -80,11 +75,12 @@ func (uh *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	queryParams := r.URL.Query()
 	filter := models.UserFilter{
-		PassportNum: queryParams.Get("passport"),
-		Surname:     queryParams.Get("surname"),
-		Name:        queryParams.Get("name"),
-		Patronymic:  queryParams.Get("patronymic"),
-		Address:     queryParams.Get("address"),
+		PassportNum:      queryParams.Get("passport"),
+		Surname:          queryParams.Get("surname"),
+		Name:             queryParams.Get("name"),
+		Patronymic:       queryParams.Get("patronymic"),
+		Address:          queryParams.Get("address"),
+		EnrichmentStatus: models.EnrichmentStatus(queryParams.Get("enrichment_status")),
 	}
 
 	page, err := strconv.Atoi(queryParams.Get("page"))
@@ -154,13 +150,15 @@ func (uh *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Update User by ID
-// @Description Обновить юзера по ID
+// @Description Обновить юзера по ID. Пустой timezone оставляет текущий часовой пояс
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user_id path int true "User ID"
+// @Param user body models.APIResponse true "User data"
 // @Success 200 {object} models.User
 // @Failure 400 {string} string "Invalid input"
+// @Failure 400 {string} string "Invalid timezone"
 // @Failure 500 {string} string "Internal server error"
 // @Router /user/{user_id} [patch]
 func (uh *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
@@ -188,6 +186,13 @@ func (uh *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	updatedUser, err := uh.UserService.UpdateUser(ctxWthTimeout, user, userID)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			uh.ZapLogger.Infof(reqIDString+"UpdateUser Invalid timezone: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
 		uh.ZapLogger.Error(reqIDString+"UpdateUser Service Error: ", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 
@@ -204,13 +209,15 @@ func (uh *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Add a new user
-// @Description Добавить пользователя по его паспортным данным
+// @Description Добавить пользователя по его паспортным данным. Часовой пояс по умолчанию UTC.
+// @Description Юзер создаётся сразу со статусом pending, ФИО и адрес подтягиваются из People API в фоне
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body models.NewUserRequest true "New User"
-// @Success 200 {object} models.User
+// @Success 202 {object} models.User
 // @Failure 400 {string} string "Invalid input"
+// @Failure 400 {string} string "Invalid timezone"
 // @Failure 500 {string} string "Internal server error"
 // @Router /user [post]
 func (uh *UserHandler) AddUser(w http.ResponseWriter, r *http.Request) {
@@ -237,22 +244,23 @@ func (uh *UserHandler) AddUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiResponse, err := uh.getPeopleInfo(usersPassportData.PassportNumber)
+	user, err := uh.UserService.CreateUser(ctxWthTimeout, usersPassportData.PassportNumber, usersPassportData.Timezone)
 	if err != nil {
-		uh.ZapLogger.Error(reqIDString+"AddUser getPeopleInfo Error: ", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			uh.ZapLogger.Infof(reqIDString+"AddUser Invalid timezone: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 
-		return
-	}
+			return
+		}
 
-	user, err := uh.UserService.CreateUser(ctxWthTimeout, apiResponse, usersPassportData.PassportNumber)
-	if err != nil {
 		uh.ZapLogger.Error(reqIDString+"AddUser Service Error: ", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 
 		return
 	}
 
+	w.WriteHeader(http.StatusAccepted)
+
 	err = json.NewEncoder(w).Encode(user)
 	if err != nil {
 		uh.ZapLogger.Error(reqIDString+"AddUser Encode Error: ", err)
@@ -261,3 +269,123 @@ func (uh *UserHandler) AddUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// @Summary Refresh user data
+// @Description Перезапросить ФИО и адрес юзера у провайдеров мимо кэша и показать отличия по полям.
+// @Description С apply=true отличия записываются, а изменённые поля попадают в историю user_changes
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param apply query bool false "Записать изменения"
+// @Success 200 {object} models.UserRefresh
+// @Failure 400 {string} string "Invalid user_id"
+// @Failure 400 {string} string "Invalid apply param"
+// @Failure 404 {string} string "user not exists"
+// @Failure 422 {string} string "person not found"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 502 {string} string "people api is unavailable"
+// @Failure 503 {string} string "people api circuit is open"
+// @Failure 504 {string} string "context deadline exceeded"
+// @Router /user/{user_id}/refresh [post]
+func (uh *UserHandler) RefreshUser(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), RefreshTimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	userID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		uh.ZapLogger.Infof(reqIDString+"RefreshUser Atoi Error: ", r.URL.Query())
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+		return
+	}
+
+	apply := false
+
+	if value := r.URL.Query().Get("apply"); value != "" {
+		apply, err = strconv.ParseBool(value)
+		if err != nil {
+			uh.ZapLogger.Infof(reqIDString+"RefreshUser Invalid apply param: ", r.URL.Query())
+			http.Error(w, "Invalid apply param", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	refresh, err := uh.UserService.RefreshUser(ctxWthTimeout, userID, apply)
+	if err != nil {
+		status := refreshErrorStatus(err)
+		if status == 0 {
+			uh.ZapLogger.Error(reqIDString+"RefreshUser Service Error: ", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+			return
+		}
+
+		uh.ZapLogger.Infof(reqIDString+"RefreshUser Service Error: ", err)
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(refresh)
+	if err != nil {
+		uh.ZapLogger.Error(reqIDString+"RefreshUser Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// @Summary Refresh several users
+// @Description Обновить данные нескольких юзеров (до 100) из провайдеров, как POST /user/{user_id}/refresh.
+// @Description Ошибка одного юзера не останавливает остальных и попадает в поле error его результата
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshUsersRequest true "Users to refresh"
+// @Success 200 {array} models.UserRefresh
+// @Failure 400 {string} string "Invalid input"
+// @Failure 400 {string} string "user_ids must contain from 1 to 100 ids"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/refresh [post]
+func (uh *UserHandler) RefreshUsers(w http.ResponseWriter, r *http.Request) {
+	ctxWthTimeout, cancel := context.WithTimeout(r.Context(), RefreshTimeoutTime)
+	defer cancel()
+
+	reqIDString := fmt.Sprintf("requestID: %s ", r.Context().Value("requestID"))
+
+	var req models.RefreshUsersRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		uh.ZapLogger.Infof(reqIDString+"RefreshUsers Decode Error: ", err)
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+		return
+	}
+
+	results, err := uh.UserService.RefreshUsers(ctxWthTimeout, req.UserIDs, req.Apply)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefresh) {
+			uh.ZapLogger.Infof(reqIDString+"RefreshUsers Invalid request: ", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		uh.ZapLogger.Error(reqIDString+"RefreshUsers Service Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		uh.ZapLogger.Error(reqIDString+"RefreshUsers Encode Error: ", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+
+		return
+	}
+}
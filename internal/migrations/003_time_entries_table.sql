@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS time_entries
+(
+    id SERIAL PRIMARY KEY,
+    task_id INT NOT NULL,
+    user_id INT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP,
+    CONSTRAINT fk_task FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS time_entries_task_id_idx ON time_entries (task_id);
+CREATE INDEX IF NOT EXISTS time_entries_user_id_start_time_idx ON time_entries (user_id, start_time);
+
+-- Переносим уже отслеженное время: если end_time раньше start_time, задачу перезапустили и таймер ещё идёт
+INSERT INTO time_entries (task_id, user_id, start_time, end_time)
+SELECT id, user_id, start_time, CASE WHEN end_time >= start_time THEN end_time END
+FROM tasks
+WHERE start_time IS NOT NULL;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS start_time, DROP COLUMN IF EXISTS end_time;
+
+-- +goose Down
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS start_time TIMESTAMP, ADD COLUMN IF NOT EXISTS end_time TIMESTAMP;
+
+UPDATE tasks t
+SET start_time = e.start_time, end_time = e.end_time
+FROM (
+    SELECT task_id, MAX(start_time) AS start_time, MAX(end_time) AS end_time
+    FROM time_entries
+    GROUP BY task_id
+    ) e
+WHERE e.task_id = t.id;
+
+DROP TABLE IF EXISTS time_entries;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Повторный старт раньше открывал вторую сессию: закрываем её началом следующей
+UPDATE time_entries te
+SET end_time = (
+    SELECT MIN(n.start_time)
+    FROM time_entries n
+    WHERE n.task_id = te.task_id AND n.user_id = te.user_id AND n.start_time > te.start_time
+    )
+WHERE te.end_time IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM time_entries n
+    WHERE n.task_id = te.task_id AND n.user_id = te.user_id AND n.start_time > te.start_time
+    );
+
+UPDATE time_entries
+SET end_time = start_time
+WHERE end_time < start_time;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_period_check CHECK (end_time IS NULL OR end_time >= start_time);
+
+CREATE UNIQUE INDEX IF NOT EXISTS time_entries_one_running_idx ON time_entries (task_id, user_id) WHERE end_time IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS time_entries_one_running_idx;
+
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_period_check;
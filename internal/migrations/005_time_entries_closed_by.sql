@@ -0,0 +1,15 @@
+-- +goose Up
+-- Как была закрыта сессия: остановкой таймера или паузой
+ALTER TABLE time_entries ADD COLUMN IF NOT EXISTS closed_by VARCHAR(16);
+
+UPDATE time_entries
+SET closed_by = 'stop'
+WHERE end_time IS NOT NULL;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_closed_by_check CHECK (closed_by IN ('stop', 'pause'));
+
+-- +goose Down
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_closed_by_check;
+
+ALTER TABLE time_entries DROP COLUMN IF EXISTS closed_by;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- source: откуда взялась сессия - таймер или ручной ввод, reason - причина последней ручной правки
+ALTER TABLE time_entries
+    ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'tracker',
+    ADD COLUMN IF NOT EXISTS reason TEXT;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_source_check CHECK (source IN ('tracker', 'manual'));
+
+-- Журнал ручных правок сессий: строки не удаляются вместе с сессией или задачей
+CREATE TABLE IF NOT EXISTS time_entry_audit
+(
+    id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL,
+    task_id INT NOT NULL,
+    user_id INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    old_start_time TIMESTAMP,
+    old_end_time TIMESTAMP,
+    new_start_time TIMESTAMP,
+    new_end_time TIMESTAMP,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS time_entry_audit_entry_id_idx ON time_entry_audit (entry_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS time_entry_audit;
+
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_source_check;
+
+ALTER TABLE time_entries DROP COLUMN IF EXISTS reason, DROP COLUMN IF EXISTS source;
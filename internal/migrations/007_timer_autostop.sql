@@ -0,0 +1,21 @@
+-- +goose Up
+-- Часовой пояс юзера: в нём считается конец рабочего дня при автоостановке таймеров
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- auto - сессию закрыл фоновый воркер, а не юзер
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_closed_by_check;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_closed_by_check CHECK (closed_by IN ('stop', 'pause', 'auto'));
+
+-- +goose Down
+UPDATE time_entries
+SET closed_by = 'stop'
+WHERE closed_by = 'auto';
+
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_closed_by_check;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_closed_by_check CHECK (closed_by IN ('stop', 'pause'));
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
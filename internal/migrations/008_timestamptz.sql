@@ -0,0 +1,25 @@
+-- +goose Up
+-- Метки времени без пояса зависели от TZ сервера. Сервис писал time.Now() в UTC,
+-- поэтому существующие значения считаем UTC
+ALTER TABLE time_entries
+    ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMPTZ USING end_time AT TIME ZONE 'UTC';
+
+ALTER TABLE time_entry_audit
+    ALTER COLUMN old_start_time TYPE TIMESTAMPTZ USING old_start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN old_end_time TYPE TIMESTAMPTZ USING old_end_time AT TIME ZONE 'UTC',
+    ALTER COLUMN new_start_time TYPE TIMESTAMPTZ USING new_start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN new_end_time TYPE TIMESTAMPTZ USING new_end_time AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+
+-- +goose Down
+ALTER TABLE time_entry_audit
+    ALTER COLUMN old_start_time TYPE TIMESTAMP USING old_start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN old_end_time TYPE TIMESTAMP USING old_end_time AT TIME ZONE 'UTC',
+    ALTER COLUMN new_start_time TYPE TIMESTAMP USING new_start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN new_end_time TYPE TIMESTAMP USING new_end_time AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+
+ALTER TABLE time_entries
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'UTC';
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Секретный токен подписки на календарь сессий юзера. Хранится только SHA-256 хэш,
+-- сам токен показывается один раз при выпуске
+CREATE TABLE IF NOT EXISTS calendar_tokens
+(
+    user_id INT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+    );
+
+-- +goose Down
+DROP TABLE IF EXISTS calendar_tokens;
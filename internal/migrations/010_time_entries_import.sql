@@ -0,0 +1,25 @@
+-- +goose Up
+-- email нужен, чтобы сопоставлять юзеров из выгрузок Toggl и Clockify. Необязателен, но уникален
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (LOWER(email));
+
+-- import - сессия перенесена из чужого трекера
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_source_check;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_source_check CHECK (source IN ('tracker', 'manual', 'import'));
+
+-- +goose Down
+UPDATE time_entries
+SET source = 'manual'
+WHERE source = 'import';
+
+ALTER TABLE time_entries DROP CONSTRAINT IF EXISTS time_entries_source_check;
+
+ALTER TABLE time_entries
+    ADD CONSTRAINT time_entries_source_check CHECK (source IN ('tracker', 'manual'));
+
+DROP INDEX IF EXISTS users_email_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email;
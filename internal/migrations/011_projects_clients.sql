@@ -0,0 +1,37 @@
+-- +goose Up
+-- Клиенты и проекты группируют задачи, чтобы считать часы по проекту и клиенту
+CREATE TABLE IF NOT EXISTS clients
+(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS clients_name_idx ON clients (LOWER(name));
+
+-- Проект без клиента - внутренний. Клиента, у которого есть проекты, удалить нельзя
+CREATE TABLE IF NOT EXISTS projects
+(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    client_id INT,
+    CONSTRAINT fk_client FOREIGN KEY(client_id) REFERENCES clients(id) ON DELETE RESTRICT
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS projects_client_name_idx ON projects (COALESCE(client_id, 0), LOWER(name));
+
+-- Удаление проекта отвязывает от него задачи, время по ним остаётся
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id INT;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_project FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_project_id_idx ON tasks (project_id);
+
+-- +goose Down
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_project;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
+
+DROP TABLE IF EXISTS clients;
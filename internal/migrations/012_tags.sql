@@ -0,0 +1,26 @@
+-- +goose Up
+-- Теги - метки задач вроде bugfix или meeting, по ним режется отслеженное время
+CREATE TABLE IF NOT EXISTS tags
+(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_name_idx ON tags (LOWER(name));
+
+-- Удаление задачи или тега снимает метку, время по задаче не трогается
+CREATE TABLE IF NOT EXISTS task_tags
+(
+    task_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY (task_id, tag_id),
+    CONSTRAINT fk_task_tags_task FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_tags_tag FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON task_tags (tag_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_tags;
+
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Статус задачи - её жизненный цикл, в отличие от состояния таймера, которое считается по сессиям
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'todo';
+
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_status_check CHECK (status IN ('todo', 'in_progress', 'done', 'archived'));
+
+-- По задачам, которые уже трекались, работа начата
+UPDATE tasks t
+SET status = 'in_progress'
+WHERE EXISTS (SELECT 1 FROM time_entries te WHERE te.task_id = t.id);
+
+CREATE INDEX IF NOT EXISTS tasks_status_idx ON tasks (status);
+
+-- +goose Down
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS status;
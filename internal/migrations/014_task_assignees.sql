@@ -0,0 +1,20 @@
+-- +goose Up
+-- Исполнители задачи: каждый трекает время по ней независимо, автор задачи исполнитель всегда
+CREATE TABLE IF NOT EXISTS task_assignees
+(
+    task_id INT NOT NULL,
+    user_id INT NOT NULL,
+    PRIMARY KEY (task_id, user_id),
+    CONSTRAINT fk_task_assignees_task FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_assignees_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS task_assignees_user_id_idx ON task_assignees (user_id);
+
+INSERT INTO task_assignees (task_id, user_id)
+SELECT id, user_id
+FROM tasks
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS task_assignees;
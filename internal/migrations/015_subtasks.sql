@@ -0,0 +1,18 @@
+-- +goose Up
+-- Подзадачи: время подзадач сворачивается в родительскую. Удаление родителя поднимает подзадачи наверх
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id INT;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_tasks_parent FOREIGN KEY(parent_id) REFERENCES tasks(id) ON DELETE SET NULL;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_parent_check CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS tasks_parent_id_idx ON tasks (parent_id);
+
+-- +goose Down
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_parent_check;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_tasks_parent;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
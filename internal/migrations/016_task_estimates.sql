@@ -0,0 +1,11 @@
+-- +goose Up
+-- Оценка задачи в секундах, NULL - задача без оценки
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimate_seconds BIGINT;
+
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_estimate_check CHECK (estimate_seconds > 0);
+
+-- +goose Down
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_estimate_check;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate_seconds;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Данные юзера дозаполняются из People API в фоне. Юзеры, заведённые без API, дозаполнять не нужно
+ALTER TABLE users ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS enrichment_error TEXT;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_enrichment_status_check CHECK (enrichment_status IN ('pending', 'done', 'failed'));
+
+-- Очередь дозаполнения: воркеры забирают задачи с run_at в прошлом и сдвигают run_at на время аренды,
+-- чтобы задачу упавшего воркера забрал другой
+CREATE TABLE IF NOT EXISTS enrichment_jobs
+(
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL UNIQUE,
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_enrichment_jobs_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS enrichment_jobs_run_at_idx ON enrichment_jobs (run_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS enrichment_jobs;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_enrichment_status_check;
+
+ALTER TABLE users DROP COLUMN IF EXISTS enrichment_error;
+
+ALTER TABLE users DROP COLUMN IF EXISTS enrichment_status;
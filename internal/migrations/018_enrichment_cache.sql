@@ -0,0 +1,19 @@
+-- +goose Up
+-- Кэш ответов провайдеров данных юзеров. Ключ - HMAC-SHA256 от номера паспорта с секретом из
+-- ENRICH_CACHE_SECRET, без секрета номер по ключу не подобрать.
+-- not_found - провайдеры паспорт не знают, такие записи живут меньше
+CREATE TABLE IF NOT EXISTS enrichment_cache
+(
+    passport_hash CHAR(64) PRIMARY KEY,
+    surname VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    patronymic VARCHAR(255) NOT NULL DEFAULT '',
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    not_found BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMPTZ NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS enrichment_cache_expires_at_idx ON enrichment_cache (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS enrichment_cache;
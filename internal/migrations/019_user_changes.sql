@@ -0,0 +1,17 @@
+-- +goose Up
+-- История изменений данных юзера при обновлении из провайдеров: какое поле, что было и что стало
+CREATE TABLE IF NOT EXISTS user_changes
+(
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    field VARCHAR(32) NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user_changes_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS user_changes_user_id_idx ON user_changes (user_id, changed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_changes;
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// CalendarEvent - сессия трекинга для календаря, у идущей сессии End пустой
+type CalendarEvent struct {
+	EntryID  int
+	TaskID   int
+	TaskName string
+	Source   string
+	Start    time.Time
+	End      *time.Time
+}
+
+// CalendarFilter - сессии юзера, пересекающие период [From, To)
+type CalendarFilter struct {
+	UserID int
+	From   time.Time
+	To     time.Time
+}
+
+// CalendarToken - выпущенный токен и готовая ссылка для подписки на календарь
+type CalendarToken struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type CalendarRepo interface {
+	SaveCalendarToken(context.Context, int, string) error
+	DeleteCalendarToken(context.Context, int) error
+	FindCalendarTokenHash(context.Context, int) (string, error)
+	FindCalendarEvents(context.Context, CalendarFilter) ([]CalendarEvent, error)
+}
+
+type CalendarService interface {
+	IssueToken(context.Context, int) (string, error)
+	RevokeToken(context.Context, int) error
+	GetEvents(context.Context, string, CalendarFilter) ([]CalendarEvent, error)
+}
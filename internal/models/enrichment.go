@@ -0,0 +1,63 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// EnrichmentStatus - дозаполнены ли данные юзера из People API
+type EnrichmentStatus string
+
+const (
+	EnrichmentPending EnrichmentStatus = "pending"
+	EnrichmentDone    EnrichmentStatus = "done"
+	EnrichmentFailed  EnrichmentStatus = "failed"
+)
+
+// EnrichmentJob - задача очереди дозаполнения. Attempts - номер текущей попытки, считая её саму
+type EnrichmentJob struct {
+	ID             int
+	UserID         int
+	PassportNumber string
+	Attempts       int
+}
+
+type EnrichmentRepo interface {
+	ClaimEnrichmentJobs(context.Context, int, time.Duration) ([]EnrichmentJob, error)
+	CompleteEnrichmentJob(context.Context, int, APIResponse) error
+	RetryEnrichmentJob(context.Context, int, time.Time, string) error
+	FailEnrichmentJob(context.Context, int, string) error
+}
+
+// Enricher - источник ФИО и адреса юзера по номеру паспорта "1234 567890". Timezone не заполняется
+type Enricher interface {
+	Enrich(context.Context, string) (APIResponse, error)
+}
+
+// EnrichmentCacheEntry - закэшированный ответ провайдера. NotFound - провайдеры паспорт не знают
+type EnrichmentCacheEntry struct {
+	PassportHash string
+	Person       APIResponse
+	NotFound     bool
+	ExpiresAt    time.Time
+}
+
+// EnrichmentCacheStats - счётчики кэша с момента старта. HitRatio - доля запросов, обслуженных кэшем
+type EnrichmentCacheStats struct {
+	Hits         int64   `json:"hits"`
+	NegativeHits int64   `json:"negative_hits"`
+	Misses       int64   `json:"misses"`
+	StoreErrors  int64   `json:"store_errors"`
+	HitRatio     float64 `json:"hit_ratio"`
+	Size         int     `json:"size"`
+}
+
+type EnrichmentCacheRepo interface {
+	FindEnrichmentCache(context.Context, string) (EnrichmentCacheEntry, error)
+	SaveEnrichmentCache(context.Context, EnrichmentCacheEntry) error
+	DeleteExpiredEnrichmentCache(context.Context) (int64, error)
+}
+
+type EnrichmentCache interface {
+	Stats() EnrichmentCacheStats
+}
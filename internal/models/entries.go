@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type TimeEntry struct {
+	ID              int        `json:"id"`
+	TaskID          int        `json:"task_id"`
+	UserID          int        `json:"user_id"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time"`
+	ClosedBy        string     `json:"closed_by,omitempty"`
+	Source          string     `json:"source"`
+	Reason          string     `json:"reason,omitempty"`
+	DurationSeconds int64      `json:"duration_seconds"`
+}
+
+type TimeEntryRequest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+}
+
+// ActiveTimer - идущая сессия вместе с задачей и юзером, который её запустил
+type ActiveTimer struct {
+	EntryID        int       `json:"entry_id"`
+	TaskID         int       `json:"task_id"`
+	TaskName       string    `json:"task_name"`
+	UserID         int       `json:"user_id"`
+	Surname        string    `json:"surname"`
+	Name           string    `json:"name"`
+	Patronymic     string    `json:"patronymic"`
+	StartTime      time.Time `json:"start_time"`
+	ElapsedSeconds int64     `json:"elapsed_seconds"`
+}
+
+type ActiveTimerFilter struct {
+	UserID  int
+	Surname string
+	Name    string
+}
+
+// AutoStopRule - когда забытый таймер закрывается автоматически. MaxDuration - предельная длина сессии,
+// Cutoff - конец рабочего дня "15:04" в часовом поясе юзера. Нулевое значение отключает ограничение
+type AutoStopRule struct {
+	MaxDuration time.Duration
+	Cutoff      string
+}
+
+type TimeEntryRepo interface {
+	FindEntriesByTaskID(context.Context, int) ([]TimeEntry, error)
+	CreateEntry(context.Context, TimeEntry, []TaskStatus) (TimeEntry, error)
+	ImportEntry(context.Context, TimeEntry) (TimeEntry, error)
+	UpdateEntry(context.Context, TimeEntry) (TimeEntry, error)
+	DeleteEntry(context.Context, int, int, string) error
+	FindActiveTimers(context.Context, ActiveTimerFilter, int, int) ([]ActiveTimer, error)
+	AutoStopTimers(context.Context, AutoStopRule, time.Time) ([]TimeEntry, error)
+}
+
+type TimeEntryService interface {
+	CreateEntry(context.Context, int, int, TimeEntryRequest) (TimeEntry, error)
+	UpdateEntry(context.Context, int, int, TimeEntryRequest) (TimeEntry, error)
+	DeleteEntry(context.Context, int, int, string) error
+	GetActiveTimers(context.Context, ActiveTimerFilter, int, int) ([]ActiveTimer, error)
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ImportFormat - откуда выгрузка: toggl или clockify. Пустое значение - определить по заголовку CSV
+type ImportFormat string
+
+const (
+	ImportFormatToggl    ImportFormat = "toggl"
+	ImportFormatClockify ImportFormat = "clockify"
+)
+
+// ImportRow - строка выгрузки. Line - номер строки в файле вместе с заголовком
+type ImportRow struct {
+	Line     int
+	User     string
+	Email    string
+	TaskName string
+	Start    time.Time
+	End      time.Time
+}
+
+// ImportOptions - DryRun проверяет файл и откатывает транзакцию, CreateUsers заводит юзеров,
+// которых не нашли по email и имени, SkipInvalid загружает остальные строки, если в файле есть проблемные
+type ImportOptions struct {
+	Format      ImportFormat
+	Timezone    string
+	DryRun      bool
+	CreateUsers bool
+	SkipInvalid bool
+}
+
+// ImportIssue - строка, которую не загрузили, и причина
+type ImportIssue struct {
+	Line   int    `json:"line"`
+	User   string `json:"user,omitempty"`
+	Task   string `json:"task,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport - итог импорта. Invalid - строки, которые не разобрать, Unmatched - не нашёлся юзер,
+// Conflicts - сессия пересекается с уже отслеженным временем юзера или с другой строкой файла
+type ImportReport struct {
+	Format       ImportFormat  `json:"format"`
+	DryRun       bool          `json:"dry_run"`
+	Committed    bool          `json:"committed"`
+	Rows         int           `json:"rows"`
+	Imported     int           `json:"imported"`
+	CreatedUsers []string      `json:"created_users"`
+	CreatedTasks []string      `json:"created_tasks"`
+	Invalid      []ImportIssue `json:"invalid"`
+	Unmatched    []ImportIssue `json:"unmatched"`
+	Conflicts    []ImportIssue `json:"conflicts"`
+}
+
+type Transactor interface {
+	InTx(context.Context, func(context.Context) error) error
+}
+
+type ImportService interface {
+	ImportEntries(context.Context, io.Reader, ImportOptions) (ImportReport, error)
+}
@@ -0,0 +1,63 @@
+package models
+
+import "context"
+
+// Client - заказчик, TotalSeconds - всё отслеженное время по задачам его проектов
+type Client struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+type ClientRequest struct {
+	Name string `json:"name"`
+}
+
+// Project - проект группирует задачи разных юзеров. Без клиента - внутренний проект
+type Project struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	ClientID     *int   `json:"client_id"`
+	ClientName   string `json:"client_name,omitempty"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+type ProjectRequest struct {
+	Name     string `json:"name"`
+	ClientID *int   `json:"client_id"`
+}
+
+// TaskProjectRequest - привязка задачи к проекту, null отвязывает задачу
+type TaskProjectRequest struct {
+	ProjectID *int `json:"project_id"`
+}
+
+type ProjectFilter struct {
+	ClientID int
+}
+
+type ProjectRepo interface {
+	CreateClient(context.Context, string) (Client, error)
+	FindClients(context.Context) ([]Client, error)
+	FindClientByID(context.Context, int) (Client, error)
+	UpdateClient(context.Context, int, string) (Client, error)
+	DeleteClient(context.Context, int) error
+	CreateProject(context.Context, ProjectRequest) (Project, error)
+	FindProjects(context.Context, ProjectFilter) ([]Project, error)
+	FindProjectByID(context.Context, int) (Project, error)
+	UpdateProject(context.Context, int, ProjectRequest) (Project, error)
+	DeleteProject(context.Context, int) error
+}
+
+type ProjectService interface {
+	CreateClient(context.Context, ClientRequest) (Client, error)
+	GetClients(context.Context) ([]Client, error)
+	GetClientByID(context.Context, int) (Client, error)
+	UpdateClient(context.Context, int, ClientRequest) (Client, error)
+	DeleteClient(context.Context, int) error
+	CreateProject(context.Context, ProjectRequest) (Project, error)
+	GetProjects(context.Context, ProjectFilter) ([]Project, error)
+	GetProjectByID(context.Context, int) (Project, error)
+	UpdateProject(context.Context, int, ProjectRequest) (Project, error)
+	DeleteProject(context.Context, int) error
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type ReportBucket string
+
+const (
+	ReportBucketDay  ReportBucket = "day"
+	ReportBucketWeek ReportBucket = "week"
+)
+
+// ReportGroup - уровень, до которого сводятся строки отчёта
+type ReportGroup string
+
+const (
+	ReportGroupTask    ReportGroup = "task"
+	ReportGroupProject ReportGroup = "project"
+	ReportGroupClient  ReportGroup = "client"
+	ReportGroupTag     ReportGroup = "tag"
+)
+
+// ReportFilter - Location задаёт часовой пояс, в котором считаются границы дней и недель.
+// ProjectID и ClientID отбирают задачи проекта или клиента, 0 - без отбора
+type ReportFilter struct {
+	UserID    int
+	From      time.Time
+	To        time.Time
+	Location  *time.Location
+	Bucket    ReportBucket
+	ProjectID int
+	ClientID  int
+	GroupBy   ReportGroup
+}
+
+// ReportScope - задача, проект и клиент строки отчёта. При группировке по проекту задача пустая,
+// по клиенту - задача и проект. TagID заполнен только при группировке по тегу
+type ReportScope struct {
+	TaskID      int    `json:"task_id,omitempty"`
+	TagID       *int   `json:"tag_id,omitempty"`
+	ProjectID   *int   `json:"project_id,omitempty"`
+	ProjectName string `json:"project_name,omitempty"`
+	ClientID    *int   `json:"client_id,omitempty"`
+	ClientName  string `json:"client_name,omitempty"`
+}
+
+// TaskWorkload - AutoStoppedEntries показывает, сколько сессий за период закрыл воркер автоостановки,
+// такие часы стоит перепроверить
+type TaskWorkload struct {
+	ReportScope
+	Name               string `json:"name"`
+	Hours              int64  `json:"hours"`
+	Minutes            int64  `json:"minutes"`
+	TotalSeconds       int64  `json:"total_seconds"`
+	AutoStoppedEntries int    `json:"auto_stopped_entries"`
+}
+
+// PeriodSummary - затраты юзера за один день или неделю. Period - дата начала дня "2006-01-02"
+// или ISO-неделя "2006-W01", Start и End - границы в часовом поясе отчёта
+type PeriodSummary struct {
+	Period       string             `json:"period"`
+	Start        time.Time          `json:"start"`
+	End          time.Time          `json:"end"`
+	Hours        int64              `json:"hours"`
+	Minutes      int64              `json:"minutes"`
+	TotalSeconds int64              `json:"total_seconds"`
+	Groups       []ReportGroupTotal `json:"groups,omitempty"`
+}
+
+// ReportGroupTotal - время группы из group_by внутри дня или недели сводки
+type ReportGroupTotal struct {
+	ReportScope
+	Name         string `json:"name"`
+	Hours        int64  `json:"hours"`
+	Minutes      int64  `json:"minutes"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// SummaryCell - время по задаче за день или неделю, у дня или недели без сессий задача пустая
+type SummaryCell struct {
+	ReportScope
+	Name         string
+	Start        time.Time
+	End          time.Time
+	TotalSeconds int64
+}
+
+// TimesheetCell - время по задаче за один день, Day - начало дня в часовом поясе отчёта
+type TimesheetCell struct {
+	ReportScope
+	Name         string
+	Day          time.Time
+	TotalSeconds int64
+}
+
+// TimesheetRow - строка табеля: минуты по задаче, проекту или клиенту за каждый день из Timesheet.Days
+type TimesheetRow struct {
+	ReportScope
+	Name         string  `json:"name"`
+	Minutes      []int64 `json:"minutes"`
+	TotalMinutes int64   `json:"total_minutes"`
+}
+
+// Timesheet - табель за день или неделю: строки по задачам, столбцы по дням и итоги по обоим
+type Timesheet struct {
+	Period       string         `json:"period"`
+	Timezone     string         `json:"timezone"`
+	Days         []string       `json:"days"`
+	Rows         []TimesheetRow `json:"rows"`
+	DayTotals    []int64        `json:"day_totals"`
+	TotalMinutes int64          `json:"total_minutes"`
+}
+
+type ReportRepo interface {
+	GetWorkload(context.Context, ReportFilter) ([]TaskWorkload, error)
+	GetTagWorkload(context.Context, ReportFilter) ([]TaskWorkload, error)
+	GetSummary(context.Context, ReportFilter) ([]SummaryCell, error)
+	GetTimesheet(context.Context, ReportFilter) ([]TimesheetCell, error)
+	FindUserTimezone(context.Context, int) (string, error)
+	FindUserByID(context.Context, int) (User, error)
+}
+
+type ReportService interface {
+	GetWorkload(context.Context, ReportFilter) ([]TaskWorkload, error)
+	GetSummary(context.Context, ReportFilter) ([]PeriodSummary, error)
+	GetTimesheet(context.Context, ReportFilter, string) (Timesheet, error)
+	ResolveLocation(context.Context, int, string) (*time.Location, error)
+	GetUser(context.Context, int) (User, error)
+}
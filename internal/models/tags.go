@@ -0,0 +1,30 @@
+package models
+
+import "context"
+
+// Tag - метка задачи, TotalSeconds - всё отслеженное время по задачам с этой меткой
+type Tag struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type TagRepo interface {
+	CreateTag(context.Context, string) (Tag, error)
+	FindTags(context.Context) ([]Tag, error)
+	DeleteTag(context.Context, int) error
+	AttachTag(context.Context, int, int) error
+	DetachTag(context.Context, int, int) error
+}
+
+type TagService interface {
+	CreateTag(context.Context, TagRequest) (Tag, error)
+	GetTags(context.Context) ([]Tag, error)
+	DeleteTag(context.Context, int) error
+	AttachTag(context.Context, int, int) error
+	DetachTag(context.Context, int, int) error
+}
@@ -5,35 +5,158 @@ import (
 	"time"
 )
 
+type TimerState string
+
+const (
+	TimerIdle    TimerState = "idle"
+	TimerRunning TimerState = "running"
+	TimerPaused  TimerState = "paused"
+	TimerStopped TimerState = "stopped"
+)
+
+type TimerPolicy string
+
+const (
+	TimerPolicySwitch   TimerPolicy = "switch"
+	TimerPolicyReject   TimerPolicy = "reject"
+	TimerPolicyParallel TimerPolicy = "parallel"
+)
+
+// TaskStatus - этап жизненного цикла задачи, переходы между статусами проверяет TaskService
+type TaskStatus string
+
+const (
+	TaskTodo       TaskStatus = "todo"
+	TaskInProgress TaskStatus = "in_progress"
+	TaskDone       TaskStatus = "done"
+	TaskArchived   TaskStatus = "archived"
+)
+
+type TimerStart struct {
+	TaskID       int        `json:"task_id"`
+	State        TimerState `json:"state"`
+	StoppedTasks []int      `json:"stopped_tasks"`
+}
+
 type Task struct {
-	ID        int        `json:"id"`
-	Name      string     `json:"name"`
-	UserID    int        `json:"user_id"`
-	StartTime *time.Time `json:"start_time"`
-	EndTime   *time.Time `json:"end_time"`
+	ID               int            `json:"id"`
+	Name             string         `json:"name"`
+	UserID           int            `json:"user_id"`
+	UserFullName     string         `json:"user_full_name"`
+	StartTime        *time.Time     `json:"start_time"`
+	EndTime          *time.Time     `json:"end_time"`
+	State            TimerState     `json:"state"`
+	Status           TaskStatus     `json:"status"`
+	TotalSeconds     int64          `json:"total_seconds"`
+	EstimateSeconds  *int64         `json:"estimate_seconds"`
+	RemainingSeconds *int64         `json:"remaining_seconds"`
+	ProjectID        *int           `json:"project_id"`
+	ProjectName      string         `json:"project_name,omitempty"`
+	Tags             []string       `json:"tags"`
+	ParentID         *int           `json:"parent_id"`
+	RollupSeconds    int64          `json:"rollup_seconds,omitempty"`
+	Subtasks         []TaskTree     `json:"subtasks,omitempty"`
+	Assignees        []TaskAssignee `json:"assignees,omitempty"`
+	Entries          []TimeEntry    `json:"entries,omitempty"`
+}
+
+// TaskBudget - задача с оценкой и отслеженным по ней временем. RemainingSeconds отрицательный
+// при перерасходе, UsedPercent - сколько процентов оценки уже потрачено
+type TaskBudget struct {
+	ID               int        `json:"id"`
+	Name             string     `json:"name"`
+	UserID           int        `json:"user_id"`
+	UserFullName     string     `json:"user_full_name"`
+	Status           TaskStatus `json:"status"`
+	EstimateSeconds  int64      `json:"estimate_seconds"`
+	TotalSeconds     int64      `json:"total_seconds"`
+	RemainingSeconds int64      `json:"remaining_seconds"`
+	UsedPercent      float64    `json:"used_percent"`
+}
+
+// TaskTree - задача в дереве подзадач. TotalSeconds - время по самой задаче,
+// RollupSeconds - вместе со всеми потомками. У Task эти поля заполняются только при запросе дерева
+type TaskTree struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	ParentID      *int       `json:"parent_id"`
+	Status        TaskStatus `json:"status"`
+	TotalSeconds  int64      `json:"total_seconds"`
+	RollupSeconds int64      `json:"rollup_seconds"`
+	Subtasks      []TaskTree `json:"subtasks,omitempty"`
+}
+
+// TaskAssignee - исполнитель задачи и время, которое он отследил по ней
+type TaskAssignee struct {
+	UserID       int    `json:"user_id"`
+	UserFullName string `json:"user_full_name"`
+	TotalSeconds int64  `json:"total_seconds"`
 }
 
 type NewTaskRequest struct {
-	Name   string `json:"name"`
-	UserID int    `json:"user_id"`
+	Name            string `json:"name"`
+	UserID          int    `json:"user_id"`
+	ProjectID       *int   `json:"project_id,omitempty"`
+	ParentID        *int   `json:"parent_id,omitempty"`
+	EstimateSeconds *int64 `json:"estimate_seconds,omitempty"`
+}
+
+type TaskParentRequest struct {
+	ParentID *int `json:"parent_id"`
+}
+
+type TaskEstimateRequest struct {
+	EstimateSeconds *int64 `json:"estimate_seconds"`
+}
+
+type TaskStatusRequest struct {
+	Status TaskStatus `json:"status"`
+}
+
+// TaskFilter - отбор задач в списках: Tags - задачи со всеми перечисленными тегами,
+// Statuses - задачи в одном из статусов. Пустой список - без отбора
+type TaskFilter struct {
+	Tags     []string
+	Statuses []TaskStatus
 }
 
 type TaskRepo interface {
-	AddTask(context.Context, string, int) (Task, error)
+	AddTask(context.Context, string, int, *int, *int, *int64) (Task, error)
+	SetTaskProject(context.Context, int, *int) error
+	SetTaskStatus(context.Context, int, TaskStatus, []TaskStatus, bool) (TaskStatus, error)
+	SetTaskParent(context.Context, int, *int) error
+	SetTaskEstimate(context.Context, int, *int64) error
+	FindOverBudgetTasks(context.Context, int) ([]TaskBudget, error)
+	FindTaskTree(context.Context, int) ([]TaskTree, error)
+	AssignTask(context.Context, int, int) error
+	UnassignTask(context.Context, int, int) error
+	FindTaskAssignees(context.Context, int) ([]TaskAssignee, error)
 	FindTaskByID(context.Context, int) (Task, error)
-	FindTasksByUserID(context.Context, int, string, string) ([]Task, error)
+	FindTaskByName(context.Context, int, string) (Task, error)
+	FindTasksByUserID(context.Context, int, string, string, TaskFilter) ([]Task, error)
 	DeleteTaskByID(context.Context, int) error
-	StartTimeTracker(context.Context, int, int) error
-	StopTimeTracker(context.Context, int, int) error
-	GetAllTasks(context.Context) ([]Task, error)
+	StartTimeTracker(context.Context, int, int, []TimerState, []TaskStatus, TimerPolicy) (TimerState, []int, error)
+	PauseTimeTracker(context.Context, int, int, []TimerState) (TimerState, error)
+	ResumeTimeTracker(context.Context, int, int, []TimerState, []TaskStatus, TimerPolicy) (TimerState, []int, error)
+	StopTimeTracker(context.Context, int, int, []TimerState) (TimerState, error)
+	GetAllTasks(context.Context, TaskFilter) ([]Task, error)
 }
 
 type TaskService interface {
-	CreateTask(context.Context, string, int) (Task, error)
-	GetTaskByID(context.Context, int) (Task, error)
-	GetTasksByUserID(context.Context, int, string, string) ([]Task, error)
+	CreateTask(context.Context, string, int, *int, *int, *int64) (Task, error)
+	GetTaskByID(context.Context, int, bool) (Task, error)
+	SetTaskProject(context.Context, int, *int) (Task, error)
+	SetTaskStatus(context.Context, int, TaskStatus) (Task, error)
+	SetTaskParent(context.Context, int, *int) (Task, error)
+	SetTaskEstimate(context.Context, int, *int64) (Task, error)
+	GetOverBudgetTasks(context.Context, int) ([]TaskBudget, error)
+	AssignTask(context.Context, int, int) error
+	UnassignTask(context.Context, int, int) error
+	GetTasksByUserID(context.Context, int, string, string, TaskFilter) ([]Task, error)
 	DeleteTaskByID(context.Context, int) error
-	StartTimeTracker(context.Context, int, int) error
+	StartTimeTracker(context.Context, int, int) (TimerStart, error)
+	PauseTimeTracker(context.Context, int, int) error
+	ResumeTimeTracker(context.Context, int, int) (TimerStart, error)
 	StopTimeTracker(context.Context, int, int) error
-	GetAllTasks(context.Context) ([]Task, error)
+	GetAllTasks(context.Context, TaskFilter) ([]Task, error)
 }
@@ -4,15 +4,20 @@ import "context"
 
 type NewUserRequest struct {
 	PassportNumber string `json:"passportNumber"`
+	Timezone       string `json:"timezone,omitempty"`
 }
 
+// User - юзер. Пока EnrichmentStatus pending, ФИО и адрес пустые: их дозаполняет воркер из People API
 type User struct {
-	ID             int    `json:"id"`
-	PassportNumber string `json:"passportNumber"`
-	Surname        string `json:"surname"`
-	Name           string `json:"name"`
-	Patronymic     string `json:"patronymic"`
-	Address        string `json:"address"`
+	ID               int              `json:"id"`
+	PassportNumber   string           `json:"passportNumber"`
+	Surname          string           `json:"surname"`
+	Name             string           `json:"name"`
+	Patronymic       string           `json:"patronymic"`
+	Address          string           `json:"address"`
+	Timezone         string           `json:"timezone"`
+	EnrichmentStatus EnrichmentStatus `json:"enrichment_status,omitempty"`
+	EnrichmentError  string           `json:"enrichment_error,omitempty"`
 }
 
 type ServiceUser struct {
@@ -21,33 +26,68 @@ type ServiceUser struct {
 	Name        string `json:"name"`
 	Patronymic  string `json:"patronymic"`
 	Address     string `json:"address"`
+	Timezone    string `json:"timezone"`
+	Email       string `json:"email,omitempty"`
 }
 
+// APIResponse - данные юзера из внешнего API, они же тело запроса на обновление.
+// Timezone во внешнем API нет, пустое значение при обновлении оставляет текущий пояс
 type APIResponse struct {
 	Surname    string `json:"surname"`
 	Name       string `json:"name"`
 	Patronymic string `json:"patronymic"`
 	Address    string `json:"address"`
+	Timezone   string `json:"timezone,omitempty"`
 }
 
 type UserFilter struct {
-	PassportNum string
-	Surname     string
-	Name        string
-	Patronymic  string
-	Address     string
+	PassportNum      string
+	Surname          string
+	Name             string
+	Patronymic       string
+	Address          string
+	EnrichmentStatus EnrichmentStatus
+}
+
+// FieldChange - поле юзера, которое у провайдера отличается от сохранённого
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// UserRefresh - результат перезапроса данных юзера у провайдеров. Applied - изменения записаны,
+// Error - почему юзера не удалось обновить при массовом обновлении
+type UserRefresh struct {
+	UserID  int           `json:"user_id"`
+	Changes []FieldChange `json:"changes"`
+	Applied bool          `json:"applied"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type RefreshUsersRequest struct {
+	UserIDs []int `json:"user_ids"`
+	Apply   bool  `json:"apply"`
 }
 
 type UserRepo interface {
 	GetAllUsers(context.Context, UserFilter, int, int) ([]User, error)
 	AddUser(context.Context, ServiceUser) (int, error)
+	AddPendingUser(context.Context, string, string) (int, error)
+	FindUserByID(context.Context, int) (User, error)
+	LockUserByID(context.Context, int) (User, error)
+	ApplyUserRefresh(context.Context, int, []FieldChange) error
+	FindUserByEmail(context.Context, string) (User, error)
+	FindUsersByFullName(context.Context, string) ([]User, error)
 	UpdateUser(context.Context, APIResponse, int) (User, error)
 	DeleteUser(context.Context, int) error
 }
 
 type UserService interface {
 	GetAllUsers(context.Context, UserFilter, int, int) ([]User, error)
-	CreateUser(context.Context, APIResponse, string) (User, error)
+	CreateUser(context.Context, string, string) (User, error)
+	RefreshUser(context.Context, int, bool) (UserRefresh, error)
+	RefreshUsers(context.Context, []int, bool) ([]UserRefresh, error)
 	UpdateUser(context.Context, APIResponse, int) (User, error)
 	DeleteUser(context.Context, int) error
 }
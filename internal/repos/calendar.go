@@ -0,0 +1,97 @@
+package repos
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrCalendarTokenNotFound = errors.New("calendar token not found")
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// SaveCalendarToken - сохраняет хэш токена юзера, прежний токен перестаёт действовать
+func (cr *CalendarRepository) SaveCalendarToken(ctx context.Context, usrID int, tokenHash string) error {
+	var exists bool
+
+	err := cr.db.QueryRowContext(ctx, queries.ExistCheck, usrID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrUsrNotExists
+	}
+
+	_, err = cr.db.ExecContext(ctx, queries.SaveCalendarToken, usrID, tokenHash)
+
+	return err
+}
+
+func (cr *CalendarRepository) DeleteCalendarToken(ctx context.Context, usrID int) error {
+	result, err := cr.db.ExecContext(ctx, queries.DeleteCalendarToken, usrID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrCalendarTokenNotFound
+	}
+
+	return nil
+}
+
+func (cr *CalendarRepository) FindCalendarTokenHash(ctx context.Context, usrID int) (string, error) {
+	var tokenHash string
+
+	err := cr.db.QueryRowContext(ctx, queries.FindCalendarTokenHash, usrID).Scan(&tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrCalendarTokenNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return tokenHash, nil
+}
+
+func (cr *CalendarRepository) FindCalendarEvents(
+	ctx context.Context,
+	filter models.CalendarFilter,
+) ([]models.CalendarEvent, error) {
+	rows, err := cr.db.QueryContext(ctx, queries.FindCalendarEvents, filter.UserID, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var events []models.CalendarEvent
+
+	for rows.Next() {
+		var event models.CalendarEvent
+
+		err = rows.Scan(&event.EntryID, &event.TaskID, &event.TaskName, &event.Source, &event.Start, &event.End)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,126 @@
+package repos
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrEnrichmentCacheMiss = errors.New("no fresh enrichment cache entry")
+
+type EnrichmentRepository struct {
+	db *sql.DB
+}
+
+func NewEnrichmentRepository(db *sql.DB) *EnrichmentRepository {
+	return &EnrichmentRepository{db: db}
+}
+
+// ClaimEnrichmentJobs - до limit готовых задач, которые на lease становятся невидимы для других воркеров
+func (er *EnrichmentRepository) ClaimEnrichmentJobs(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]models.EnrichmentJob, error) {
+	rows, err := er.db.QueryContext(ctx, queries.ClaimEnrichmentJobs, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var jobs []models.EnrichmentJob
+
+	for rows.Next() {
+		var job models.EnrichmentJob
+
+		err = rows.Scan(&job.ID, &job.UserID, &job.PassportNumber, &job.Attempts)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// CompleteEnrichmentJob - дописывает данные из People API в пустые поля юзера и удаляет задачу
+func (er *EnrichmentRepository) CompleteEnrichmentJob(ctx context.Context, jobID int, info models.APIResponse) error {
+	_, err := er.db.ExecContext(
+		ctx,
+		queries.CompleteEnrichmentJob,
+		jobID,
+		info.Surname,
+		info.Name,
+		info.Patronymic,
+		info.Address,
+	)
+
+	return err
+}
+
+func (er *EnrichmentRepository) RetryEnrichmentJob(ctx context.Context, jobID int, runAt time.Time, reason string) error {
+	_, err := er.db.ExecContext(ctx, queries.RetryEnrichmentJob, jobID, runAt, reason)
+
+	return err
+}
+
+// FailEnrichmentJob - юзер остаётся без данных со статусом failed и причиной, задача удаляется
+func (er *EnrichmentRepository) FailEnrichmentJob(ctx context.Context, jobID int, reason string) error {
+	_, err := er.db.ExecContext(ctx, queries.FailEnrichmentJob, jobID, reason)
+
+	return err
+}
+
+// FindEnrichmentCache - непросроченная запись кэша по хэшу паспорта
+func (er *EnrichmentRepository) FindEnrichmentCache(ctx context.Context, hash string) (models.EnrichmentCacheEntry, error) {
+	entry := models.EnrichmentCacheEntry{PassportHash: hash}
+
+	err := er.db.QueryRowContext(ctx, queries.FindEnrichmentCache, hash).Scan(
+		&entry.Person.Surname,
+		&entry.Person.Name,
+		&entry.Person.Patronymic,
+		&entry.Person.Address,
+		&entry.NotFound,
+		&entry.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.EnrichmentCacheEntry{}, ErrEnrichmentCacheMiss
+	}
+
+	if err != nil {
+		return models.EnrichmentCacheEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (er *EnrichmentRepository) SaveEnrichmentCache(ctx context.Context, entry models.EnrichmentCacheEntry) error {
+	_, err := er.db.ExecContext(
+		ctx,
+		queries.SaveEnrichmentCache,
+		entry.PassportHash,
+		entry.Person.Surname,
+		entry.Person.Name,
+		entry.Person.Patronymic,
+		entry.Person.Address,
+		entry.NotFound,
+		entry.ExpiresAt,
+	)
+
+	return err
+}
+
+// DeleteExpiredEnrichmentCache - удаляет просроченные записи кэша, возвращает их число
+func (er *EnrichmentRepository) DeleteExpiredEnrichmentCache(ctx context.Context) (int64, error) {
+	result, err := er.db.ExecContext(ctx, queries.DeleteExpiredEnrichmentCache)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
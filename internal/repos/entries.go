@@ -0,0 +1,429 @@
+package repos
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"slices"
+	"time"
+)
+
+var ErrEntryNotFound = errors.New("time entry not found")
+var ErrEntryRunning = errors.New("time entry is still running")
+var ErrEntryOverlap = errors.New("time entry overlaps another entry of the user")
+
+// Действия, которые пишутся в журнал ручных правок time_entry_audit
+const (
+	auditCreate = "create"
+	auditUpdate = "update"
+	auditDelete = "delete"
+)
+
+type TimeEntriesRepository struct {
+	db *sql.DB
+}
+
+func NewTimeEntriesRepository(db *sql.DB) *TimeEntriesRepository {
+	return &TimeEntriesRepository{db: db}
+}
+
+func (er *TimeEntriesRepository) FindEntriesByTaskID(ctx context.Context, taskID int) ([]models.TimeEntry, error) {
+	rows, err := er.db.QueryContext(ctx, queries.FindEntriesByTaskID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []models.TimeEntry
+
+	for rows.Next() {
+		var entry models.TimeEntry
+
+		err = rows.Scan(
+			&entry.ID,
+			&entry.TaskID,
+			&entry.UserID,
+			&entry.StartTime,
+			&entry.EndTime,
+			&entry.ClosedBy,
+			&entry.Source,
+			&entry.Reason,
+			&entry.DurationSeconds,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// FindActiveTimers - идущие сессии с именами юзеров, самые долгие сверху
+func (er *TimeEntriesRepository) FindActiveTimers(
+	ctx context.Context,
+	filter models.ActiveTimerFilter,
+	pg, lim int,
+) ([]models.ActiveTimer, error) {
+	query := squirrel.Select(
+		"te.id",
+		"t.id",
+		"t.name",
+		"u.id",
+		"u.surname",
+		"u.name",
+		"u.patronymic",
+		"te.start_time",
+		"EXTRACT(EPOCH FROM NOW() - te.start_time)::BIGINT",
+	).
+		From("time_entries te").
+		Join("tasks t ON t.id = te.task_id").
+		Join("users u ON u.id = te.user_id").
+		Where("te.end_time IS NULL")
+
+	if filter.UserID != 0 {
+		query = query.Where(squirrel.Eq{"u.id": filter.UserID})
+	}
+
+	if filter.Surname != "" {
+		query = query.Where(squirrel.Eq{"u.surname": filter.Surname})
+	}
+
+	if filter.Name != "" {
+		query = query.Where(squirrel.Eq{"u.name": filter.Name})
+	}
+
+	offset := (pg - 1) * lim
+	query = query.Limit(uint64(lim)).Offset(uint64(offset))
+
+	query = query.OrderBy("te.start_time", "te.id")
+
+	query = query.PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := er.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var timers []models.ActiveTimer
+
+	for rows.Next() {
+		var timer models.ActiveTimer
+
+		err = rows.Scan(
+			&timer.EntryID,
+			&timer.TaskID,
+			&timer.TaskName,
+			&timer.UserID,
+			&timer.Surname,
+			&timer.Name,
+			&timer.Patronymic,
+			&timer.StartTime,
+			&timer.ElapsedSeconds,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		timers = append(timers, timer)
+	}
+
+	return timers, nil
+}
+
+// CreateEntry - добавляет закрытую сессию вручную и пишет правку в журнал. Как и таймер,
+// ручное время принимается, только если статус задачи входит в trackable
+func (er *TimeEntriesRepository) CreateEntry(
+	ctx context.Context,
+	entry models.TimeEntry,
+	trackable []models.TaskStatus,
+) (models.TimeEntry, error) {
+	tx, err := er.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	defer tx.Rollback()
+
+	var status models.TaskStatus
+
+	err = tx.QueryRowContext(ctx, queries.LockUserTaskStatus, entry.TaskID, entry.UserID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TimeEntry{}, ErrTaskNotFound
+	}
+
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	if !slices.Contains(trackable, status) {
+		return models.TimeEntry{}, ErrTaskStatusConflict
+	}
+
+	err = checkOverlap(ctx, tx, entry.UserID, 0, entry.StartTime, *entry.EndTime)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		queries.CreateEntry,
+		entry.TaskID,
+		entry.UserID,
+		entry.StartTime,
+		entry.EndTime,
+		entry.Reason,
+	).Scan(&entry.ID)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queries.CreateEntryAudit,
+		entry.ID,
+		entry.TaskID,
+		entry.UserID,
+		auditCreate,
+		nil,
+		nil,
+		entry.StartTime,
+		entry.EndTime,
+		entry.Reason,
+	)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	entry.ClosedBy = "stop"
+	entry.Source = "manual"
+	entry.DurationSeconds = int64(entry.EndTime.Sub(entry.StartTime).Seconds())
+
+	return entry, tx.Commit()
+}
+
+// ImportEntry - добавляет закрытую сессию из выгрузки другого трекера. Вызывается внутри InTx:
+// блокировка юзера держится до конца импорта, поэтому пересечения ищутся и среди уже загруженных строк
+func (er *TimeEntriesRepository) ImportEntry(ctx context.Context, entry models.TimeEntry) (models.TimeEntry, error) {
+	q := conn(ctx, er.db)
+
+	err := checkOverlap(ctx, q, entry.UserID, 0, entry.StartTime, *entry.EndTime)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	err = q.QueryRowContext(
+		ctx,
+		queries.CreateImportedEntry,
+		entry.TaskID,
+		entry.UserID,
+		entry.StartTime,
+		entry.EndTime,
+		entry.Reason,
+	).Scan(&entry.ID)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	entry.ClosedBy = "stop"
+	entry.Source = "import"
+	entry.DurationSeconds = int64(entry.EndTime.Sub(entry.StartTime).Seconds())
+
+	return entry, nil
+}
+
+// UpdateEntry - меняет границы закрытой сессии и пишет правку вместе со старыми границами в журнал
+func (er *TimeEntriesRepository) UpdateEntry(ctx context.Context, entry models.TimeEntry) (models.TimeEntry, error) {
+	tx, err := er.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	defer tx.Rollback()
+
+	old, err := lockClosedEntry(ctx, tx, entry.ID, entry.UserID)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	err = checkOverlap(ctx, tx, entry.UserID, entry.ID, entry.StartTime, *entry.EndTime)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		queries.UpdateEntry,
+		entry.ID,
+		entry.StartTime,
+		entry.EndTime,
+		entry.Reason,
+	).Scan(&entry.TaskID, &entry.ClosedBy, &entry.Source)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queries.CreateEntryAudit,
+		entry.ID,
+		entry.TaskID,
+		entry.UserID,
+		auditUpdate,
+		old.StartTime,
+		old.EndTime,
+		entry.StartTime,
+		entry.EndTime,
+		entry.Reason,
+	)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	entry.DurationSeconds = int64(entry.EndTime.Sub(entry.StartTime).Seconds())
+
+	return entry, tx.Commit()
+}
+
+// DeleteEntry - удаляет закрытую сессию, в журнале остаются её границы и причина удаления
+func (er *TimeEntriesRepository) DeleteEntry(ctx context.Context, entryID, usrID int, reason string) error {
+	tx, err := er.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	old, err := lockClosedEntry(ctx, tx, entryID, usrID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, queries.DeleteEntry, entryID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		queries.CreateEntryAudit,
+		entryID,
+		old.TaskID,
+		usrID,
+		auditDelete,
+		old.StartTime,
+		old.EndTime,
+		nil,
+		nil,
+		reason,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockClosedEntry - блокирует сессию юзера до конца транзакции. Идущие сессии
+// вручную не правятся, ими управляет таймер
+func lockClosedEntry(ctx context.Context, tx *sql.Tx, entryID, usrID int) (models.TimeEntry, error) {
+	var entry models.TimeEntry
+
+	err := tx.QueryRowContext(ctx, queries.LockUserEntry, entryID, usrID).Scan(
+		&entry.TaskID,
+		&entry.StartTime,
+		&entry.EndTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TimeEntry{}, ErrEntryNotFound
+	}
+
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	if entry.EndTime == nil {
+		return models.TimeEntry{}, ErrEntryRunning
+	}
+
+	return entry, nil
+}
+
+// checkOverlap - блокирует строку юзера, чтобы правки его сессий шли по очереди,
+// и проверяет, что новый период не пересекается с другими сессиями юзера
+func checkOverlap(ctx context.Context, tx querier, usrID, entryID int, start, end time.Time) error {
+	var lockedID int
+
+	err := tx.QueryRowContext(ctx, queries.LockUser, usrID).Scan(&lockedID)
+	if err != nil {
+		return err
+	}
+
+	var overlaps bool
+
+	err = tx.QueryRowContext(ctx, queries.EntryOverlapCheck, usrID, entryID, start, end).Scan(&overlaps)
+	if err != nil {
+		return err
+	}
+
+	if overlaps {
+		return ErrEntryOverlap
+	}
+
+	return nil
+}
+
+// AutoStopTimers - закрывает забытые таймеры на момент срабатывания правила и возвращает закрытые сессии
+func (er *TimeEntriesRepository) AutoStopTimers(
+	ctx context.Context,
+	rule models.AutoStopRule,
+	now time.Time,
+) ([]models.TimeEntry, error) {
+	var maxSeconds, cutoff any
+
+	if rule.MaxDuration > 0 {
+		maxSeconds = int64(rule.MaxDuration.Seconds())
+	}
+
+	if rule.Cutoff != "" {
+		cutoff = rule.Cutoff
+	}
+
+	rows, err := er.db.QueryContext(ctx, queries.AutoStopTimers, maxSeconds, cutoff, now)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []models.TimeEntry
+
+	for rows.Next() {
+		var entry models.TimeEntry
+
+		err = rows.Scan(&entry.ID, &entry.TaskID, &entry.UserID, &entry.StartTime, &entry.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.ClosedBy = "auto"
+		entry.Source = "tracker"
+		entry.DurationSeconds = int64(entry.EndTime.Sub(entry.StartTime).Seconds())
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,231 @@
+package repos
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+)
+
+var ErrClientNotFound = errors.New("client not found")
+var ErrClientExists = errors.New("client with this name already exists")
+var ErrClientHasProjects = errors.New("client has projects")
+var ErrProjectNotFound = errors.New("project not found")
+var ErrProjectExists = errors.New("project with this name already exists for the client")
+
+// Коды ошибок Postgres для нарушенных ограничений
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+)
+
+func isPQError(err error, code string) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
+
+type ProjectsRepository struct {
+	db *sql.DB
+}
+
+func NewProjectsRepository(db *sql.DB) *ProjectsRepository {
+	return &ProjectsRepository{db: db}
+}
+
+func (pr *ProjectsRepository) CreateClient(ctx context.Context, name string) (models.Client, error) {
+	var client models.Client
+
+	err := pr.db.QueryRowContext(ctx, queries.CreateClient, name).Scan(&client.ID, &client.Name)
+	if isPQError(err, pqUniqueViolation) {
+		return models.Client{}, ErrClientExists
+	}
+
+	if err != nil {
+		return models.Client{}, err
+	}
+
+	return client, nil
+}
+
+func (pr *ProjectsRepository) FindClients(ctx context.Context) ([]models.Client, error) {
+	rows, err := pr.db.QueryContext(ctx, queries.FindClients)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	clients := []models.Client{}
+
+	for rows.Next() {
+		var client models.Client
+
+		err = rows.Scan(&client.ID, &client.Name, &client.TotalSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+func (pr *ProjectsRepository) FindClientByID(ctx context.Context, id int) (models.Client, error) {
+	var client models.Client
+
+	err := pr.db.QueryRowContext(ctx, queries.FindClientByID, id).Scan(&client.ID, &client.Name, &client.TotalSeconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Client{}, ErrClientNotFound
+	}
+
+	if err != nil {
+		return models.Client{}, err
+	}
+
+	return client, nil
+}
+
+func (pr *ProjectsRepository) UpdateClient(ctx context.Context, id int, name string) (models.Client, error) {
+	var client models.Client
+
+	err := pr.db.QueryRowContext(ctx, queries.UpdateClient, id, name).Scan(&client.ID, &client.Name, &client.TotalSeconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Client{}, ErrClientNotFound
+	}
+
+	if isPQError(err, pqUniqueViolation) {
+		return models.Client{}, ErrClientExists
+	}
+
+	if err != nil {
+		return models.Client{}, err
+	}
+
+	return client, nil
+}
+
+// DeleteClient - клиента с проектами не удалить: сначала нужно удалить или перенести его проекты
+func (pr *ProjectsRepository) DeleteClient(ctx context.Context, id int) error {
+	result, err := pr.db.ExecContext(ctx, queries.DeleteClient, id)
+	if isPQError(err, pqForeignKeyViolation) {
+		return ErrClientHasProjects
+	}
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrClientNotFound
+	}
+
+	return nil
+}
+
+func scanProject(row interface{ Scan(...any) error }) (models.Project, error) {
+	var project models.Project
+
+	err := row.Scan(&project.ID, &project.Name, &project.ClientID, &project.ClientName, &project.TotalSeconds)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+// projectError - ошибки ограничений при записи проекта: несуществующий клиент или повтор названия
+func projectError(err error) error {
+	switch {
+	case isPQError(err, pqForeignKeyViolation):
+		return ErrClientNotFound
+	case isPQError(err, pqUniqueViolation):
+		return ErrProjectExists
+	default:
+		return err
+	}
+}
+
+func (pr *ProjectsRepository) CreateProject(ctx context.Context, req models.ProjectRequest) (models.Project, error) {
+	project, err := scanProject(pr.db.QueryRowContext(ctx, queries.CreateProject, req.Name, req.ClientID))
+	if err != nil {
+		return models.Project{}, projectError(err)
+	}
+
+	return project, nil
+}
+
+func (pr *ProjectsRepository) FindProjects(ctx context.Context, filter models.ProjectFilter) ([]models.Project, error) {
+	rows, err := pr.db.QueryContext(ctx, queries.FindProjects, filter.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	projects := []models.Project{}
+
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+func (pr *ProjectsRepository) FindProjectByID(ctx context.Context, id int) (models.Project, error) {
+	project, err := scanProject(pr.db.QueryRowContext(ctx, queries.FindProjectByID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Project{}, ErrProjectNotFound
+	}
+
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+func (pr *ProjectsRepository) UpdateProject(ctx context.Context, id int, req models.ProjectRequest) (models.Project, error) {
+	project, err := scanProject(pr.db.QueryRowContext(ctx, queries.UpdateProject, id, req.Name, req.ClientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Project{}, ErrProjectNotFound
+	}
+
+	if err != nil {
+		return models.Project{}, projectError(err)
+	}
+
+	return project, nil
+}
+
+// DeleteProject - задачи проекта остаются без проекта вместе со всем отслеженным временем
+func (pr *ProjectsRepository) DeleteProject(ctx context.Context, id int) error {
+	result, err := pr.db.ExecContext(ctx, queries.DeleteProject, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
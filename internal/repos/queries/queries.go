@@ -11,22 +11,59 @@ const (
 	`
 
 	CreateUser = `
-		INSERT INTO users (passport_number, surname, name, patronymic, address)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (passport_number, surname, name, patronymic, address, timezone, email)
+		VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'UTC'), NULLIF($7, ''))
 		RETURNING id;
 	`
 
+	// Юзер заводится только с паспортом, остальное дозаполнит воркер по задаче из очереди
+	CreatePendingUser = `
+		WITH usr AS (
+		    INSERT INTO users (passport_number, surname, name, patronymic, address, timezone, enrichment_status)
+		    VALUES ($1, '', '', '', '', COALESCE(NULLIF($2, ''), 'UTC'), 'pending')
+		    RETURNING id
+		), job AS (
+		    INSERT INTO enrichment_jobs (user_id)
+		    SELECT id FROM usr
+		)
+		SELECT id
+		FROM usr;
+	`
+
 	FindUserByID = `
-		SELECT id, passport_number, surname, name, patronymic, address
+		SELECT id, passport_number, surname, name, patronymic, address, timezone
+		FROM users
+		WHERE id = $1;
+	`
+
+	FindUserByEmail = `
+		SELECT id, passport_number, surname, name, patronymic, address, timezone
+		FROM users
+		WHERE LOWER(email) = LOWER($1);
+	`
+
+	FindUsersByFullName = `
+		SELECT id, passport_number, surname, name, patronymic, address, timezone
+		FROM users
+		WHERE LOWER($1) IN (
+		    LOWER(TRIM(CONCAT_WS(' ', surname, name, patronymic))),
+		    LOWER(TRIM(CONCAT_WS(' ', surname, name))),
+		    LOWER(TRIM(CONCAT_WS(' ', name, surname))))
+		ORDER BY id;
+	`
+
+	FindUserTimezone = `
+		SELECT timezone
 		FROM users
 		WHERE id = $1;
 	`
 
 	UpdateUser = `
 		UPDATE users
-		SET surname= $2,name= $3,patronymic= $4,address= $5
+		SET surname= $2,name= $3,patronymic= $4,address= $5,timezone= COALESCE(NULLIF($6, ''), timezone)
 		WHERE id = $1
-		RETURNING id, passport_number, surname, name, patronymic, address;
+		RETURNING id, passport_number, surname, name, patronymic, address, timezone, enrichment_status,
+		          COALESCE(enrichment_error, '');
 	`
 
 	DeleteUser = `
@@ -34,43 +71,785 @@ const (
 		WHERE id = $1;
 	`
 
+	// Строка юзера блокируется до конца транзакции обновления, чтобы параллельный PATCH не потерялся
+	LockUserByID = `
+		SELECT id, passport_number, surname, name, patronymic, address, timezone
+		FROM users
+		WHERE id = $1
+		FOR UPDATE;
+	`
+
+	// $2, $3, $4 - массивы полей, старых и новых значений одинаковой длины
+	InsertUserChanges = `
+		INSERT INTO user_changes (user_id, field, old_value, new_value)
+		SELECT $1, c.field, c.old_value, c.new_value
+		FROM unnest($2::text[], $3::text[], $4::text[]) AS c(field, old_value, new_value);
+	`
+
 	//----------------------------------------------
 
 	// TASKS QUERIES---------------------------------
 
+	// Агрегаты по сессиям задачи из time_entries te: начало первой сессии, конец последней
+	// (пока таймер идёт - NULL), состояние таймера и суммарное отслеженное время в секундах.
+	// Пауза закрывает сессию, поэтому время на паузе в сумму не попадает. Из них собираются
+	// запросы задач ниже и запрос задач юзера в TasksRepository
+	TaskUserName   = `CONCAT_WS(' ', u.surname, u.name, u.patronymic)`
+	TaskStartTime  = `MIN(te.start_time)`
+	TaskEndTime    = `CASE WHEN bool_and(te.end_time IS NOT NULL) THEN MAX(te.end_time) END`
+	TrackedSeconds = `COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(te.end_time, NOW()) - te.start_time)), 0)::BIGINT`
+	TaskTags       = `ARRAY(SELECT tg.name FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.task_id = t.id ORDER BY tg.name)`
+
+	// Состояние таймера по последней сессии трекера: TaskState - по сессиям всех исполнителей,
+	// UserTaskState - только по сессиям юзера из плейсхолдера squirrel
+	taskSessionState = `
+		SELECT CASE WHEN s.end_time IS NULL THEN 'running' WHEN s.closed_by = 'pause' THEN 'paused' ELSE 'stopped' END
+		FROM time_entries s
+		WHERE s.task_id = t.id AND s.source = 'tracker'`
+	taskLastSession = `
+		ORDER BY (s.end_time IS NULL) DESC, s.start_time DESC
+		LIMIT 1`
+	TaskState     = `COALESCE((` + taskSessionState + taskLastSession + `), 'idle')`
+	UserTaskState = `COALESCE((` + taskSessionState + ` AND s.user_id = ?` + taskLastSession + `), 'idle')`
+
+	// TaskRemaining - остаток оценки задачи за вычетом времени всех исполнителей,
+	// не зависит от того, как в запросе отобраны сессии te
+	TaskRemaining = `t.estimate_seconds - (
+		SELECT ` + TrackedSeconds + `
+		FROM time_entries te
+		WHERE te.task_id = t.id
+	)`
+
+	// TaskTagsFilter - задача имеет все теги из списка, имена тегов сравниваются в нижнем регистре.
+	// Плейсхолдеры squirrel: список тегов и его длина
+	TaskTagsFilter = `(
+		SELECT COUNT(*)
+		FROM task_tags ft
+		JOIN tags fg ON fg.id = ft.tag_id
+		WHERE ft.task_id = t.id AND LOWER(fg.name) = ANY(?)
+	) = ?`
+
+	// Автор задачи сразу становится её исполнителем. Подзадача без проекта получает проект родителя
 	CreateTask = `
-		INSERT INTO tasks (name, user_id,start_time,end_time)
-        VALUES ($1, $2, NULL, NULL)
-        RETURNING id, name, user_id;
+		WITH task AS (
+		    INSERT INTO tasks (name, user_id, project_id, parent_id, estimate_seconds)
+		    VALUES ($1, $2, COALESCE($3, (SELECT project_id FROM tasks WHERE id = $4)), $4, $5)
+		    RETURNING id, name, user_id, project_id, status, parent_id, estimate_seconds
+		), assignee AS (
+		    INSERT INTO task_assignees (task_id, user_id)
+		    SELECT id, user_id FROM task
+		)
+		SELECT id, name, user_id, project_id, status, parent_id, estimate_seconds, estimate_seconds
+		FROM task;
 	`
 
-	FindTaskByID = `
-		SELECT id, name, user_id, start_time, end_time
+	SetTaskProject = `
+		UPDATE tasks
+		SET project_id = $2
+		WHERE id = $1;
+	`
+
+	SetTaskEstimate = `
+		UPDATE tasks
+		SET estimate_seconds = $2
+		WHERE id = $1;
+	`
+
+	// Задачи с оценкой, на которые потратили не меньше $1 процентов оценки, сначала самые перерасходованные
+	FindOverBudgetTasks = `
+		WITH tracked AS (
+		    SELECT t.id, t.name, t.user_id, t.status, t.estimate_seconds,
+		           ` + TrackedSeconds + ` AS seconds
+		    FROM tasks t
+		    LEFT JOIN time_entries te ON te.task_id = t.id
+		    WHERE t.estimate_seconds IS NOT NULL
+		    GROUP BY t.id
+		)
+		SELECT tr.id, tr.name, tr.user_id, ` + TaskUserName + `, tr.status,
+		       tr.estimate_seconds, tr.seconds, tr.estimate_seconds - tr.seconds,
+		       ROUND(tr.seconds * 100.0 / tr.estimate_seconds, 1)::FLOAT8
+		FROM tracked tr
+		JOIN users u ON u.id = tr.user_id
+		WHERE tr.seconds * 100 >= tr.estimate_seconds * $1
+		ORDER BY tr.seconds::FLOAT8 / tr.estimate_seconds DESC, tr.id;
+	`
+
+	SetTaskParent = `
+		UPDATE tasks
+		SET parent_id = $2
+		WHERE id = $1;
+	`
+
+	// Переносы задач в дереве идут по очереди, иначе два встречных переноса могут замкнуть цикл
+	LockTaskHierarchy = `
+		SELECT pg_advisory_xact_lock(hashtext('tasks_hierarchy'));
+	`
+
+	// Есть ли задача $2 среди предков задачи $1 или это она сама
+	TaskHasAncestor = `
+		WITH RECURSIVE ancestors AS (
+		    SELECT id, parent_id
+		    FROM tasks
+		    WHERE id = $1
+		    UNION ALL
+		    SELECT t.id, t.parent_id
+		    FROM tasks t
+		    JOIN ancestors a ON t.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2);
+	`
+
+	// Задача $1 со всеми потомками: своё время каждой задачи и время вместе с её потомками.
+	// paths связывает каждую задачу дерева со всеми её потомками, включая её саму
+	FindTaskTree = `
+		WITH RECURSIVE tree AS (
+		    SELECT id, parent_id, 0 AS depth
+		    FROM tasks
+		    WHERE id = $1
+		    UNION ALL
+		    SELECT t.id, t.parent_id, tree.depth + 1
+		    FROM tasks t
+		    JOIN tree ON t.parent_id = tree.id
+		), own AS (
+		    SELECT tree.id, ` + TrackedSeconds + ` AS seconds
+		    FROM tree
+		    LEFT JOIN time_entries te ON te.task_id = tree.id
+		    GROUP BY tree.id
+		), paths AS (
+		    SELECT id AS ancestor_id, id AS task_id
+		    FROM tree
+		    UNION ALL
+		    SELECT paths.ancestor_id, tree.id
+		    FROM paths
+		    JOIN tree ON tree.parent_id = paths.task_id
+		)
+		SELECT tree.id, t.name, tree.parent_id, t.status, own.seconds,
+		       (SELECT SUM(o.seconds) FROM paths p JOIN own o ON o.id = p.task_id WHERE p.ancestor_id = tree.id)::BIGINT
+		FROM tree
+		JOIN tasks t ON t.id = tree.id
+		JOIN own ON own.id = tree.id
+		ORDER BY tree.depth, tree.id;
+	`
+
+	LockTaskStatus = `
+		SELECT status
 		FROM tasks
+		WHERE id = $1
+		FOR UPDATE;
+	`
+
+	SetTaskStatus = `
+		UPDATE tasks
+		SET status = $2
 		WHERE id = $1;
 	`
 
+	// Закрывает идущую сессию задачи, которую больше нельзя трекать
+	StopTaskTimers = `
+		UPDATE time_entries
+		SET end_time = GREATEST($1, start_time), closed_by = 'stop'
+		WHERE task_id = $2 AND end_time IS NULL;
+	`
+
+	// Первый запуск таймера переводит задачу в работу
+	MarkTaskInProgress = `
+		UPDATE tasks
+		SET status = 'in_progress'
+		WHERE id = $1 AND status = 'todo';
+	`
+
+	// Состояние таймера и время - суммарные по всем исполнителям задачи
+	FindTaskByID = `
+		SELECT t.id, t.name, t.user_id, ` + TaskUserName + `,
+		       ` + TaskStartTime + `,
+		       ` + TaskEndTime + `,
+		       ` + TaskState + `,
+		       ` + TrackedSeconds + `,
+		       t.project_id, COALESCE(p.name, ''),
+		       ` + TaskTags + `,
+		       t.status, t.parent_id, t.estimate_seconds,
+		       t.estimate_seconds - ` + TrackedSeconds + `
+		FROM tasks t
+		JOIN users u ON u.id = t.user_id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		LEFT JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1
+		GROUP BY t.id, u.id, p.id;
+	`
+
+	FindTaskByName = `
+		SELECT t.id, t.name, t.user_id
+		FROM tasks t
+		JOIN task_assignees ta ON ta.task_id = t.id
+		WHERE ta.user_id = $1 AND LOWER(t.name) = LOWER($2)
+		ORDER BY t.id
+		LIMIT 1;
+	`
+
 	DeleteTask = `
 		DELETE FROM tasks
 		WHERE id = $1;
 	`
 
+	LockUserTaskStatus = `
+		SELECT t.status
+		FROM tasks t
+		JOIN task_assignees ta ON ta.task_id = t.id
+		WHERE t.id = $1 AND ta.user_id = $2
+		FOR UPDATE OF t;
+	`
+
+	LockTaskOwner = `
+		SELECT user_id
+		FROM tasks
+		WHERE id = $1
+		FOR UPDATE;
+	`
+
+	AssignTask = `
+		INSERT INTO task_assignees (task_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING;
+	`
+
+	UnassignTask = `
+		DELETE FROM task_assignees
+		WHERE task_id = $1 AND user_id = $2;
+	`
+
+	StopAssigneeTimer = `
+		UPDATE time_entries
+		SET end_time = GREATEST($1, start_time), closed_by = 'stop'
+		WHERE task_id = $2 AND user_id = $3 AND end_time IS NULL;
+	`
+
+	// Исполнители задачи с временем, которое каждый из них отследил по ней
+	FindTaskAssignees = `
+		SELECT u.id, ` + TaskUserName + `,
+		       ` + TrackedSeconds + `
+		FROM task_assignees ta
+		JOIN users u ON u.id = ta.user_id
+		LEFT JOIN time_entries te ON te.task_id = ta.task_id AND te.user_id = ta.user_id
+		WHERE ta.task_id = $1
+		GROUP BY u.id
+		ORDER BY u.id;
+	`
+
+	LockUser = `
+		SELECT id
+		FROM users
+		WHERE id = $1
+		FOR UPDATE;
+	`
+
+	FindOtherRunningTimers = `
+		SELECT task_id
+		FROM time_entries
+		WHERE user_id = $1 AND task_id <> $2 AND end_time IS NULL
+		ORDER BY task_id;
+	`
+
+	StopOtherRunningTimers = `
+		UPDATE time_entries
+		SET end_time = GREATEST($1, start_time), closed_by = 'stop'
+		WHERE user_id = $2 AND task_id <> $3 AND end_time IS NULL
+		RETURNING task_id;
+	`
+
+	GetTimerState = `
+		SELECT CASE WHEN end_time IS NULL THEN 'running' WHEN closed_by = 'pause' THEN 'paused' ELSE 'stopped' END
+		FROM time_entries
+		WHERE task_id = $1 AND user_id = $2 AND source = 'tracker'
+		ORDER BY (end_time IS NULL) DESC, start_time DESC
+		LIMIT 1;
+	`
+
 	StartTimeTracker = `
-		UPDATE tasks
-		SET start_time = $1
-		WHERE id = $2 AND user_id = $3;
+		INSERT INTO time_entries (task_id, user_id, start_time)
+		VALUES ($2, $3, $1);
 	`
 
+	PauseTimeTracker = `
+		UPDATE time_entries
+		SET end_time = GREATEST($1, start_time), closed_by = 'pause'
+		WHERE task_id = $2 AND user_id = $3 AND end_time IS NULL;
+	`
+
+	// Закрывает идущую сессию, а если таймер на паузе - помечает остановленной последнюю
 	StopTimeTracker = `
-		UPDATE tasks
-		SET end_time = $1
-		WHERE id = $2 AND user_id = $3;
+		UPDATE time_entries
+		SET end_time = COALESCE(end_time, GREATEST($1, start_time)), closed_by = 'stop'
+		WHERE id = (
+		    SELECT id
+		    FROM time_entries
+		    WHERE task_id = $2 AND user_id = $3 AND source = 'tracker'
+		    ORDER BY (end_time IS NULL) DESC, start_time DESC
+		    LIMIT 1
+		);
 	`
 
+	// $1 - теги в нижнем регистре, задача должна иметь их все. $2 - статусы задачи. NULL - без отбора.
+	// Состояние таймера и время - суммарные по всем исполнителям задачи
 	GetAllTasks = `
-		SELECT id, name, user_id, start_time, end_time
-		FROM tasks
-		ORDER BY user_id DESC;
+		SELECT t.id, t.name, t.user_id, ` + TaskUserName + `,
+		       ` + TaskStartTime + `,
+		       ` + TaskEndTime + `,
+		       ` + TaskState + `,
+		       ` + TrackedSeconds + `,
+		       t.project_id, COALESCE(p.name, ''),
+		       ` + TaskTags + `,
+		       t.status, t.parent_id, t.estimate_seconds,
+		       t.estimate_seconds - ` + TrackedSeconds + `
+		FROM tasks t
+		JOIN users u ON u.id = t.user_id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		LEFT JOIN projects p ON p.id = t.project_id
+		WHERE COALESCE(CARDINALITY($1::TEXT[]), 0) = (
+		    SELECT COUNT(*)
+		    FROM task_tags ft
+		    JOIN tags fg ON fg.id = ft.tag_id
+		    WHERE ft.task_id = t.id AND LOWER(fg.name) = ANY($1::TEXT[])
+		)
+		  AND ($2::TEXT[] IS NULL OR t.status = ANY($2::TEXT[]))
+		GROUP BY t.id, u.id, p.id
+		ORDER BY t.user_id DESC;
+	`
+
+	//----------------------------------------------
+
+	// TIME ENTRIES QUERIES--------------------------
+
+	FindEntriesByTaskID = `
+		SELECT id, task_id, user_id, start_time, end_time, COALESCE(closed_by, ''), source, COALESCE(reason, ''),
+		       EXTRACT(EPOCH FROM COALESCE(end_time, NOW()) - start_time)::BIGINT
+		FROM time_entries
+		WHERE task_id = $1
+		ORDER BY start_time;
+	`
+
+	LockUserEntry = `
+		SELECT task_id, start_time, end_time
+		FROM time_entries
+		WHERE id = $1 AND user_id = $2
+		FOR UPDATE;
+	`
+
+	// Пересечение с любыми сессиями юзера, идущая сессия считается до текущего момента
+	EntryOverlapCheck = `
+		SELECT EXISTS(
+		SELECT 1
+		FROM time_entries
+		WHERE user_id = $1 AND id <> $2 AND start_time < $4 AND COALESCE(end_time, NOW()) > $3)
+	`
+
+	CreateEntry = `
+		INSERT INTO time_entries (task_id, user_id, start_time, end_time, closed_by, source, reason)
+		VALUES ($1, $2, $3, $4, 'stop', 'manual', $5)
+		RETURNING id;
+	`
+
+	CreateImportedEntry = `
+		INSERT INTO time_entries (task_id, user_id, start_time, end_time, closed_by, source, reason)
+		VALUES ($1, $2, $3, $4, 'stop', 'import', $5)
+		RETURNING id;
+	`
+
+	UpdateEntry = `
+		UPDATE time_entries
+		SET start_time = $2, end_time = $3, reason = $4
+		WHERE id = $1
+		RETURNING task_id, COALESCE(closed_by, ''), source;
+	`
+
+	DeleteEntry = `
+		DELETE FROM time_entries
+		WHERE id = $1;
+	`
+
+	CreateEntryAudit = `
+		INSERT INTO time_entry_audit (entry_id, task_id, user_id, action, old_start_time, old_end_time, new_start_time, new_end_time, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+
+	// Закрывает идущие сессии по ближайшему из ограничений: $1 - максимальная длительность в секундах,
+	// $2 - время конца рабочего дня в часовом поясе юзера. NULL отключает ограничение
+	AutoStopTimers = `
+		WITH deadlines AS (
+		    SELECT te.id,
+		           LEAST(
+		               te.start_time + $1::BIGINT * INTERVAL '1 second',
+		               (loc.start_time::DATE + $2::TIME
+		                   + CASE WHEN loc.start_time::TIME >= $2::TIME THEN INTERVAL '1 day' ELSE INTERVAL '0' END)
+		                   AT TIME ZONE u.timezone
+		           ) AS deadline
+		    FROM time_entries te
+		    JOIN users u ON u.id = te.user_id
+		    CROSS JOIN LATERAL (
+		        SELECT te.start_time AT TIME ZONE u.timezone AS start_time
+		    ) loc
+		    WHERE te.end_time IS NULL
+		)
+		UPDATE time_entries te
+		SET end_time = d.deadline, closed_by = 'auto'
+		FROM deadlines d
+		WHERE te.id = d.id AND te.end_time IS NULL AND d.deadline <= $3
+		RETURNING te.id, te.task_id, te.user_id, te.start_time, te.end_time;
+	`
+
+	//----------------------------------------------
+
+	// REPORTS QUERIES------------------------------
+
+	// userPeriodEntries - общий для отчётов и календаря отбор сессий: сессии юзера $1, которые пересекаются
+	// с периодом [$2, $3), незавершённые считаются до текущего момента. Время задачи потом обрезается по периоду
+	userPeriodEntries = `te.user_id = $1
+		  AND te.start_time < $3
+		  AND COALESCE(te.end_time, NOW()) > $2`
+
+	// periodEntryStart и periodEntryEnd - границы сессии, обрезанные по периоду [$2, $3).
+	// Отчёты считают время только между ними, а корзины и дни обрезают их дальше
+	periodEntryStart = `GREATEST(te.start_time, $2)`
+	periodEntryEnd   = `LEAST(COALESCE(te.end_time, NOW()), $3)`
+
+	// Сессии обрезаются по границам периода, незавершённые считаются до текущего момента.
+	// $4 и $5 - отбор по проекту и клиенту, 0 - без отбора
+	GetWorkload = `
+		SELECT t.id, t.name, t.project_id, COALESCE(p.name, ''), p.client_id, COALESCE(c.name, ''),
+		       SUM(EXTRACT(EPOCH FROM ` + periodEntryEnd + ` - ` + periodEntryStart + `))::BIGINT AS total_seconds,
+		       COUNT(*) FILTER (WHERE te.closed_by = 'auto')
+		FROM time_entries te
+		JOIN tasks t ON t.id = te.task_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE ` + userPeriodEntries + `
+		  AND ($4::INT = 0 OR t.project_id = $4)
+		  AND ($5::INT = 0 OR p.client_id = $5)
+		GROUP BY t.id, p.id, c.id
+		ORDER BY total_seconds DESC, t.id;
+	`
+
+	// Время задачи засчитывается каждому её тегу, у задач без тегов тег пустой. $4 и $5 - отбор по проекту и клиенту
+	GetTagWorkload = `
+		SELECT tg.id, COALESCE(tg.name, ''),
+		       SUM(EXTRACT(EPOCH FROM ` + periodEntryEnd + ` - ` + periodEntryStart + `))::BIGINT AS total_seconds,
+		       COUNT(*) FILTER (WHERE te.closed_by = 'auto')
+		FROM time_entries te
+		JOIN tasks t ON t.id = te.task_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		LEFT JOIN task_tags tt ON tt.task_id = t.id
+		LEFT JOIN tags tg ON tg.id = tt.tag_id
+		WHERE ` + userPeriodEntries + `
+		  AND ($4::INT = 0 OR t.project_id = $4)
+		  AND ($5::INT = 0 OR p.client_id = $5)
+		GROUP BY tg.id
+		ORDER BY total_seconds DESC, tg.id NULLS LAST;
+	`
+
+	// Границы дней и недель ($4) считаются в часовом поясе $5, сессии обрезаются по границам корзины и периода.
+	// Строка на каждую задачу в корзине, у пустой корзины одна строка без задачи. $6 и $7 - отбор по проекту и клиенту
+	GetSummary = `
+		SELECT b.bucket_start, b.bucket_end,
+		       COALESCE(t.id, 0), COALESCE(t.name, ''), t.project_id, COALESCE(p.name, ''), p.client_id, COALESCE(c.name, ''),
+		       COALESCE(SUM(EXTRACT(EPOCH FROM
+		           LEAST(` + periodEntryEnd + `, b.bucket_end) - GREATEST(` + periodEntryStart + `, b.bucket_start)
+		       )), 0)::BIGINT
+		FROM (
+		    SELECT g AT TIME ZONE $5::TEXT AS bucket_start,
+		           (g + ('1 ' || $4::TEXT)::INTERVAL) AT TIME ZONE $5::TEXT AS bucket_end
+		    FROM generate_series(
+		        date_trunc($4::TEXT, $2::TIMESTAMPTZ AT TIME ZONE $5::TEXT),
+		        $3::TIMESTAMPTZ AT TIME ZONE $5::TEXT - INTERVAL '1 microsecond',
+		        ('1 ' || $4::TEXT)::INTERVAL
+		    ) g
+		) b
+		LEFT JOIN (time_entries te
+		           JOIN tasks t ON t.id = te.task_id
+		           LEFT JOIN projects p ON p.id = t.project_id
+		           LEFT JOIN clients c ON c.id = p.client_id)
+		       ON ` + userPeriodEntries + `
+		      AND te.start_time < b.bucket_end
+		      AND COALESCE(te.end_time, NOW()) > b.bucket_start
+		      AND ($6::INT = 0 OR t.project_id = $6)
+		      AND ($7::INT = 0 OR p.client_id = $7)
+		GROUP BY b.bucket_start, b.bucket_end, t.id, p.id, c.id
+		ORDER BY b.bucket_start, t.id;
+	`
+
+	// Сессии, которые переходят через полночь в поясе $4, делятся между днями и обрезаются по границам периода:
+	// последний день может быть неполным. $5 и $6 - отбор по проекту и клиенту
+	GetTimesheet = `
+		SELECT t.id, t.name, t.project_id, COALESCE(p.name, ''), p.client_id, COALESCE(c.name, ''), d.day_start,
+		       SUM(EXTRACT(EPOCH FROM
+		           LEAST(` + periodEntryEnd + `, d.day_end) - GREATEST(` + periodEntryStart + `, d.day_start)
+		       ))::BIGINT
+		FROM (
+		    SELECT g AT TIME ZONE $4::TEXT AS day_start,
+		           (g + INTERVAL '1 day') AT TIME ZONE $4::TEXT AS day_end
+		    FROM generate_series(
+		        $2::TIMESTAMPTZ AT TIME ZONE $4::TEXT,
+		        $3::TIMESTAMPTZ AT TIME ZONE $4::TEXT - INTERVAL '1 microsecond',
+		        INTERVAL '1 day'
+		    ) g
+		) d
+		JOIN time_entries te
+		  ON ` + userPeriodEntries + `
+		 AND te.start_time < d.day_end
+		 AND COALESCE(te.end_time, NOW()) > d.day_start
+		JOIN tasks t ON t.id = te.task_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		LEFT JOIN clients c ON c.id = p.client_id
+		WHERE ($5::INT = 0 OR t.project_id = $5)
+		  AND ($6::INT = 0 OR p.client_id = $6)
+		GROUP BY t.id, p.id, c.id, d.day_start
+		ORDER BY t.id, d.day_start;
+	`
+
+	//----------------------------------------------
+
+	// CALENDAR QUERIES-----------------------------
+
+	SaveCalendarToken = `
+		INSERT INTO calendar_tokens (user_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW();
+	`
+
+	DeleteCalendarToken = `
+		DELETE FROM calendar_tokens
+		WHERE user_id = $1;
+	`
+
+	FindCalendarTokenHash = `
+		SELECT token_hash
+		FROM calendar_tokens
+		WHERE user_id = $1;
+	`
+
+	FindCalendarEvents = `
+		SELECT te.id, te.task_id, t.name, te.source, te.start_time, te.end_time
+		FROM time_entries te
+		JOIN tasks t ON t.id = te.task_id
+		WHERE ` + userPeriodEntries + `
+		ORDER BY te.start_time, te.id;
+	`
+
+	//----------------------------------------------
+
+	// PROJECTS QUERIES-----------------------------
+
+	CreateClient = `
+		INSERT INTO clients (name)
+		VALUES ($1)
+		RETURNING id, name;
+	`
+
+	// Время клиента - сумма по всем сессиям задач его проектов, идущие сессии считаются до текущего момента
+	FindClients = `
+		SELECT c.id, c.name,
+		       ` + TrackedSeconds + `
+		FROM clients c
+		LEFT JOIN projects p ON p.client_id = c.id
+		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		GROUP BY c.id
+		ORDER BY c.name, c.id;
+	`
+
+	FindClientByID = `
+		SELECT c.id, c.name,
+		       ` + TrackedSeconds + `
+		FROM clients c
+		LEFT JOIN projects p ON p.client_id = c.id
+		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		WHERE c.id = $1
+		GROUP BY c.id;
+	`
+
+	UpdateClient = `
+		WITH updated AS (
+		    UPDATE clients
+		    SET name = $2
+		    WHERE id = $1
+		    RETURNING id, name
+		)
+		SELECT u.id, u.name,
+		       ` + TrackedSeconds + `
+		FROM updated u
+		LEFT JOIN projects p ON p.client_id = u.id
+		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		GROUP BY u.id, u.name;
+	`
+
+	DeleteClient = `
+		DELETE FROM clients
+		WHERE id = $1;
+	`
+
+	CreateProject = `
+		WITH created AS (
+		    INSERT INTO projects (name, client_id)
+		    VALUES ($1, $2)
+		    RETURNING id, name, client_id
+		)
+		SELECT cr.id, cr.name, cr.client_id, COALESCE(c.name, ''), 0::BIGINT
+		FROM created cr
+		LEFT JOIN clients c ON c.id = cr.client_id;
+	`
+
+	// $1 - клиент, 0 - все проекты
+	FindProjects = `
+		SELECT p.id, p.name, p.client_id, COALESCE(c.name, ''),
+		       ` + TrackedSeconds + `
+		FROM projects p
+		LEFT JOIN clients c ON c.id = p.client_id
+		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		WHERE $1::INT = 0 OR p.client_id = $1
+		GROUP BY p.id, c.id
+		ORDER BY p.name, p.id;
+	`
+
+	FindProjectByID = `
+		SELECT p.id, p.name, p.client_id, COALESCE(c.name, ''),
+		       ` + TrackedSeconds + `
+		FROM projects p
+		LEFT JOIN clients c ON c.id = p.client_id
+		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		WHERE p.id = $1
+		GROUP BY p.id, c.id;
+	`
+
+	UpdateProject = `
+		WITH updated AS (
+		    UPDATE projects
+		    SET name = $2, client_id = $3
+		    WHERE id = $1
+		    RETURNING id, name, client_id
+		)
+		SELECT u.id, u.name, u.client_id, COALESCE(c.name, ''),
+		       ` + TrackedSeconds + `
+		FROM updated u
+		LEFT JOIN clients c ON c.id = u.client_id
+		LEFT JOIN tasks t ON t.project_id = u.id
+		LEFT JOIN time_entries te ON te.task_id = t.id
+		GROUP BY u.id, u.name, u.client_id, c.id;
+	`
+
+	DeleteProject = `
+		DELETE FROM projects
+		WHERE id = $1;
+	`
+
+	//----------------------------------------------
+	// TAGS QUERIES---------------------------------
+
+	CreateTag = `
+		INSERT INTO tags (name)
+		VALUES ($1)
+		RETURNING id, name, 0::BIGINT;
+	`
+
+	// Время тега - сумма по всем сессиям задач с этим тегом, идущие сессии считаются до текущего момента
+	FindTags = `
+		SELECT tg.id, tg.name,
+		       ` + TrackedSeconds + `
+		FROM tags tg
+		LEFT JOIN task_tags tt ON tt.tag_id = tg.id
+		LEFT JOIN time_entries te ON te.task_id = tt.task_id
+		GROUP BY tg.id
+		ORDER BY tg.name, tg.id;
+	`
+
+	DeleteTag = `
+		DELETE FROM tags
+		WHERE id = $1;
+	`
+
+	AttachTag = `
+		INSERT INTO task_tags (task_id, tag_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING;
+	`
+
+	DetachTag = `
+		DELETE FROM task_tags
+		WHERE task_id = $1 AND tag_id = $2;
+	`
+
+	//----------------------------------------------
+	// ENRICHMENT QUERIES---------------------------
+
+	// Забирает до $1 готовых задач, пропуская занятые другими воркерами, и сдвигает их run_at
+	// на $2 секунд аренды. Попытка засчитывается сразу, чтобы задача упавшего воркера не крутилась вечно
+	ClaimEnrichmentJobs = `
+		UPDATE enrichment_jobs j
+		SET run_at = NOW() + make_interval(secs => $2), attempts = j.attempts + 1
+		FROM users u
+		WHERE u.id = j.user_id AND j.id IN (
+		    SELECT id
+		    FROM enrichment_jobs
+		    WHERE run_at <= NOW()
+		    ORDER BY run_at, id
+		    LIMIT $1
+		    FOR UPDATE SKIP LOCKED
+		)
+		RETURNING j.id, j.user_id, u.passport_number, j.attempts;
+	`
+
+	// Заполняются только пустые поля: то, что успели записать через PATCH, пока юзер ждал воркера, не затирается
+	CompleteEnrichmentJob = `
+		WITH job AS (
+		    DELETE FROM enrichment_jobs
+		    WHERE id = $1
+		    RETURNING user_id
+		)
+		UPDATE users
+		SET surname = COALESCE(NULLIF(surname, ''), $2),
+		    name = COALESCE(NULLIF(name, ''), $3),
+		    patronymic = COALESCE(NULLIF(patronymic, ''), $4),
+		    address = COALESCE(NULLIF(address, ''), $5),
+		    enrichment_status = 'done',
+		    enrichment_error = NULL
+		WHERE id = (SELECT user_id FROM job);
+	`
+
+	RetryEnrichmentJob = `
+		UPDATE enrichment_jobs
+		SET run_at = $2, last_error = $3
+		WHERE id = $1;
+	`
+
+	FailEnrichmentJob = `
+		WITH job AS (
+		    DELETE FROM enrichment_jobs
+		    WHERE id = $1
+		    RETURNING user_id
+		)
+		UPDATE users
+		SET enrichment_status = 'failed', enrichment_error = $2
+		WHERE id = (SELECT user_id FROM job);
+	`
+
+	FindEnrichmentCache = `
+		SELECT surname, name, patronymic, address, not_found, expires_at
+		FROM enrichment_cache
+		WHERE passport_hash = $1 AND expires_at > NOW();
+	`
+
+	SaveEnrichmentCache = `
+		INSERT INTO enrichment_cache (passport_hash, surname, name, patronymic, address, not_found, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (passport_hash) DO UPDATE
+		SET surname = EXCLUDED.surname, name = EXCLUDED.name, patronymic = EXCLUDED.patronymic,
+		    address = EXCLUDED.address, not_found = EXCLUDED.not_found, expires_at = EXCLUDED.expires_at;
+	`
+
+	DeleteExpiredEnrichmentCache = `
+		DELETE FROM enrichment_cache
+		WHERE expires_at <= NOW();
 	`
 
 	//----------------------------------------------
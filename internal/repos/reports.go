@@ -0,0 +1,215 @@
+package repos
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type ReportsRepository struct {
+	db *sql.DB
+}
+
+func NewReportsRepository(db *sql.DB) *ReportsRepository {
+	return &ReportsRepository{db: db}
+}
+
+func (rr *ReportsRepository) GetWorkload(ctx context.Context, filter models.ReportFilter) ([]models.TaskWorkload, error) {
+	rows, err := rr.db.QueryContext(
+		ctx,
+		queries.GetWorkload,
+		filter.UserID,
+		filter.From,
+		filter.To,
+		filter.ProjectID,
+		filter.ClientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var workload []models.TaskWorkload
+
+	for rows.Next() {
+		var item models.TaskWorkload
+
+		err = rows.Scan(
+			&item.TaskID,
+			&item.Name,
+			&item.ProjectID,
+			&item.ProjectName,
+			&item.ClientID,
+			&item.ClientName,
+			&item.TotalSeconds,
+			&item.AutoStoppedEntries,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		workload = append(workload, item)
+	}
+
+	return workload, nil
+}
+
+// GetTagWorkload - трудозатраты по тегам, время задачи с несколькими тегами попадает в каждый из них
+func (rr *ReportsRepository) GetTagWorkload(ctx context.Context, filter models.ReportFilter) ([]models.TaskWorkload, error) {
+	rows, err := rr.db.QueryContext(
+		ctx,
+		queries.GetTagWorkload,
+		filter.UserID,
+		filter.From,
+		filter.To,
+		filter.ProjectID,
+		filter.ClientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var workload []models.TaskWorkload
+
+	for rows.Next() {
+		var item models.TaskWorkload
+
+		err = rows.Scan(&item.TagID, &item.Name, &item.TotalSeconds, &item.AutoStoppedEntries)
+		if err != nil {
+			return nil, err
+		}
+
+		workload = append(workload, item)
+	}
+
+	return workload, nil
+}
+
+func (rr *ReportsRepository) GetSummary(ctx context.Context, filter models.ReportFilter) ([]models.SummaryCell, error) {
+	rows, err := rr.db.QueryContext(
+		ctx,
+		queries.GetSummary,
+		filter.UserID,
+		filter.From,
+		filter.To,
+		string(filter.Bucket),
+		filter.Location.String(),
+		filter.ProjectID,
+		filter.ClientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var cells []models.SummaryCell
+
+	for rows.Next() {
+		var cell models.SummaryCell
+
+		err = rows.Scan(
+			&cell.Start,
+			&cell.End,
+			&cell.TaskID,
+			&cell.Name,
+			&cell.ProjectID,
+			&cell.ProjectName,
+			&cell.ClientID,
+			&cell.ClientName,
+			&cell.TotalSeconds,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		cells = append(cells, cell)
+	}
+
+	return cells, nil
+}
+
+func (rr *ReportsRepository) FindUserTimezone(ctx context.Context, usrID int) (string, error) {
+	var timezone string
+
+	err := rr.db.QueryRowContext(ctx, queries.FindUserTimezone, usrID).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrUsrNotExists
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return timezone, nil
+}
+
+func (rr *ReportsRepository) FindUserByID(ctx context.Context, usrID int) (models.User, error) {
+	var user models.User
+
+	err := rr.db.QueryRowContext(ctx, queries.FindUserByID, usrID).Scan(
+		&user.ID,
+		&user.PassportNumber,
+		&user.Surname,
+		&user.Name,
+		&user.Patronymic,
+		&user.Address,
+		&user.Timezone,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUsrNotExists
+	}
+
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (rr *ReportsRepository) GetTimesheet(ctx context.Context, filter models.ReportFilter) ([]models.TimesheetCell, error) {
+	rows, err := rr.db.QueryContext(
+		ctx,
+		queries.GetTimesheet,
+		filter.UserID,
+		filter.From,
+		filter.To,
+		filter.Location.String(),
+		filter.ProjectID,
+		filter.ClientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var cells []models.TimesheetCell
+
+	for rows.Next() {
+		var cell models.TimesheetCell
+
+		err = rows.Scan(
+			&cell.TaskID,
+			&cell.Name,
+			&cell.ProjectID,
+			&cell.ProjectName,
+			&cell.ClientID,
+			&cell.ClientName,
+			&cell.Day,
+			&cell.TotalSeconds,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		cells = append(cells, cell)
+	}
+
+	return cells, nil
+}
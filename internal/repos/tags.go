@@ -0,0 +1,117 @@
+package repos
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+)
+
+var ErrTagNotFound = errors.New("tag not found")
+var ErrTagExists = errors.New("tag with this name already exists")
+var ErrTagNotAttached = errors.New("tag is not attached to the task")
+
+// fkTaskTagsTask - ограничение task_tags на задачу, по нему отличаем несуществующую задачу от тега
+const fkTaskTagsTask = "fk_task_tags_task"
+
+type TagsRepository struct {
+	db *sql.DB
+}
+
+func NewTagsRepository(db *sql.DB) *TagsRepository {
+	return &TagsRepository{db: db}
+}
+
+func (tr *TagsRepository) CreateTag(ctx context.Context, name string) (models.Tag, error) {
+	var tag models.Tag
+
+	err := tr.db.QueryRowContext(ctx, queries.CreateTag, name).Scan(&tag.ID, &tag.Name, &tag.TotalSeconds)
+	if isPQError(err, pqUniqueViolation) {
+		return models.Tag{}, ErrTagExists
+	}
+
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (tr *TagsRepository) FindTags(ctx context.Context) ([]models.Tag, error) {
+	rows, err := tr.db.QueryContext(ctx, queries.FindTags)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := []models.Tag{}
+
+	for rows.Next() {
+		var tag models.Tag
+
+		err = rows.Scan(&tag.ID, &tag.Name, &tag.TotalSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// DeleteTag - тег снимается со всех задач, время по задачам остаётся
+func (tr *TagsRepository) DeleteTag(ctx context.Context, id int) error {
+	result, err := tr.db.ExecContext(ctx, queries.DeleteTag, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
+
+// AttachTag - вешает тег на задачу, повторная привязка ничего не меняет
+func (tr *TagsRepository) AttachTag(ctx context.Context, taskID, tagID int) error {
+	_, err := tr.db.ExecContext(ctx, queries.AttachTag, taskID, tagID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code) == pqForeignKeyViolation {
+		if pqErr.Constraint == fkTaskTagsTask {
+			return ErrTaskNotFound
+		}
+
+		return ErrTagNotFound
+	}
+
+	return err
+}
+
+func (tr *TagsRepository) DetachTag(ctx context.Context, taskID, tagID int) error {
+	result, err := tr.db.ExecContext(ctx, queries.DetachTag, taskID, tagID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTagNotAttached
+	}
+
+	return nil
+}
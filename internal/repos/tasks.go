@@ -7,11 +7,26 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"slices"
 	"time"
 )
 
 var ErrTaskNotFound = errors.New("task not found")
 var ErrUsrNotExists = errors.New("user not exists")
+var ErrTimerConflict = errors.New("timer state does not allow this action")
+var ErrAnotherTimerRunning = errors.New("another timer of the user is running")
+var ErrTaskStatusConflict = errors.New("task status does not allow this action")
+var ErrTaskNotAssigned = errors.New("user is not assigned to the task")
+var ErrTaskOwnerAssignee = errors.New("task owner can't be unassigned")
+var ErrParentTaskNotFound = errors.New("parent task not found")
+var ErrTaskCycle = errors.New("task can't be moved under itself or its subtask")
+
+// fkTasksParent - ограничение tasks на родительскую задачу, по нему отличаем несуществующего родителя от проекта
+const fkTasksParent = "fk_tasks_parent"
+
+// fkTaskAssigneesTask - ограничение task_assignees на задачу, по нему отличаем несуществующую задачу от юзера
+const fkTaskAssigneesTask = "fk_task_assignees_task"
 
 type TasksRepository struct {
 	db *sql.DB
@@ -21,11 +36,19 @@ func NewTasksRepository(db *sql.DB) *TasksRepository {
 	return &TasksRepository{db: db}
 }
 
-func (tr *TasksRepository) AddTask(ctx context.Context, name string, usrID int) (models.Task, error) {
+// AddTask - создаёт задачу юзера, projectID nil - задача без проекта или с проектом родителя,
+// parentID nil - задача верхнего уровня, estimate nil - задача без оценки
+func (tr *TasksRepository) AddTask(
+	ctx context.Context,
+	name string,
+	usrID int,
+	projectID, parentID *int,
+	estimate *int64,
+) (models.Task, error) {
 	var task models.Task
 
 	var exists bool
-	err := tr.db.QueryRowContext(ctx, queries.ExistCheck, usrID).Scan(&exists)
+	err := conn(ctx, tr.db).QueryRowContext(ctx, queries.ExistCheck, usrID).Scan(&exists)
 
 	if err != nil {
 		return models.Task{}, err
@@ -35,11 +58,330 @@ func (tr *TasksRepository) AddTask(ctx context.Context, name string, usrID int)
 		return models.Task{}, ErrUsrNotExists
 	}
 
-	err = tr.db.QueryRowContext(ctx, queries.CreateTask, name, usrID).Scan(
+	err = conn(ctx, tr.db).QueryRowContext(ctx, queries.CreateTask, name, usrID, projectID, parentID, estimate).Scan(
 		&task.ID,
 		&task.Name,
 		&task.UserID,
+		&task.ProjectID,
+		&task.Status,
+		&task.ParentID,
+		&task.EstimateSeconds,
+		&task.RemainingSeconds,
 	)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code) == pqForeignKeyViolation {
+		if pqErr.Constraint == fkTasksParent {
+			return models.Task{}, ErrParentTaskNotFound
+		}
+
+		return models.Task{}, ErrProjectNotFound
+	}
+
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// SetTaskProject - привязывает задачу к проекту, projectID nil отвязывает
+func (tr *TasksRepository) SetTaskProject(ctx context.Context, id int, projectID *int) error {
+	result, err := tr.db.ExecContext(ctx, queries.SetTaskProject, id, projectID)
+	if isPQError(err, pqForeignKeyViolation) {
+		return ErrProjectNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// SetTaskStatus - переводит задачу в статус to, если текущий статус входит в from. При stopTimer
+// закрывает идущую сессию задачи в той же транзакции. Возвращает статус, в котором задача была до перехода
+func (tr *TasksRepository) SetTaskStatus(
+	ctx context.Context,
+	id int,
+	to models.TaskStatus,
+	from []models.TaskStatus,
+	stopTimer bool,
+) (models.TaskStatus, error) {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	var status models.TaskStatus
+
+	err = tx.QueryRowContext(ctx, queries.LockTaskStatus, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrTaskNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(from, status) {
+		return status, ErrTaskStatusConflict
+	}
+
+	_, err = tx.ExecContext(ctx, queries.SetTaskStatus, id, to)
+	if err != nil {
+		return status, err
+	}
+
+	if stopTimer {
+		_, err = tx.ExecContext(ctx, queries.StopTaskTimers, time.Now(), id)
+		if err != nil {
+			return status, err
+		}
+	}
+
+	return status, tx.Commit()
+}
+
+// SetTaskEstimate - задаёт оценку задачи в секундах, estimate nil убирает оценку
+func (tr *TasksRepository) SetTaskEstimate(ctx context.Context, id int, estimate *int64) error {
+	result, err := tr.db.ExecContext(ctx, queries.SetTaskEstimate, id, estimate)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// FindOverBudgetTasks - задачи, на которые потратили не меньше threshold процентов оценки
+func (tr *TasksRepository) FindOverBudgetTasks(ctx context.Context, threshold int) ([]models.TaskBudget, error) {
+	rows, err := tr.db.QueryContext(ctx, queries.FindOverBudgetTasks, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tasks []models.TaskBudget
+
+	for rows.Next() {
+		var task models.TaskBudget
+
+		err = rows.Scan(
+			&task.ID,
+			&task.Name,
+			&task.UserID,
+			&task.UserFullName,
+			&task.Status,
+			&task.EstimateSeconds,
+			&task.TotalSeconds,
+			&task.RemainingSeconds,
+			&task.UsedPercent,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// SetTaskParent - переносит задачу под родителя, parentID nil делает её задачей верхнего уровня.
+// Перенос под саму задачу или её потомка замкнул бы дерево, такой перенос отклоняется
+func (tr *TasksRepository) SetTaskParent(ctx context.Context, id int, parentID *int) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, queries.LockTaskHierarchy)
+	if err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		var cycle bool
+
+		err = tx.QueryRowContext(ctx, queries.TaskHasAncestor, *parentID, id).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+
+		if cycle {
+			return ErrTaskCycle
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, queries.SetTaskParent, id, parentID)
+	if isPQError(err, pqForeignKeyViolation) {
+		return ErrParentTaskNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	return tx.Commit()
+}
+
+// FindTaskTree - задача и все её потомки по уровням, сначала сама задача
+func (tr *TasksRepository) FindTaskTree(ctx context.Context, id int) ([]models.TaskTree, error) {
+	rows, err := tr.db.QueryContext(ctx, queries.FindTaskTree, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var nodes []models.TaskTree
+
+	for rows.Next() {
+		var node models.TaskTree
+
+		err = rows.Scan(&node.ID, &node.Name, &node.ParentID, &node.Status, &node.TotalSeconds, &node.RollupSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, rows.Err()
+}
+
+// AssignTask - добавляет юзера в исполнители задачи, повторное назначение ничего не меняет
+func (tr *TasksRepository) AssignTask(ctx context.Context, id, usrID int) error {
+	_, err := tr.db.ExecContext(ctx, queries.AssignTask, id, usrID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code) == pqForeignKeyViolation {
+		if pqErr.Constraint == fkTaskAssigneesTask {
+			return ErrTaskNotFound
+		}
+
+		return ErrUsrNotExists
+	}
+
+	return err
+}
+
+// UnassignTask - убирает юзера из исполнителей задачи и останавливает его таймер по ней.
+// Автора задачи убрать нельзя
+func (tr *TasksRepository) UnassignTask(ctx context.Context, id, usrID int) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var ownerID int
+
+	err = tx.QueryRowContext(ctx, queries.LockTaskOwner, id).Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTaskNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if ownerID == usrID {
+		return ErrTaskOwnerAssignee
+	}
+
+	result, err := tx.ExecContext(ctx, queries.UnassignTask, id, usrID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTaskNotAssigned
+	}
+
+	_, err = tx.ExecContext(ctx, queries.StopAssigneeTimer, time.Now(), id, usrID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (tr *TasksRepository) FindTaskAssignees(ctx context.Context, id int) ([]models.TaskAssignee, error) {
+	rows, err := tr.db.QueryContext(ctx, queries.FindTaskAssignees, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var assignees []models.TaskAssignee
+
+	for rows.Next() {
+		var assignee models.TaskAssignee
+
+		err = rows.Scan(&assignee.UserID, &assignee.UserFullName, &assignee.TotalSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		assignees = append(assignees, assignee)
+	}
+
+	return assignees, rows.Err()
+}
+
+// FindTaskByName - первая задача, где юзер исполнитель, с таким названием без учёта регистра
+func (tr *TasksRepository) FindTaskByName(ctx context.Context, usrID int, name string) (models.Task, error) {
+	var task models.Task
+
+	err := conn(ctx, tr.db).QueryRowContext(ctx, queries.FindTaskByName, usrID, name).Scan(
+		&task.ID,
+		&task.Name,
+		&task.UserID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, ErrTaskNotFound
+	}
+
 	if err != nil {
 		return models.Task{}, err
 	}
@@ -54,8 +396,18 @@ func (tr *TasksRepository) FindTaskByID(ctx context.Context, id int) (models.Tas
 		&task.ID,
 		&task.Name,
 		&task.UserID,
+		&task.UserFullName,
 		&task.StartTime,
 		&task.EndTime,
+		&task.State,
+		&task.TotalSeconds,
+		&task.ProjectID,
+		&task.ProjectName,
+		pq.Array(&task.Tags),
+		&task.Status,
+		&task.ParentID,
+		&task.EstimateSeconds,
+		&task.RemainingSeconds,
 	)
 	if err != nil {
 		return models.Task{}, err
@@ -64,21 +416,61 @@ func (tr *TasksRepository) FindTaskByID(ctx context.Context, id int) (models.Tas
 	return task, nil
 }
 
-func (tr *TasksRepository) FindTasksByUserID(ctx context.Context, usrID int, startTime, endTime string) ([]models.Task, error) {
-	query := squirrel.Select("id", "name", "user_id", "start_time", "end_time").
-		From("tasks").
-		Where(squirrel.Eq{"user_id": usrID})
+// FindTasksByUserID - задачи, где юзер исполнитель, с отбором по тегам и статусам из filter
+// Состояние таймера, начало, конец и отслеженное время считаются только по сессиям юзера,
+// остаток оценки - по всем исполнителям.
+// Период отбирает задачи целиком: первая сессия не раньше startTime, последняя закончена не позже endTime.
+// Отчёты и табель, наоборот, берут сессии, пересекающие период, и обрезают их по его границам
+func (tr *TasksRepository) FindTasksByUserID(
+	ctx context.Context,
+	usrID int,
+	startTime, endTime string,
+	filter models.TaskFilter,
+) ([]models.Task, error) {
+	query := squirrel.Select(
+		"t.id",
+		"t.name",
+		"t.user_id",
+		queries.TaskUserName,
+		queries.TaskStartTime,
+		queries.TaskEndTime,
+	).
+		Column(squirrel.Expr(queries.UserTaskState, usrID)).
+		Columns(
+			queries.TrackedSeconds,
+			"t.project_id",
+			"COALESCE(p.name, '')",
+			queries.TaskTags,
+			"t.status",
+			"t.parent_id",
+			"t.estimate_seconds",
+			queries.TaskRemaining,
+		).
+		From("tasks t").
+		Join("users u ON u.id = t.user_id").
+		LeftJoin("time_entries te ON te.task_id = t.id AND te.user_id = ?", usrID).
+		LeftJoin("projects p ON p.id = t.project_id").
+		Where("t.id IN (SELECT ta.task_id FROM task_assignees ta WHERE ta.user_id = ?)", usrID).
+		GroupBy("t.id", "u.id", "p.id")
+
+	if len(filter.Tags) > 0 {
+		query = query.Where(queries.TaskTagsFilter, pq.Array(filter.Tags), len(filter.Tags))
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where(squirrel.Eq{"t.status": filter.Statuses})
+	}
 
 	if startTime != "" {
-		query = query.Where(squirrel.GtOrEq{"start_time": startTime})
+		query = query.Having(queries.TaskStartTime+" >= ?", startTime)
 	}
 
 	if endTime != "" {
-		query = query.Where(squirrel.LtOrEq{"end_time": endTime})
+		query = query.Having(queries.TaskEndTime+" <= ?", endTime)
 	}
 
 	if startTime != "" && endTime != "" {
-		query = query.OrderBy("AGE(end_time, start_time) DESC")
+		query = query.OrderBy(queries.TrackedSeconds + " DESC")
 	}
 
 	query = query.PlaceholderFormat(squirrel.Dollar)
@@ -98,7 +490,23 @@ func (tr *TasksRepository) FindTasksByUserID(ctx context.Context, usrID int, sta
 
 	for rows.Next() {
 		var task models.Task
-		err = rows.Scan(&task.ID, &task.Name, &task.UserID, &task.StartTime, &task.EndTime)
+		err = rows.Scan(
+			&task.ID,
+			&task.Name,
+			&task.UserID,
+			&task.UserFullName,
+			&task.StartTime,
+			&task.EndTime,
+			&task.State,
+			&task.TotalSeconds,
+			&task.ProjectID,
+			&task.ProjectName,
+			pq.Array(&task.Tags),
+			&task.Status,
+			&task.ParentID,
+			&task.EstimateSeconds,
+			&task.RemainingSeconds,
+		)
 
 		if err != nil {
 			return nil, err
@@ -115,44 +523,202 @@ func (tr *TasksRepository) DeleteTaskByID(ctx context.Context, id int) error {
 	return err
 }
 
-func (tr *TasksRepository) StartTimeTracker(ctx context.Context, id, usrID int) error {
-	res, err := tr.db.ExecContext(ctx, queries.StartTimeTracker, time.Now(), id, usrID)
-	if err != nil {
+// StartTimeTracker - открывает новую сессию, если текущее состояние таймера входит в from,
+// а статус задачи - в trackable, и применяет политику к другим идущим таймерам юзера.
+// Задача в статусе todo переходит в работу. Возвращает состояние, в котором
+// таймер был до перехода, и задачи, чьи таймеры были остановлены
+func (tr *TasksRepository) StartTimeTracker(
+	ctx context.Context,
+	id, usrID int,
+	from []models.TimerState,
+	trackable []models.TaskStatus,
+	policy models.TimerPolicy,
+) (models.TimerState, []int, error) {
+	return tr.openTimer(ctx, id, usrID, from, trackable, policy)
+}
+
+// PauseTimeTracker - закрывает идущую сессию паузой, если текущее состояние таймера входит в from.
+// Возвращает состояние, в котором таймер был до перехода
+func (tr *TasksRepository) PauseTimeTracker(ctx context.Context, id, usrID int, from []models.TimerState) (models.TimerState, error) {
+	return tr.switchTimer(ctx, id, usrID, from, nil, func(tx *sql.Tx, now time.Time) error {
+		_, err := tx.ExecContext(ctx, queries.PauseTimeTracker, now, id, usrID)
+		return err
+	})
+}
+
+// ResumeTimeTracker - то же, что StartTimeTracker, но для перехода из паузы: отличаются
+// только состояния from, которые передаёт сервис
+func (tr *TasksRepository) ResumeTimeTracker(
+	ctx context.Context,
+	id, usrID int,
+	from []models.TimerState,
+	trackable []models.TaskStatus,
+	policy models.TimerPolicy,
+) (models.TimerState, []int, error) {
+	return tr.openTimer(ctx, id, usrID, from, trackable, policy)
+}
+
+// StopTimeTracker - закрывает открытую сессию, если текущее состояние таймера входит в from.
+// Возвращает состояние, в котором таймер был до перехода
+func (tr *TasksRepository) StopTimeTracker(ctx context.Context, id, usrID int, from []models.TimerState) (models.TimerState, error) {
+	return tr.switchTimer(ctx, id, usrID, from, nil, func(tx *sql.Tx, now time.Time) error {
+		_, err := tx.ExecContext(ctx, queries.StopTimeTracker, now, id, usrID)
 		return err
+	})
+}
+
+// switchTimer - блокирует строку задачи до конца транзакции, чтобы параллельные запросы
+// к таймеру выполнялись по очереди. Таймер доступен только исполнителю задачи, дальше проверяются
+// статус задачи (trackable nil - любой), текущее состояние таймера юзера и применяется переход
+func (tr *TasksRepository) switchTimer(
+	ctx context.Context,
+	id, usrID int,
+	from []models.TimerState,
+	trackable []models.TaskStatus,
+	apply func(*sql.Tx, time.Time) error,
+) (models.TimerState, error) {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	var status models.TaskStatus
+
+	err = tx.QueryRowContext(ctx, queries.LockUserTaskStatus, id, usrID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrTaskNotFound
 	}
 
-	rowsAffected, err := res.RowsAffected()
 	if err != nil {
+		return "", err
+	}
+
+	if trackable != nil && !slices.Contains(trackable, status) {
+		return "", ErrTaskStatusConflict
+	}
+
+	state := models.TimerIdle
+
+	err = tx.QueryRowContext(ctx, queries.GetTimerState, id, usrID).Scan(&state)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	if !slices.Contains(from, state) {
+		return state, ErrTimerConflict
+	}
+
+	err = apply(tx, time.Now())
+	if err != nil {
+		return state, err
+	}
+
+	return state, tx.Commit()
+}
+
+// openTimer - переход таймера в running из состояний from: switchTimer с openSession
+func (tr *TasksRepository) openTimer(
+	ctx context.Context,
+	id, usrID int,
+	from []models.TimerState,
+	trackable []models.TaskStatus,
+	policy models.TimerPolicy,
+) (models.TimerState, []int, error) {
+	var stopped []int
+
+	state, err := tr.switchTimer(ctx, id, usrID, from, trackable, func(tx *sql.Tx, now time.Time) error {
+		var err error
+
+		stopped, err = openSession(ctx, tx, id, usrID, policy, now)
+
 		return err
+	})
+
+	return state, stopped, err
+}
+
+// openSession - применяет политику таймеров, открывает сессию и переводит задачу из todo в работу
+func openSession(
+	ctx context.Context,
+	tx *sql.Tx,
+	id, usrID int,
+	policy models.TimerPolicy,
+	now time.Time,
+) ([]int, error) {
+	stopped, err := applyTimerPolicy(ctx, tx, id, usrID, policy, now)
+	if err != nil {
+		return stopped, err
 	}
 
-	if rowsAffected == 0 {
-		return ErrTaskNotFound
+	_, err = tx.ExecContext(ctx, queries.StartTimeTracker, now, id, usrID)
+	if err != nil {
+		return stopped, err
 	}
 
-	return nil
+	_, err = tx.ExecContext(ctx, queries.MarkTaskInProgress, id)
+
+	return stopped, err
 }
 
-func (tr *TasksRepository) StopTimeTracker(ctx context.Context, id, usrID int) error {
-	res, err := tr.db.ExecContext(ctx, queries.StopTimeTracker, time.Now(), id, usrID)
+// applyTimerPolicy - блокирует строку юзера, чтобы запуски его таймеров шли по очереди,
+// и останавливает (switch) или запрещает (reject) остальные идущие таймеры юзера
+func applyTimerPolicy(
+	ctx context.Context,
+	tx *sql.Tx,
+	id, usrID int,
+	policy models.TimerPolicy,
+	now time.Time,
+) ([]int, error) {
+	if policy == models.TimerPolicyParallel {
+		return nil, nil
+	}
+
+	var lockedID int
+
+	err := tx.QueryRowContext(ctx, queries.LockUser, usrID).Scan(&lockedID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var rows *sql.Rows
+
+	if policy == models.TimerPolicyReject {
+		rows, err = tx.QueryContext(ctx, queries.FindOtherRunningTimers, usrID, id)
+	} else {
+		rows, err = tx.QueryContext(ctx, queries.StopOtherRunningTimers, now, usrID, id)
 	}
 
-	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return ErrTaskNotFound
+	defer rows.Close()
+
+	var taskIDs []int
+
+	for rows.Next() {
+		var taskID int
+
+		err = rows.Scan(&taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		taskIDs = append(taskIDs, taskID)
 	}
 
-	return nil
+	if policy == models.TimerPolicyReject && len(taskIDs) > 0 {
+		return taskIDs, ErrAnotherTimerRunning
+	}
+
+	return taskIDs, nil
 }
 
-func (tr *TasksRepository) GetAllTasks(ctx context.Context) ([]models.Task, error) {
-	rows, err := tr.db.QueryContext(ctx, queries.GetAllTasks)
+// GetAllTasks - все задачи с отбором по тегам и статусам из filter
+func (tr *TasksRepository) GetAllTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	rows, err := tr.db.QueryContext(ctx, queries.GetAllTasks, pq.Array(filter.Tags), pq.Array(filter.Statuses))
 	if err != nil {
 		return nil, err
 	}
@@ -168,8 +734,18 @@ func (tr *TasksRepository) GetAllTasks(ctx context.Context) ([]models.Task, erro
 			&task.ID,
 			&task.Name,
 			&task.UserID,
+			&task.UserFullName,
 			&task.StartTime,
 			&task.EndTime,
+			&task.State,
+			&task.TotalSeconds,
+			&task.ProjectID,
+			&task.ProjectName,
+			pq.Array(&task.Tags),
+			&task.Status,
+			&task.ParentID,
+			&task.EstimateSeconds,
+			&task.RemainingSeconds,
 		)
 		if err != nil {
 			return nil, err
@@ -6,10 +6,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 )
 
 var errNotFound = errors.New("user not found: ")
+var errUnknownUserField = errors.New("unknown user field")
+
+// refreshColumns - поля юзера, которые обновляются из провайдеров, они же колонки users
+var refreshColumns = map[string]bool{"surname": true, "name": true, "patronymic": true, "address": true}
 
 type UsersRepository struct {
 	db *sql.DB
@@ -20,7 +26,8 @@ func NewUsersRepository(db *sql.DB) *UsersRepository {
 }
 
 func (ur *UsersRepository) GetAllUsers(ctx context.Context, filter models.UserFilter, pg, lim int) ([]models.User, error) {
-	query := squirrel.Select("id", "passport_number", "surname", "name", "patronymic", "address").
+	query := squirrel.Select("id", "passport_number", "surname", "name", "patronymic", "address", "timezone",
+		"enrichment_status", "COALESCE(enrichment_error, '')").
 		From("users")
 
 	if filter.PassportNum != "" {
@@ -43,6 +50,10 @@ func (ur *UsersRepository) GetAllUsers(ctx context.Context, filter models.UserFi
 		query = query.Where(squirrel.Eq{"address": filter.Address})
 	}
 
+	if filter.EnrichmentStatus != "" {
+		query = query.Where(squirrel.Eq{"enrichment_status": filter.EnrichmentStatus})
+	}
+
 	offset := (pg - 1) * lim
 	query = query.Limit(uint64(lim)).Offset(uint64(offset))
 
@@ -55,7 +66,7 @@ func (ur *UsersRepository) GetAllUsers(ctx context.Context, filter models.UserFi
 		return nil, err
 	}
 
-	rows, err := ur.db.QueryContext(ctx, sqlQuery, args...)
+	rows, err := conn(ctx, ur.db).QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +85,9 @@ func (ur *UsersRepository) GetAllUsers(ctx context.Context, filter models.UserFi
 			&user.Name,
 			&user.Patronymic,
 			&user.Address,
+			&user.Timezone,
+			&user.EnrichmentStatus,
+			&user.EnrichmentError,
 		)
 		if err != nil {
 			return nil, err
@@ -88,7 +102,7 @@ func (ur *UsersRepository) GetAllUsers(ctx context.Context, filter models.UserFi
 func (ur *UsersRepository) AddUser(ctx context.Context, user models.ServiceUser) (int, error) {
 	var userID int
 
-	err := ur.db.QueryRowContext(
+	err := conn(ctx, ur.db).QueryRowContext(
 		ctx,
 		queries.CreateUser,
 		user.PassportNum,
@@ -96,6 +110,8 @@ func (ur *UsersRepository) AddUser(ctx context.Context, user models.ServiceUser)
 		user.Name,
 		user.Patronymic,
 		user.Address,
+		user.Timezone,
+		user.Email,
 	).Scan(&userID)
 	if err != nil {
 		return 0, err
@@ -104,10 +120,182 @@ func (ur *UsersRepository) AddUser(ctx context.Context, user models.ServiceUser)
 	return userID, nil
 }
 
+// AddPendingUser - юзер только с номером паспорта и задача на дозаполнение его данных, одним запросом
+func (ur *UsersRepository) AddPendingUser(ctx context.Context, passport, timezone string) (int, error) {
+	var userID int
+
+	err := conn(ctx, ur.db).QueryRowContext(ctx, queries.CreatePendingUser, passport, timezone).Scan(&userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func (ur *UsersRepository) FindUserByID(ctx context.Context, usrID int) (models.User, error) {
+	var user models.User
+
+	err := conn(ctx, ur.db).QueryRowContext(ctx, queries.FindUserByID, usrID).Scan(
+		&user.ID,
+		&user.PassportNumber,
+		&user.Surname,
+		&user.Name,
+		&user.Patronymic,
+		&user.Address,
+		&user.Timezone,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUsrNotExists
+	}
+
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// LockUserByID - FindUserByID с блокировкой строки, вызывается внутри InTx
+func (ur *UsersRepository) LockUserByID(ctx context.Context, usrID int) (models.User, error) {
+	var user models.User
+
+	err := conn(ctx, ur.db).QueryRowContext(ctx, queries.LockUserByID, usrID).Scan(
+		&user.ID,
+		&user.PassportNumber,
+		&user.Surname,
+		&user.Name,
+		&user.Patronymic,
+		&user.Address,
+		&user.Timezone,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUsrNotExists
+	}
+
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// ApplyUserRefresh - записывает только изменённые поля и их историю. Заодно снимает failed
+// после неудачного дозаполнения. Вызывается внутри InTx после LockUserByID
+func (ur *UsersRepository) ApplyUserRefresh(ctx context.Context, usrID int, changes []models.FieldChange) error {
+	query := squirrel.Update("users").
+		Set("enrichment_status", models.EnrichmentDone).
+		Set("enrichment_error", nil).
+		Where(squirrel.Eq{"id": usrID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	fields := make([]string, 0, len(changes))
+	oldValues := make([]string, 0, len(changes))
+	newValues := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		if !refreshColumns[change.Field] {
+			return fmt.Errorf("%w: %q", errUnknownUserField, change.Field)
+		}
+
+		query = query.Set(change.Field, change.New)
+
+		fields = append(fields, change.Field)
+		oldValues = append(oldValues, change.Old)
+		newValues = append(newValues, change.New)
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, ur.db).ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrUsrNotExists
+	}
+
+	_, err = conn(ctx, ur.db).ExecContext(
+		ctx,
+		queries.InsertUserChanges,
+		usrID,
+		pq.Array(fields),
+		pq.Array(oldValues),
+		pq.Array(newValues),
+	)
+
+	return err
+}
+
+// FindUserByEmail - юзер по email без учёта регистра
+func (ur *UsersRepository) FindUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+
+	err := conn(ctx, ur.db).QueryRowContext(ctx, queries.FindUserByEmail, email).Scan(
+		&user.ID,
+		&user.PassportNumber,
+		&user.Surname,
+		&user.Name,
+		&user.Patronymic,
+		&user.Address,
+		&user.Timezone,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUsrNotExists
+	}
+
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// FindUsersByFullName - юзеры, у которых ФИО, "фамилия имя" или "имя фамилия" совпадает с fullName без учёта регистра
+func (ur *UsersRepository) FindUsersByFullName(ctx context.Context, fullName string) ([]models.User, error) {
+	rows, err := conn(ctx, ur.db).QueryContext(ctx, queries.FindUsersByFullName, fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next() {
+		var user models.User
+
+		err := rows.Scan(
+			&user.ID,
+			&user.PassportNumber,
+			&user.Surname,
+			&user.Name,
+			&user.Patronymic,
+			&user.Address,
+			&user.Timezone,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 func (ur *UsersRepository) UpdateUser(ctx context.Context, newUser models.APIResponse, usrID int) (models.User, error) {
 	var user models.User
 
-	err := ur.db.QueryRowContext(
+	err := conn(ctx, ur.db).QueryRowContext(
 		ctx,
 		queries.UpdateUser,
 		usrID,
@@ -115,7 +303,18 @@ func (ur *UsersRepository) UpdateUser(ctx context.Context, newUser models.APIRes
 		newUser.Name,
 		newUser.Patronymic,
 		newUser.Address,
-	).Scan(&user.ID, &user.PassportNumber, &user.Surname, &user.Name, &user.Patronymic, &user.Address)
+		newUser.Timezone,
+	).Scan(
+		&user.ID,
+		&user.PassportNumber,
+		&user.Surname,
+		&user.Name,
+		&user.Patronymic,
+		&user.Address,
+		&user.Timezone,
+		&user.EnrichmentStatus,
+		&user.EnrichmentError,
+	)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -123,7 +322,7 @@ func (ur *UsersRepository) UpdateUser(ctx context.Context, newUser models.APIRes
 	return user, nil
 }
 func (ur *UsersRepository) DeleteUser(ctx context.Context, usrID int) error {
-	result, err := ur.db.ExecContext(ctx, queries.DeleteUser, usrID)
+	result, err := conn(ctx, ur.db).ExecContext(ctx, queries.DeleteUser, usrID)
 	if err != nil {
 		return err
 	}
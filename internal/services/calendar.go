@@ -0,0 +1,75 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+)
+
+// calendarTokenBytes - длина токена подписки в байтах до кодирования в hex
+const calendarTokenBytes = 32
+
+var ErrInvalidCalendarToken = errors.New("invalid calendar token")
+
+type CalendarService struct {
+	calendarRepo models.CalendarRepo
+}
+
+func NewCalendarService(repo models.CalendarRepo) *CalendarService {
+	return &CalendarService{calendarRepo: repo}
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueToken - выпускает новый токен подписки на календарь, прежний токен юзера перестаёт действовать
+func (cs *CalendarService) IssueToken(ctx context.Context, usrID int) (string, error) {
+	raw := make([]byte, calendarTokenBytes)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(raw)
+
+	err = cs.calendarRepo.SaveCalendarToken(ctx, usrID, hashCalendarToken(token))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (cs *CalendarService) RevokeToken(ctx context.Context, usrID int) error {
+	return cs.calendarRepo.DeleteCalendarToken(ctx, usrID)
+}
+
+// GetEvents - сессии юзера за период, если токен совпадает с выпущенным для этого юзера
+func (cs *CalendarService) GetEvents(
+	ctx context.Context,
+	token string,
+	filter models.CalendarFilter,
+) ([]models.CalendarEvent, error) {
+	tokenHash, err := cs.calendarRepo.FindCalendarTokenHash(ctx, filter.UserID)
+	if errors.Is(err, repos.ErrCalendarTokenNotFound) {
+		return nil, ErrInvalidCalendarToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(hashCalendarToken(token))) != 1 {
+		return nil, ErrInvalidCalendarToken
+	}
+
+	return cs.calendarRepo.FindCalendarEvents(ctx, filter)
+}
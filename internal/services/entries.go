@@ -0,0 +1,97 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidEntry     = errors.New("invalid time entry")
+	ErrEntryPeriod      = fmt.Errorf("%w: end_time must be after start_time", ErrInvalidEntry)
+	ErrEntryInFuture    = fmt.Errorf("%w: time entry must not end in the future", ErrInvalidEntry)
+	ErrEntryEmptyReason = fmt.Errorf("%w: reason is required", ErrInvalidEntry)
+)
+
+type TimeEntryService struct {
+	entriesRepo models.TimeEntryRepo
+}
+
+func NewTimeEntryService(repo models.TimeEntryRepo) *TimeEntryService {
+	return &TimeEntryService{entriesRepo: repo}
+}
+
+// validateEntry - ручная сессия должна быть закрытой, не уходить в будущее и иметь причину правки
+func validateEntry(req models.TimeEntryRequest) error {
+	if req.StartTime.IsZero() || req.EndTime.IsZero() || !req.StartTime.Before(req.EndTime) {
+		return ErrEntryPeriod
+	}
+
+	if req.EndTime.After(time.Now()) {
+		return ErrEntryInFuture
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return ErrEntryEmptyReason
+	}
+
+	return nil
+}
+
+func (es *TimeEntryService) CreateEntry(
+	ctx context.Context,
+	taskID, usrID int,
+	req models.TimeEntryRequest,
+) (models.TimeEntry, error) {
+	err := validateEntry(req)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	return es.entriesRepo.CreateEntry(ctx, models.TimeEntry{
+		TaskID:    taskID,
+		UserID:    usrID,
+		StartTime: req.StartTime,
+		EndTime:   &req.EndTime,
+		Reason:    strings.TrimSpace(req.Reason),
+	}, trackableStatuses)
+}
+
+func (es *TimeEntryService) UpdateEntry(
+	ctx context.Context,
+	entryID, usrID int,
+	req models.TimeEntryRequest,
+) (models.TimeEntry, error) {
+	err := validateEntry(req)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	return es.entriesRepo.UpdateEntry(ctx, models.TimeEntry{
+		ID:        entryID,
+		UserID:    usrID,
+		StartTime: req.StartTime,
+		EndTime:   &req.EndTime,
+		Reason:    strings.TrimSpace(req.Reason),
+	})
+}
+
+func (es *TimeEntryService) DeleteEntry(ctx context.Context, entryID, usrID int, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrEntryEmptyReason
+	}
+
+	return es.entriesRepo.DeleteEntry(ctx, entryID, usrID, reason)
+}
+
+func (es *TimeEntryService) GetActiveTimers(
+	ctx context.Context,
+	filter models.ActiveTimerFilter,
+	pg, lim int,
+) ([]models.ActiveTimer, error) {
+	return es.entriesRepo.FindActiveTimers(ctx, filter, pg, lim)
+}
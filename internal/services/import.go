@@ -0,0 +1,274 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrImportRejected = errors.New("import has rows that cannot be imported")
+
+// errImportDryRun - откатывает транзакцию пробного импорта
+var errImportDryRun = errors.New("import dry run")
+
+type ImportService struct {
+	transactor  models.Transactor
+	usersRepo   models.UserRepo
+	tasksRepo   models.TaskRepo
+	entriesRepo models.TimeEntryRepo
+}
+
+func NewImportService(
+	transactor models.Transactor,
+	usersRepo models.UserRepo,
+	tasksRepo models.TaskRepo,
+	entriesRepo models.TimeEntryRepo,
+) *ImportService {
+	return &ImportService{transactor: transactor, usersRepo: usersRepo, tasksRepo: tasksRepo, entriesRepo: entriesRepo}
+}
+
+// importMatch - юзер или задача, найденные или созданные за время импорта. Пустой reason - совпадение есть
+type importMatch struct {
+	id     int
+	reason string
+}
+
+// importRun - состояние одного импорта: юзеры и задачи ищутся в базе один раз на весь файл
+type importRun struct {
+	opts   models.ImportOptions
+	report *models.ImportReport
+	users  map[string]importMatch
+	tasks  map[string]int
+}
+
+// splitImportName - ФИО для нового юзера. Трекеры пишут "Имя Фамилия", из трёх и более слов
+// читаем "Фамилия Имя Отчество", как ФИО показывается в сервисе
+func splitImportName(fullName string) (string, string, string) {
+	parts := strings.Fields(fullName)
+
+	switch len(parts) {
+	case 0:
+		return "", "", ""
+	case 1:
+		return "", parts[0], ""
+	case 2:
+		return parts[1], parts[0], ""
+	default:
+		return parts[0], parts[1], strings.Join(parts[2:], " ")
+	}
+}
+
+// ImportEntries - переносит сессии из выгрузки Toggl или Clockify одной транзакцией. Юзер ищется по email,
+// затем по имени, задача - по названию у этого юзера; ненайденные создаются. Пробный импорт и импорт
+// с проблемными строками без SkipInvalid откатываются, а отчёт показывает, что было бы загружено
+func (is *ImportService) ImportEntries(
+	ctx context.Context,
+	r io.Reader,
+	opts models.ImportOptions,
+) (models.ImportReport, error) {
+	loc := time.UTC
+
+	if opts.Timezone != "" {
+		var err error
+
+		loc, err = LoadTimezone(opts.Timezone)
+		if err != nil {
+			return models.ImportReport{}, err
+		}
+	}
+
+	format, rows, invalid, err := parseImportCSV(r, opts.Format, loc, time.Now())
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	report := models.ImportReport{
+		Format:       format,
+		DryRun:       opts.DryRun,
+		Rows:         len(rows) + len(invalid),
+		CreatedUsers: []string{},
+		CreatedTasks: []string{},
+		Invalid:      append([]models.ImportIssue{}, invalid...),
+		Unmatched:    []models.ImportIssue{},
+		Conflicts:    []models.ImportIssue{},
+	}
+
+	run := &importRun{
+		opts:   opts,
+		report: &report,
+		users:  make(map[string]importMatch),
+		tasks:  make(map[string]int),
+	}
+
+	err = is.transactor.InTx(ctx, func(ctx context.Context) error {
+		for _, row := range rows {
+			err := is.importRow(ctx, run, row)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.DryRun {
+			return errImportDryRun
+		}
+
+		if !opts.SkipInvalid && len(report.Invalid)+len(report.Unmatched)+len(report.Conflicts) > 0 {
+			return ErrImportRejected
+		}
+
+		return nil
+	})
+	if errors.Is(err, errImportDryRun) {
+		return report, nil
+	}
+
+	if err != nil {
+		return report, err
+	}
+
+	report.Committed = true
+
+	return report, nil
+}
+
+func (is *ImportService) importRow(ctx context.Context, run *importRun, row models.ImportRow) error {
+	issue := models.ImportIssue{Line: row.Line, User: importUserLabel(row), Task: row.TaskName}
+
+	user, err := is.matchUser(ctx, run, row)
+	if err != nil {
+		return err
+	}
+
+	if user.reason != "" {
+		issue.Reason = user.reason
+		run.report.Unmatched = append(run.report.Unmatched, issue)
+
+		return nil
+	}
+
+	taskID, err := is.matchTask(ctx, run, user.id, row)
+	if err != nil {
+		return err
+	}
+
+	_, err = is.entriesRepo.ImportEntry(ctx, models.TimeEntry{
+		TaskID:    taskID,
+		UserID:    user.id,
+		StartTime: row.Start,
+		EndTime:   &row.End,
+		Reason:    fmt.Sprintf("импорт из %s, строка %d", run.report.Format, row.Line),
+	})
+	if errors.Is(err, repos.ErrEntryOverlap) {
+		issue.Reason = fmt.Sprintf("overlaps another entry of the user (%s - %s)",
+			row.Start.Format(time.RFC3339), row.End.Format(time.RFC3339))
+		run.report.Conflicts = append(run.report.Conflicts, issue)
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	run.report.Imported++
+
+	return nil
+}
+
+// matchUser - юзер по email, затем по имени. Однофамильцы не угадываются: строка уходит в unmatched
+func (is *ImportService) matchUser(ctx context.Context, run *importRun, row models.ImportRow) (importMatch, error) {
+	key := "name:" + strings.ToLower(row.User)
+	if row.Email != "" {
+		key = "email:" + strings.ToLower(row.Email)
+	}
+
+	if match, ok := run.users[key]; ok {
+		return match, nil
+	}
+
+	if row.Email != "" {
+		user, err := is.usersRepo.FindUserByEmail(ctx, row.Email)
+		if err == nil {
+			run.users[key] = importMatch{id: user.ID}
+
+			return run.users[key], nil
+		}
+
+		if !errors.Is(err, repos.ErrUsrNotExists) {
+			return importMatch{}, err
+		}
+	}
+
+	if row.User != "" {
+		users, err := is.usersRepo.FindUsersByFullName(ctx, row.User)
+		if err != nil {
+			return importMatch{}, err
+		}
+
+		switch {
+		case len(users) == 1:
+			run.users[key] = importMatch{id: users[0].ID}
+
+			return run.users[key], nil
+		case len(users) > 1:
+			run.users[key] = importMatch{reason: fmt.Sprintf("%d users have this name", len(users))}
+
+			return run.users[key], nil
+		}
+	}
+
+	if !run.opts.CreateUsers || row.User == "" {
+		run.users[key] = importMatch{reason: "user not found"}
+
+		return run.users[key], nil
+	}
+
+	surname, name, patronymic := splitImportName(row.User)
+
+	userID, err := is.usersRepo.AddUser(ctx, models.ServiceUser{
+		Surname:    surname,
+		Name:       name,
+		Patronymic: patronymic,
+		Timezone:   run.opts.Timezone,
+		Email:      row.Email,
+	})
+	if err != nil {
+		return importMatch{}, err
+	}
+
+	run.users[key] = importMatch{id: userID}
+	run.report.CreatedUsers = append(run.report.CreatedUsers, importUserLabel(row))
+
+	return run.users[key], nil
+}
+
+func (is *ImportService) matchTask(ctx context.Context, run *importRun, usrID int, row models.ImportRow) (int, error) {
+	key := fmt.Sprintf("%d:%s", usrID, strings.ToLower(row.TaskName))
+
+	if taskID, ok := run.tasks[key]; ok {
+		return taskID, nil
+	}
+
+	task, err := is.tasksRepo.FindTaskByName(ctx, usrID, row.TaskName)
+	if errors.Is(err, repos.ErrTaskNotFound) {
+		task, err = is.tasksRepo.AddTask(ctx, row.TaskName, usrID, nil, nil, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		run.report.CreatedTasks = append(run.report.CreatedTasks, fmt.Sprintf("%s: %s", importUserLabel(row), row.TaskName))
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	run.tasks[key] = task.ID
+
+	return task.ID, nil
+}
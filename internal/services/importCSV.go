@@ -0,0 +1,250 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidImport = errors.New("invalid import file")
+var ErrImportFormat = errors.New("format must be toggl or clockify")
+
+// Колонки детальных выгрузок. Toggl и Clockify называют их одинаково с точностью до регистра
+const (
+	importColUser        = "user"
+	importColEmail       = "email"
+	importColProject     = "project"
+	importColTask        = "task"
+	importColDescription = "description"
+	importColStartDate   = "start date"
+	importColStartTime   = "start time"
+	importColEndDate     = "end date"
+	importColEndTime     = "end time"
+)
+
+// Форматы дат и времени в выгрузках. Clockify пишет даты в формате воркспейса и время в 12- или 24-часовом виде
+var (
+	importDateLayouts = map[models.ImportFormat][]string{
+		models.ImportFormatToggl:    {"2006-01-02"},
+		models.ImportFormatClockify: {"01/02/2006", "2006-01-02", "02.01.2006", "02-01-2006"},
+	}
+	importTimeLayouts = []string{"15:04:05", "15:04", "3:04:05 PM", "3:04 PM"}
+)
+
+// ParseImportFormat - формат выгрузки из параметра запроса или флага, пустая строка - определить по заголовку
+func ParseImportFormat(value string) (models.ImportFormat, error) {
+	format := models.ImportFormat(strings.ToLower(strings.TrimSpace(value)))
+
+	switch format {
+	case "", models.ImportFormatToggl, models.ImportFormatClockify:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrImportFormat, value)
+	}
+}
+
+// detectImportFormat - в выгрузке Clockify длительность лежит в колонках "Duration (h)" и "Duration (decimal)"
+func detectImportFormat(columns map[string]int) models.ImportFormat {
+	_, hours := columns["duration (h)"]
+	_, decimal := columns["duration (decimal)"]
+
+	if hours || decimal {
+		return models.ImportFormatClockify
+	}
+
+	return models.ImportFormatToggl
+}
+
+func importColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\xEF\xBB\xBF")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+
+	for _, name := range []string{importColStartDate, importColStartTime, importColEndDate, importColEndTime} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: no %q column", ErrInvalidImport, name)
+		}
+	}
+
+	_, user := columns[importColUser]
+	_, email := columns[importColEmail]
+
+	if !user && !email {
+		return nil, fmt.Errorf("%w: no user or email column", ErrInvalidImport)
+	}
+
+	_, description := columns[importColDescription]
+	_, task := columns[importColTask]
+	_, project := columns[importColProject]
+
+	if !description && !task && !project {
+		return nil, fmt.Errorf("%w: no description, task or project column", ErrInvalidImport)
+	}
+
+	return columns, nil
+}
+
+// importUserLabel - как показывать юзера строки в отчёте импорта
+func importUserLabel(row models.ImportRow) string {
+	if row.User == "" {
+		return row.Email
+	}
+
+	if row.Email == "" {
+		return row.User
+	}
+
+	return fmt.Sprintf("%s <%s>", row.User, row.Email)
+}
+
+func parseImportTime(date, clock string, layouts []string, loc *time.Location) (time.Time, error) {
+	date = strings.TrimSpace(date)
+	clock = strings.ToUpper(strings.TrimSpace(clock))
+
+	for _, dateLayout := range layouts {
+		for _, timeLayout := range importTimeLayouts {
+			parsed, err := time.ParseInLocation(dateLayout+" "+timeLayout, date+" "+clock, loc)
+			if err == nil {
+				return parsed, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q %q", date, clock)
+}
+
+// parseImportRow - строка выгрузки. Название задачи - описание записи, без него - задача или проект
+func parseImportRow(
+	record []string,
+	columns map[string]int,
+	layouts []string,
+	loc *time.Location,
+	now time.Time,
+) (models.ImportRow, string) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	row := models.ImportRow{
+		User:  strings.Join(strings.Fields(field(importColUser)), " "),
+		Email: field(importColEmail),
+	}
+
+	for _, name := range []string{importColDescription, importColTask, importColProject} {
+		if row.TaskName = field(name); row.TaskName != "" {
+			break
+		}
+	}
+
+	if row.User == "" && row.Email == "" {
+		return row, "no user or email"
+	}
+
+	if row.TaskName == "" {
+		return row, "no description, task or project"
+	}
+
+	if field(importColEndDate) == "" || field(importColEndTime) == "" {
+		return row, "entry is still running"
+	}
+
+	var err error
+
+	row.Start, err = parseImportTime(field(importColStartDate), field(importColStartTime), layouts, loc)
+	if err != nil {
+		return row, "start: " + err.Error()
+	}
+
+	row.End, err = parseImportTime(field(importColEndDate), field(importColEndTime), layouts, loc)
+	if err != nil {
+		return row, "end: " + err.Error()
+	}
+
+	if !row.Start.Before(row.End) {
+		return row, "end must be after start"
+	}
+
+	if row.End.After(now) {
+		return row, "entry ends in the future"
+	}
+
+	return row, ""
+}
+
+// parseImportCSV - разбирает детальную выгрузку Toggl или Clockify. Строки, которые не разобрать,
+// возвращаются отдельно с причиной, а ошибка - только если не читается сам файл или заголовок
+func parseImportCSV(
+	r io.Reader,
+	format models.ImportFormat,
+	loc *time.Location,
+	now time.Time,
+) (models.ImportFormat, []models.ImportRow, []models.ImportIssue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return "", nil, nil, fmt.Errorf("%w: empty file", ErrInvalidImport)
+	}
+
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+
+	columns, err := importColumns(header)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	if format == "" {
+		format = detectImportFormat(columns)
+	}
+
+	var rows []models.ImportRow
+
+	var invalid []models.ImportIssue
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+		}
+
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		row, reason := parseImportRow(record, columns, importDateLayouts[format], loc, now)
+		row.Line = line
+
+		if reason != "" {
+			invalid = append(invalid, models.ImportIssue{Line: line, User: importUserLabel(row), Task: row.TaskName, Reason: reason})
+
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return format, rows, invalid, nil
+}
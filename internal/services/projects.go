@@ -0,0 +1,97 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxProjectNameLen - длина колонки name у clients и projects
+const maxProjectNameLen = 255
+
+var ErrInvalidProjectName = errors.New("name must be from 1 to 255 characters")
+
+type ProjectService struct {
+	projectsRepo models.ProjectRepo
+}
+
+func NewProjectService(repo models.ProjectRepo) *ProjectService {
+	return &ProjectService{projectsRepo: repo}
+}
+
+// projectName - название клиента или проекта без пробелов по краям
+func projectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxProjectNameLen {
+		return "", ErrInvalidProjectName
+	}
+
+	return name, nil
+}
+
+func (ps *ProjectService) CreateClient(ctx context.Context, req models.ClientRequest) (models.Client, error) {
+	name, err := projectName(req.Name)
+	if err != nil {
+		return models.Client{}, err
+	}
+
+	return ps.projectsRepo.CreateClient(ctx, name)
+}
+
+func (ps *ProjectService) GetClients(ctx context.Context) ([]models.Client, error) {
+	return ps.projectsRepo.FindClients(ctx)
+}
+
+func (ps *ProjectService) GetClientByID(ctx context.Context, id int) (models.Client, error) {
+	return ps.projectsRepo.FindClientByID(ctx, id)
+}
+
+func (ps *ProjectService) UpdateClient(ctx context.Context, id int, req models.ClientRequest) (models.Client, error) {
+	name, err := projectName(req.Name)
+	if err != nil {
+		return models.Client{}, err
+	}
+
+	return ps.projectsRepo.UpdateClient(ctx, id, name)
+}
+
+func (ps *ProjectService) DeleteClient(ctx context.Context, id int) error {
+	return ps.projectsRepo.DeleteClient(ctx, id)
+}
+
+func (ps *ProjectService) CreateProject(ctx context.Context, req models.ProjectRequest) (models.Project, error) {
+	var err error
+
+	req.Name, err = projectName(req.Name)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return ps.projectsRepo.CreateProject(ctx, req)
+}
+
+func (ps *ProjectService) GetProjects(ctx context.Context, filter models.ProjectFilter) ([]models.Project, error) {
+	return ps.projectsRepo.FindProjects(ctx, filter)
+}
+
+func (ps *ProjectService) GetProjectByID(ctx context.Context, id int) (models.Project, error) {
+	return ps.projectsRepo.FindProjectByID(ctx, id)
+}
+
+// UpdateProject - заменяет название и клиента проекта, client_id: null делает проект внутренним
+func (ps *ProjectService) UpdateProject(ctx context.Context, id int, req models.ProjectRequest) (models.Project, error) {
+	var err error
+
+	req.Name, err = projectName(req.Name)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	return ps.projectsRepo.UpdateProject(ctx, id, req)
+}
+
+func (ps *ProjectService) DeleteProject(ctx context.Context, id int) error {
+	return ps.projectsRepo.DeleteProject(ctx, id)
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxSummaryBuckets - ограничение на число дней или недель в одном отчёте
+const maxSummaryBuckets = 366
+
+var ErrInvalidReportBucket = errors.New("group must be day or week")
+var ErrReportPeriodTooLong = fmt.Errorf("report period must not exceed %d buckets", maxSummaryBuckets)
+var ErrTagGroupUnsupported = errors.New("group_by=tag is supported only by workload")
+
+// Названия групп для задач без проекта и проектов без клиента
+const (
+	noProjectName = "Без проекта"
+	noClientName  = "Без клиента"
+	noTagName     = "Без тега"
+)
+
+type ReportService struct {
+	reportsRepo models.ReportRepo
+}
+
+func NewReportService(repo models.ReportRepo) *ReportService {
+	return &ReportService{reportsRepo: repo}
+}
+
+// GetWorkload - трудозатраты по задачам или группам из GroupBy, от большей затраты к меньшей
+func (rs *ReportService) GetWorkload(ctx context.Context, filter models.ReportFilter) ([]models.TaskWorkload, error) {
+	if filter.GroupBy == models.ReportGroupTag {
+		return rs.getTagWorkload(ctx, filter)
+	}
+
+	workload, err := rs.reportsRepo.GetWorkload(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var grouped []models.TaskWorkload
+
+	groupIndex := make(map[string]int)
+
+	for _, item := range workload {
+		scope, name, key := groupScope(filter.GroupBy, item.ReportScope, item.Name)
+
+		i, ok := groupIndex[key]
+		if !ok {
+			i = len(grouped)
+			groupIndex[key] = i
+			grouped = append(grouped, models.TaskWorkload{ReportScope: scope, Name: name})
+		}
+
+		grouped[i].TotalSeconds += item.TotalSeconds
+		grouped[i].AutoStoppedEntries += item.AutoStoppedEntries
+	}
+
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return grouped[i].TotalSeconds > grouped[j].TotalSeconds
+	})
+
+	for i := range grouped {
+		grouped[i].Hours = grouped[i].TotalSeconds / 3600
+		grouped[i].Minutes = grouped[i].TotalSeconds % 3600 / 60
+	}
+
+	return grouped, nil
+}
+
+// getTagWorkload - трудозатраты по тегам. Задача с несколькими тегами попадает в каждый,
+// поэтому сумма по тегам может быть больше отслеженного времени
+func (rs *ReportService) getTagWorkload(ctx context.Context, filter models.ReportFilter) ([]models.TaskWorkload, error) {
+	workload, err := rs.reportsRepo.GetTagWorkload(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range workload {
+		if workload[i].TagID == nil {
+			workload[i].Name = noTagName
+		}
+
+		workload[i].Hours = workload[i].TotalSeconds / 3600
+		workload[i].Minutes = workload[i].TotalSeconds % 3600 / 60
+	}
+
+	return workload, nil
+}
+
+// groupScope - сводит строку отчёта к уровню group: возвращает задачу, проект и клиента группы,
+// её название и ключ, одинаковый у строк одной группы
+func groupScope(group models.ReportGroup, scope models.ReportScope, name string) (models.ReportScope, string, string) {
+	switch group {
+	case models.ReportGroupProject:
+		name = scope.ProjectName
+		if scope.ProjectID == nil {
+			name = noProjectName
+		}
+
+		scope = models.ReportScope{ProjectID: scope.ProjectID, ClientID: scope.ClientID, ClientName: scope.ClientName}
+	case models.ReportGroupClient:
+		name = scope.ClientName
+		if scope.ClientID == nil {
+			name = noClientName
+		}
+
+		scope = models.ReportScope{ClientID: scope.ClientID}
+	}
+
+	key := fmt.Sprintf("%d/%d/%d", scope.TaskID, scopeID(scope.ProjectID), scopeID(scope.ClientID))
+
+	return scope, name, key
+}
+
+func scopeID(id *int) int {
+	if id == nil {
+		return 0
+	}
+
+	return *id
+}
+
+// GetSummary - затраты юзера по дням или неделям в часовом поясе фильтра
+func (rs *ReportService) GetSummary(ctx context.Context, filter models.ReportFilter) ([]models.PeriodSummary, error) {
+	bucketLen := 24 * time.Hour
+
+	switch filter.Bucket {
+	case models.ReportBucketDay:
+	case models.ReportBucketWeek:
+		bucketLen *= 7
+	default:
+		return nil, ErrInvalidReportBucket
+	}
+
+	if filter.GroupBy == models.ReportGroupTag {
+		return nil, ErrTagGroupUnsupported
+	}
+
+	if filter.To.Sub(filter.From) > maxSummaryBuckets*bucketLen {
+		return nil, ErrReportPeriodTooLong
+	}
+
+	cells, err := rs.reportsRepo.GetSummary(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary []models.PeriodSummary
+
+	var groupIndex map[string]int
+
+	for _, cell := range cells {
+		if n := len(summary); n == 0 || !summary[n-1].Start.Equal(cell.Start) {
+			start := cell.Start.In(filter.Location)
+			summary = append(summary, models.PeriodSummary{
+				Period: periodLabel(start, filter.Bucket),
+				Start:  start,
+				End:    cell.End.In(filter.Location),
+			})
+			groupIndex = make(map[string]int)
+		}
+
+		period := &summary[len(summary)-1]
+		period.TotalSeconds += cell.TotalSeconds
+
+		// без group_by сводка только по периодам, пустой период строк по задачам не имеет
+		if filter.GroupBy == "" || cell.TaskID == 0 {
+			continue
+		}
+
+		scope, name, key := groupScope(filter.GroupBy, cell.ReportScope, cell.Name)
+
+		i, ok := groupIndex[key]
+		if !ok {
+			i = len(period.Groups)
+			groupIndex[key] = i
+			period.Groups = append(period.Groups, models.ReportGroupTotal{ReportScope: scope, Name: name})
+		}
+
+		period.Groups[i].TotalSeconds += cell.TotalSeconds
+	}
+
+	for i := range summary {
+		summary[i].Hours = summary[i].TotalSeconds / 3600
+		summary[i].Minutes = summary[i].TotalSeconds % 3600 / 60
+
+		groups := summary[i].Groups
+
+		sort.SliceStable(groups, func(a, b int) bool {
+			return groups[a].TotalSeconds > groups[b].TotalSeconds
+		})
+
+		for j := range groups {
+			groups[j].Hours = groups[j].TotalSeconds / 3600
+			groups[j].Minutes = groups[j].TotalSeconds % 3600 / 60
+		}
+	}
+
+	return summary, nil
+}
+
+// ResolveLocation - часовой пояс отчёта: явно переданный tz, иначе пояс из профиля юзера
+func (rs *ReportService) ResolveLocation(ctx context.Context, usrID int, tz string) (*time.Location, error) {
+	if tz != "" {
+		return LoadTimezone(tz)
+	}
+
+	timezone, err := rs.reportsRepo.FindUserTimezone(ctx, usrID)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadTimezone(timezone)
+}
+
+// GetUser - данные юзера для шапки и строк выгружаемых отчётов
+func (rs *ReportService) GetUser(ctx context.Context, usrID int) (models.User, error) {
+	return rs.reportsRepo.FindUserByID(ctx, usrID)
+}
+
+func periodLabel(start time.Time, bucket models.ReportBucket) string {
+	if bucket == models.ReportBucketWeek {
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+
+	return start.Format(time.DateOnly)
+}
+
+// GetTimesheet - табель по дням периода фильтра, строки по задачам или группам из GroupBy.
+// Минуты в ячейках округляются вниз, итоги считаются по ячейкам, чтобы сетка сходилась
+func (rs *ReportService) GetTimesheet(
+	ctx context.Context,
+	filter models.ReportFilter,
+	period string,
+) (models.Timesheet, error) {
+	if filter.To.Sub(filter.From) > maxSummaryBuckets*24*time.Hour {
+		return models.Timesheet{}, ErrReportPeriodTooLong
+	}
+
+	if filter.GroupBy == models.ReportGroupTag {
+		return models.Timesheet{}, ErrTagGroupUnsupported
+	}
+
+	cells, err := rs.reportsRepo.GetTimesheet(ctx, filter)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	sheet := models.Timesheet{
+		Period:   period,
+		Timezone: filter.Location.String(),
+		Days:     []string{},
+		Rows:     []models.TimesheetRow{},
+	}
+
+	dayIndex := make(map[string]int)
+
+	for day := filter.From.In(filter.Location); day.Before(filter.To); day = day.AddDate(0, 0, 1) {
+		dayIndex[day.Format(time.DateOnly)] = len(sheet.Days)
+		sheet.Days = append(sheet.Days, day.Format(time.DateOnly))
+	}
+
+	sheet.DayTotals = make([]int64, len(sheet.Days))
+
+	rowIndex := make(map[string]int)
+
+	for _, cell := range cells {
+		col, ok := dayIndex[cell.Day.In(filter.Location).Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+
+		scope, name, key := groupScope(filter.GroupBy, cell.ReportScope, cell.Name)
+
+		row, ok := rowIndex[key]
+		if !ok {
+			row = len(sheet.Rows)
+			rowIndex[key] = row
+			sheet.Rows = append(sheet.Rows, models.TimesheetRow{
+				ReportScope: scope,
+				Name:        name,
+				Minutes:     make([]int64, len(sheet.Days)),
+			})
+		}
+
+		minutes := cell.TotalSeconds / 60
+
+		sheet.Rows[row].Minutes[col] += minutes
+		sheet.Rows[row].TotalMinutes += minutes
+		sheet.DayTotals[col] += minutes
+		sheet.TotalMinutes += minutes
+	}
+
+	return sheet, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTagNameLen - длина колонки name у tags
+const maxTagNameLen = 64
+
+var ErrInvalidTagName = errors.New("tag name must be from 1 to 64 characters without commas")
+
+type TagService struct {
+	tagsRepo models.TagRepo
+}
+
+func NewTagService(repo models.TagRepo) *TagService {
+	return &TagService{tagsRepo: repo}
+}
+
+// tagName - имя тега без пробелов по краям. Запятая разделяет теги в фильтре, поэтому в имени её нет
+func tagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLen || strings.Contains(name, ",") {
+		return "", ErrInvalidTagName
+	}
+
+	return name, nil
+}
+
+// normalizeTags - теги фильтра в нижнем регистре без пустых и повторов, nil - без отбора
+func normalizeTags(tags []string) []string {
+	var normalized []string
+
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+func (ts *TagService) CreateTag(ctx context.Context, req models.TagRequest) (models.Tag, error) {
+	name, err := tagName(req.Name)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return ts.tagsRepo.CreateTag(ctx, name)
+}
+
+func (ts *TagService) GetTags(ctx context.Context) ([]models.Tag, error) {
+	return ts.tagsRepo.FindTags(ctx)
+}
+
+func (ts *TagService) DeleteTag(ctx context.Context, id int) error {
+	return ts.tagsRepo.DeleteTag(ctx, id)
+}
+
+func (ts *TagService) AttachTag(ctx context.Context, taskID, tagID int) error {
+	return ts.tagsRepo.AttachTag(ctx, taskID, tagID)
+}
+
+func (ts *TagService) DetachTag(ctx context.Context, taskID, tagID int) error {
+	return ts.tagsRepo.DetachTag(ctx, taskID, tagID)
+}
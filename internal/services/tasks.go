@@ -2,36 +2,374 @@ package services
 
 import (
 	"EMTask/internal/models"
+	"EMTask/internal/repos"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 )
 
+var (
+	ErrTimerTransition      = errors.New("invalid timer transition")
+	ErrTimerAlreadyRunning  = fmt.Errorf("%w: timer is already running", ErrTimerTransition)
+	ErrTimerNotRunning      = fmt.Errorf("%w: timer is not running", ErrTimerTransition)
+	ErrTimerAlreadyFinished = fmt.Errorf("%w: timer is already finished", ErrTimerTransition)
+	ErrTimerPaused          = fmt.Errorf("%w: timer is paused, resume it instead", ErrTimerTransition)
+	ErrTimerAlreadyPaused   = fmt.Errorf("%w: timer is already paused", ErrTimerTransition)
+	ErrAnotherTimerRunning  = fmt.Errorf("%w: another timer is already running", ErrTimerTransition)
+	ErrUnknownTimerPolicy   = errors.New("unknown timer policy")
+	ErrTaskNotTrackable     = fmt.Errorf("%w: done or archived task can't be tracked", ErrTimerTransition)
+)
+
+var (
+	ErrUnknownTaskStatus    = errors.New("status must be todo, in_progress, done or archived")
+	ErrTaskStatusTransition = errors.New("invalid status transition")
+)
+
+var (
+	ErrInvalidEstimate        = errors.New("estimate_seconds must be positive")
+	ErrInvalidBudgetThreshold = errors.New("threshold must be a positive percent")
+)
+
+// DefaultBudgetThreshold - порог перерасхода в процентах оценки, если он не задан в запросе
+const DefaultBudgetThreshold = 100
+
+type timerAction string
+
+const (
+	timerStart  timerAction = "start"
+	timerPause  timerAction = "pause"
+	timerResume timerAction = "resume"
+	timerStop   timerAction = "stop"
+)
+
+// timerStates - все состояния таймера в порядке жизненного цикла
+var timerStates = []models.TimerState{
+	models.TimerIdle,
+	models.TimerRunning,
+	models.TimerPaused,
+	models.TimerStopped,
+}
+
+// timerTransitions - машина состояний таймера: для каждого действия из какого состояния в какое
+// оно переводит. Остановленный таймер можно запустить снова - это откроет новую сессию
+var timerTransitions = map[timerAction]map[models.TimerState]models.TimerState{
+	timerStart: {
+		models.TimerIdle:    models.TimerRunning,
+		models.TimerStopped: models.TimerRunning,
+	},
+	timerPause: {
+		models.TimerRunning: models.TimerPaused,
+	},
+	timerResume: {
+		models.TimerPaused: models.TimerRunning,
+	},
+	timerStop: {
+		models.TimerRunning: models.TimerStopped,
+		models.TimerPaused:  models.TimerStopped,
+	},
+}
+
+// timerSources - состояния, из которых разрешено действие. Репозиторий проверяет
+// их в той же транзакции, что и сам переход
+func timerSources(action timerAction) []models.TimerState {
+	var sources []models.TimerState
+
+	for _, state := range timerStates {
+		if _, ok := timerTransitions[action][state]; ok {
+			sources = append(sources, state)
+		}
+	}
+
+	return sources
+}
+
+// timerError - переводит отказ репозитория в типизированную ошибку действия над таймером в состоянии from
+func timerError(err error, from models.TimerState, action timerAction) error {
+	if !errors.Is(err, repos.ErrTimerConflict) {
+		return err
+	}
+
+	switch {
+	case from == models.TimerRunning:
+		return ErrTimerAlreadyRunning
+	case from == models.TimerPaused && action == timerPause:
+		return ErrTimerAlreadyPaused
+	case from == models.TimerPaused:
+		return ErrTimerPaused
+	case from == models.TimerStopped:
+		return ErrTimerAlreadyFinished
+	case from == models.TimerIdle:
+		return ErrTimerNotRunning
+	}
+
+	return fmt.Errorf("%w: %s from %s", ErrTimerTransition, action, from)
+}
+
+// timerStartError - как timerError, но ещё сообщает, какие таймеры мешают запуску по политике reject
+func timerStartError(err error, from models.TimerState, running []int, action timerAction) error {
+	if errors.Is(err, repos.ErrAnotherTimerRunning) {
+		return fmt.Errorf("%w: tasks %v", ErrAnotherTimerRunning, running)
+	}
+
+	if errors.Is(err, repos.ErrTaskStatusConflict) {
+		return ErrTaskNotTrackable
+	}
+
+	return timerError(err, from, action)
+}
+
+// taskStatuses - все статусы задачи в порядке жизненного цикла
+var taskStatuses = []models.TaskStatus{
+	models.TaskTodo,
+	models.TaskInProgress,
+	models.TaskDone,
+	models.TaskArchived,
+}
+
+// statusTransitions - в какие статусы можно перевести задачу из каждого статуса. Завершённую задачу
+// можно вернуть в работу, архивную - только в todo
+var statusTransitions = map[models.TaskStatus][]models.TaskStatus{
+	models.TaskTodo:       {models.TaskInProgress, models.TaskDone, models.TaskArchived},
+	models.TaskInProgress: {models.TaskTodo, models.TaskDone, models.TaskArchived},
+	models.TaskDone:       {models.TaskInProgress, models.TaskArchived},
+	models.TaskArchived:   {models.TaskTodo},
+}
+
+// trackableStatuses - статусы, в которых по задаче можно запустить таймер или добавить время вручную
+var trackableStatuses = []models.TaskStatus{models.TaskTodo, models.TaskInProgress}
+
+// statusSources - статусы, из которых задачу можно перевести в to. Репозиторий проверяет
+// их в той же транзакции, что и сам переход
+func statusSources(to models.TaskStatus) []models.TaskStatus {
+	var sources []models.TaskStatus
+
+	for _, status := range taskStatuses {
+		if slices.Contains(statusTransitions[status], to) {
+			sources = append(sources, status)
+		}
+	}
+
+	return sources
+}
+
+// ValidTaskStatus - сообщает, известен ли статус задачи
+func ValidTaskStatus(status models.TaskStatus) bool {
+	return slices.Contains(taskStatuses, status)
+}
+
+// ParseTimerPolicy - разбирает политику запуска таймеров из конфига, по умолчанию
+// у юзера может идти только один таймер и запуск нового останавливает остальные
+func ParseTimerPolicy(policy string) (models.TimerPolicy, error) {
+	switch models.TimerPolicy(policy) {
+	case "":
+		return models.TimerPolicySwitch, nil
+	case models.TimerPolicySwitch, models.TimerPolicyReject, models.TimerPolicyParallel:
+		return models.TimerPolicy(policy), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownTimerPolicy, policy)
+}
+
 type TaskService struct {
-	tasksRepo models.TaskRepo
+	tasksRepo   models.TaskRepo
+	entriesRepo models.TimeEntryRepo
+	timerPolicy models.TimerPolicy
 }
 
-func NewTaskService(repo models.TaskRepo) *TaskService {
-	return &TaskService{tasksRepo: repo}
+func NewTaskService(repo models.TaskRepo, entriesRepo models.TimeEntryRepo, policy models.TimerPolicy) *TaskService {
+	return &TaskService{tasksRepo: repo, entriesRepo: entriesRepo, timerPolicy: policy}
 }
 
-func (tr *TaskService) CreateTask(ctx context.Context, name string, usrID int) (models.Task, error) {
-	task, err := tr.tasksRepo.AddTask(ctx, name, usrID)
+// validEstimate - оценка задачи не задана или положительна
+func validEstimate(estimate *int64) bool {
+	return estimate == nil || *estimate > 0
+}
+
+// CreateTask - создаёт задачу, с parentID - подзадачу
+func (tr *TaskService) CreateTask(
+	ctx context.Context,
+	name string,
+	usrID int,
+	projectID, parentID *int,
+	estimate *int64,
+) (models.Task, error) {
+	if !validEstimate(estimate) {
+		return models.Task{}, ErrInvalidEstimate
+	}
+
+	task, err := tr.tasksRepo.AddTask(ctx, name, usrID, projectID, parentID, estimate)
 	if err != nil {
 		return models.Task{}, err
 	}
 
 	return task, nil
 }
-func (tr *TaskService) GetTaskByID(ctx context.Context, id int) (models.Task, error) {
+
+// SetTaskProject - переносит задачу в проект и возвращает её с новым проектом
+func (tr *TaskService) SetTaskProject(ctx context.Context, id int, projectID *int) (models.Task, error) {
+	err := tr.tasksRepo.SetTaskProject(ctx, id, projectID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return tr.tasksRepo.FindTaskByID(ctx, id)
+}
+
+// SetTaskEstimate - меняет оценку задачи и возвращает её с пересчитанным остатком
+func (tr *TaskService) SetTaskEstimate(ctx context.Context, id int, estimate *int64) (models.Task, error) {
+	if !validEstimate(estimate) {
+		return models.Task{}, ErrInvalidEstimate
+	}
+
+	err := tr.tasksRepo.SetTaskEstimate(ctx, id, estimate)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return tr.tasksRepo.FindTaskByID(ctx, id)
+}
+
+// GetOverBudgetTasks - задачи, отслеженное время которых достигло threshold процентов оценки
+func (tr *TaskService) GetOverBudgetTasks(ctx context.Context, threshold int) ([]models.TaskBudget, error) {
+	if threshold <= 0 {
+		return nil, ErrInvalidBudgetThreshold
+	}
+
+	return tr.tasksRepo.FindOverBudgetTasks(ctx, threshold)
+}
+
+// SetTaskParent - переносит задачу под другую и возвращает её с новым родителем
+func (tr *TaskService) SetTaskParent(ctx context.Context, id int, parentID *int) (models.Task, error) {
+	err := tr.tasksRepo.SetTaskParent(ctx, id, parentID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return tr.tasksRepo.FindTaskByID(ctx, id)
+}
+
+// GetTaskByID - задача с исполнителями и сессиями, с withSubtasks - ещё и с деревом подзадач
+// и временем вместе со всеми потомками
+func (tr *TaskService) GetTaskByID(ctx context.Context, id int, withSubtasks bool) (models.Task, error) {
 	task, err := tr.tasksRepo.FindTaskByID(ctx, id)
 	if err != nil {
 		return models.Task{}, err
 	}
 
+	if withSubtasks {
+		nodes, err := tr.tasksRepo.FindTaskTree(ctx, id)
+		if err != nil {
+			return models.Task{}, err
+		}
+
+		if len(nodes) > 0 {
+			root := buildTaskTree(nodes[0], nodes[1:])
+			task.RollupSeconds = root.RollupSeconds
+			task.Subtasks = root.Subtasks
+		}
+	}
+
+	task.Assignees, err = tr.tasksRepo.FindTaskAssignees(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task.Entries, err = tr.entriesRepo.FindEntriesByTaskID(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+
 	return task, nil
 }
 
-func (tr *TaskService) GetTasksByUserID(ctx context.Context, usrID int, start, end string) ([]models.Task, error) {
-	tasks, err := tr.tasksRepo.FindTasksByUserID(ctx, usrID, start, end)
+// buildTaskTree - собирает дерево с корнем root из узлов потомков, упорядоченных по уровням
+func buildTaskTree(root models.TaskTree, nodes []models.TaskTree) models.TaskTree {
+	children := make(map[int][]models.TaskTree)
+
+	for _, node := range nodes {
+		if node.ParentID != nil {
+			children[*node.ParentID] = append(children[*node.ParentID], node)
+		}
+	}
+
+	var attach func(node models.TaskTree) models.TaskTree
+
+	attach = func(node models.TaskTree) models.TaskTree {
+		for _, child := range children[node.ID] {
+			node.Subtasks = append(node.Subtasks, attach(child))
+		}
+
+		return node
+	}
+
+	return attach(root)
+}
+
+// AssignTask - добавляет юзера в исполнители, дальше он трекает задачу независимо от остальных
+func (tr *TaskService) AssignTask(ctx context.Context, id, usrID int) error {
+	return tr.tasksRepo.AssignTask(ctx, id, usrID)
+}
+
+// UnassignTask - убирает юзера из исполнителей, отслеженное им время по задаче остаётся
+func (tr *TaskService) UnassignTask(ctx context.Context, id, usrID int) error {
+	return tr.tasksRepo.UnassignTask(ctx, id, usrID)
+}
+
+// SetTaskStatus - переводит задачу в новый статус по машине statusTransitions и возвращает её.
+// Завершение или архивация задачи останавливает её идущий таймер
+func (tr *TaskService) SetTaskStatus(ctx context.Context, id int, status models.TaskStatus) (models.Task, error) {
+	if !ValidTaskStatus(status) {
+		return models.Task{}, ErrUnknownTaskStatus
+	}
+
+	stopTimer := !slices.Contains(trackableStatuses, status)
+
+	from, err := tr.tasksRepo.SetTaskStatus(ctx, id, status, statusSources(status), stopTimer)
+	if errors.Is(err, repos.ErrTaskStatusConflict) {
+		return models.Task{}, fmt.Errorf("%w: %s -> %s", ErrTaskStatusTransition, from, status)
+	}
+
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return tr.tasksRepo.FindTaskByID(ctx, id)
+}
+
+// taskFilter - теги фильтра в нижнем регистре без повторов, статусы только известные и без повторов
+func taskFilter(filter models.TaskFilter) (models.TaskFilter, error) {
+	var statuses []models.TaskStatus
+
+	for _, status := range filter.Statuses {
+		if !ValidTaskStatus(status) {
+			return models.TaskFilter{}, ErrUnknownTaskStatus
+		}
+
+		if !slices.Contains(statuses, status) {
+			statuses = append(statuses, status)
+		}
+	}
+
+	filter.Statuses = statuses
+	filter.Tags = normalizeTags(filter.Tags)
+
+	return filter, nil
+}
+
+// GetTasksByUserID - задачи юзера с отбором по тегам и статусам
+func (tr *TaskService) GetTasksByUserID(
+	ctx context.Context,
+	usrID int,
+	start, end string,
+	filter models.TaskFilter,
+) ([]models.Task, error) {
+	filter, err := taskFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := tr.tasksRepo.FindTasksByUserID(ctx, usrID, start, end, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -48,26 +386,64 @@ func (tr *TaskService) DeleteTaskByID(ctx context.Context, id int) error {
 	return nil
 }
 
-func (tr *TaskService) StartTimeTracker(ctx context.Context, id int, usrID int) error {
-	err := tr.tasksRepo.StartTimeTracker(ctx, id, usrID)
+func (tr *TaskService) StartTimeTracker(ctx context.Context, id int, usrID int) (models.TimerStart, error) {
+	state, stopped, err := tr.tasksRepo.StartTimeTracker(
+		ctx,
+		id,
+		usrID,
+		timerSources(timerStart),
+		trackableStatuses,
+		tr.timerPolicy,
+	)
 	if err != nil {
-		return err
+		return models.TimerStart{}, timerStartError(err, state, stopped, timerStart)
+	}
+
+	return models.TimerStart{TaskID: id, State: models.TimerRunning, StoppedTasks: stopped}, nil
+}
+
+func (tr *TaskService) PauseTimeTracker(ctx context.Context, id int, usrID int) error {
+	state, err := tr.tasksRepo.PauseTimeTracker(ctx, id, usrID, timerSources(timerPause))
+	if err != nil {
+		return timerError(err, state, timerPause)
 	}
 
 	return nil
 }
 
+func (tr *TaskService) ResumeTimeTracker(ctx context.Context, id int, usrID int) (models.TimerStart, error) {
+	state, stopped, err := tr.tasksRepo.ResumeTimeTracker(
+		ctx,
+		id,
+		usrID,
+		timerSources(timerResume),
+		trackableStatuses,
+		tr.timerPolicy,
+	)
+	if err != nil {
+		return models.TimerStart{}, timerStartError(err, state, stopped, timerResume)
+	}
+
+	return models.TimerStart{TaskID: id, State: models.TimerRunning, StoppedTasks: stopped}, nil
+}
+
 func (tr *TaskService) StopTimeTracker(ctx context.Context, id int, usrID int) error {
-	err := tr.tasksRepo.StopTimeTracker(ctx, id, usrID)
+	state, err := tr.tasksRepo.StopTimeTracker(ctx, id, usrID, timerSources(timerStop))
 	if err != nil {
-		return err
+		return timerError(err, state, timerStop)
 	}
 
 	return nil
 }
 
-func (tr *TaskService) GetAllTasks(ctx context.Context) ([]models.Task, error) {
-	tasks, err := tr.tasksRepo.GetAllTasks(ctx)
+// GetAllTasks - все задачи с отбором по тегам и статусам
+func (tr *TaskService) GetAllTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	filter, err := taskFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := tr.tasksRepo.GetAllTasks(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -3,14 +3,49 @@ package services
 import (
 	"EMTask/internal/models"
 	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/sync/errgroup"
+	"time"
 )
 
+const (
+	defaultTimezone = "UTC"
+	// MaxRefreshUsers - сколько юзеров можно обновить одним запросом, каждый - это поход к провайдерам
+	MaxRefreshUsers = 100
+	// refreshConcurrency и refreshUserTimeout подобраны так, чтобы MaxRefreshUsers юзеров
+	// уложились в таймаут запроса: 100 / 20 * 5s = 25s при RefreshTimeoutTime в 30s
+	refreshConcurrency = 20
+	refreshUserTimeout = 5 * time.Second
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+var ErrInvalidRefresh = fmt.Errorf("user_ids must contain from 1 to %d ids", MaxRefreshUsers)
+
+// LoadTimezone - часовой пояс по имени из базы IANA. Local не принимается:
+// он зависит от сервера, а база должна понимать пояс так же, как сервис
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+
+	return loc, nil
+}
+
 type UsersService struct {
-	usersRepo models.UserRepo
+	usersRepo  models.UserRepo
+	transactor models.Transactor
+	enricher   models.Enricher
 }
 
-func NewUserService(repo models.UserRepo) *UsersService {
-	return &UsersService{usersRepo: repo}
+// NewUserService - enricher нужен для обновления данных юзеров, он должен ходить к провайдерам мимо кэша
+func NewUserService(repo models.UserRepo, transactor models.Transactor, enricher models.Enricher) *UsersService {
+	return &UsersService{usersRepo: repo, transactor: transactor, enricher: enricher}
 }
 
 func (us *UsersService) GetAllUsers(ctx context.Context, filter models.UserFilter, pg, lim int) ([]models.User, error) {
@@ -22,31 +57,39 @@ func (us *UsersService) GetAllUsers(ctx context.Context, filter models.UserFilte
 	return users, nil
 }
 
-func (us *UsersService) CreateUser(ctx context.Context, resp models.APIResponse, passport string) (models.User, error) {
-	user := models.ServiceUser{
-		PassportNum: passport,
-		Surname:     resp.Surname,
-		Name:        resp.Name,
-		Patronymic:  resp.Patronymic,
-		Address:     resp.Address,
+// CreateUser - заводит юзера только с номером паспорта. ФИО и адрес дозаполняет воркер
+// из People API, до этого юзер в статусе pending
+func (us *UsersService) CreateUser(ctx context.Context, passport, timezone string) (models.User, error) {
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+
+	_, err := LoadTimezone(timezone)
+	if err != nil {
+		return models.User{}, err
 	}
 
-	userID, err := us.usersRepo.AddUser(ctx, user)
+	userID, err := us.usersRepo.AddPendingUser(ctx, passport, timezone)
 	if err != nil {
 		return models.User{}, err
 	}
 
 	return models.User{
-		ID:             userID,
-		PassportNumber: passport,
-		Surname:        resp.Surname,
-		Name:           resp.Name,
-		Patronymic:     resp.Patronymic,
-		Address:        resp.Address,
+		ID:               userID,
+		PassportNumber:   passport,
+		Timezone:         timezone,
+		EnrichmentStatus: models.EnrichmentPending,
 	}, nil
 }
 
 func (us *UsersService) UpdateUser(ctx context.Context, response models.APIResponse, usrID int) (models.User, error) {
+	if response.Timezone != "" {
+		_, err := LoadTimezone(response.Timezone)
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+
 	users, err := us.usersRepo.UpdateUser(ctx, response, usrID)
 	if err != nil {
 		return models.User{}, err
@@ -62,3 +105,113 @@ func (us *UsersService) DeleteUser(ctx context.Context, usrID int) error {
 
 	return nil
 }
+
+// RefreshUser - перезапрашивает ФИО и адрес юзера у провайдеров и сравнивает с сохранёнными.
+// С apply изменения записываются вместе с историей, без него - только показываются. Провайдер
+// может отвечать долго, поэтому перед записью строка юзера блокируется и отличия считаются заново:
+// правка, сделанная за это время, не затрётся
+func (us *UsersService) RefreshUser(ctx context.Context, usrID int, apply bool) (models.UserRefresh, error) {
+	user, err := us.usersRepo.FindUserByID(ctx, usrID)
+	if err != nil {
+		return models.UserRefresh{}, err
+	}
+
+	person, err := us.enricher.Enrich(ctx, user.PassportNumber)
+	if err != nil {
+		return models.UserRefresh{}, err
+	}
+
+	refresh := models.UserRefresh{UserID: usrID, Changes: diffUser(user, person)}
+
+	if !apply {
+		return refresh, nil
+	}
+
+	err = us.transactor.InTx(ctx, func(ctx context.Context) error {
+		locked, err := us.usersRepo.LockUserByID(ctx, usrID)
+		if err != nil {
+			return err
+		}
+
+		refresh.Changes = diffUser(locked, person)
+		if len(refresh.Changes) == 0 {
+			return nil
+		}
+
+		return us.usersRepo.ApplyUserRefresh(ctx, usrID, refresh.Changes)
+	})
+	if err != nil {
+		return models.UserRefresh{}, err
+	}
+
+	refresh.Applied = len(refresh.Changes) > 0
+
+	return refresh, nil
+}
+
+// RefreshUsers - RefreshUser для каждого юзера, не больше refreshConcurrency одновременно и не дольше
+// refreshUserTimeout на юзера. Ошибка одного юзера не останавливает остальных и попадает в его результат,
+// результаты идут в порядке usrIDs
+func (us *UsersService) RefreshUsers(ctx context.Context, usrIDs []int, apply bool) ([]models.UserRefresh, error) {
+	if len(usrIDs) == 0 || len(usrIDs) > MaxRefreshUsers {
+		return nil, ErrInvalidRefresh
+	}
+
+	unique := make([]int, 0, len(usrIDs))
+	seen := make(map[int]bool, len(usrIDs))
+
+	for _, usrID := range usrIDs {
+		if seen[usrID] {
+			continue
+		}
+
+		seen[usrID] = true
+		unique = append(unique, usrID)
+	}
+
+	results := make([]models.UserRefresh, len(unique))
+
+	var g errgroup.Group
+	g.SetLimit(refreshConcurrency)
+
+	for i, usrID := range unique {
+		g.Go(func() error {
+			usrCtx, cancel := context.WithTimeout(ctx, refreshUserTimeout)
+			defer cancel()
+
+			refresh, err := us.RefreshUser(usrCtx, usrID, apply)
+			if err != nil {
+				refresh = models.UserRefresh{UserID: usrID, Error: err.Error()}
+			}
+
+			results[i] = refresh
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return results, nil
+}
+
+// diffUser - поля, в которых данные провайдера расходятся с сохранёнными
+func diffUser(user models.User, person models.APIResponse) []models.FieldChange {
+	changes := []models.FieldChange{}
+
+	for _, field := range []struct {
+		name           string
+		current, fresh string
+	}{
+		{"surname", user.Surname, person.Surname},
+		{"name", user.Name, person.Name},
+		{"patronymic", user.Patronymic, person.Patronymic},
+		{"address", user.Address, person.Address},
+	} {
+		if field.current != field.fresh {
+			changes = append(changes, models.FieldChange{Field: field.name, Old: field.current, New: field.fresh})
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,220 @@
+package workers
+
+import (
+	"EMTask/internal/enrichers"
+	"EMTask/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultEnrichWorkers     = 2
+	defaultEnrichMaxAttempts = 5
+	defaultEnrichInterval    = time.Second
+	defaultEnrichBackoff     = 10 * time.Second
+
+	enrichMaxBackoff = 10 * time.Minute
+	enrichBatchSize  = 10
+	// enrichCallTimeout - срок опроса провайдеров с повторами для одной задачи
+	enrichCallTimeout = 30 * time.Second
+	// enrichLease - сколько задача невидима для других воркеров после захвата. Если воркер упал,
+	// задачу по истечении аренды заберёт другой. Задачи пачки идут друг за другом, поэтому аренда
+	// покрывает всю пачку с запасом на запись результатов: иначе хвост пачки захватили бы повторно
+	enrichLease = enrichBatchSize*enrichCallTimeout + time.Minute
+)
+
+var ErrInvalidEnrichmentConfig = errors.New("invalid enrichment worker config")
+
+// EnrichmentConfig - Workers воркеров раз в Interval разбирают очередь. Неудачная попытка
+// повторяется через Backoff, удваивая паузу, после MaxAttempts попыток юзер получает статус failed
+type EnrichmentConfig struct {
+	Workers     int
+	MaxAttempts int
+	Interval    time.Duration
+	Backoff     time.Duration
+}
+
+// ParseEnrichmentConfig - разбирает число воркеров, попыток, период опроса очереди и начальную паузу
+// в формате time.ParseDuration. Пустые значения - по умолчанию
+func ParseEnrichmentConfig(workers, maxAttempts, interval, backoff string) (EnrichmentConfig, error) {
+	cfg := EnrichmentConfig{
+		Workers:     defaultEnrichWorkers,
+		MaxAttempts: defaultEnrichMaxAttempts,
+		Interval:    defaultEnrichInterval,
+		Backoff:     defaultEnrichBackoff,
+	}
+
+	if workers != "" {
+		n, err := strconv.Atoi(workers)
+		if err != nil || n < 1 {
+			return EnrichmentConfig{}, fmt.Errorf("%w: workers %q", ErrInvalidEnrichmentConfig, workers)
+		}
+
+		cfg.Workers = n
+	}
+
+	if maxAttempts != "" {
+		n, err := strconv.Atoi(maxAttempts)
+		if err != nil || n < 1 {
+			return EnrichmentConfig{}, fmt.Errorf("%w: max attempts %q", ErrInvalidEnrichmentConfig, maxAttempts)
+		}
+
+		cfg.MaxAttempts = n
+	}
+
+	if interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			return EnrichmentConfig{}, fmt.Errorf("%w: interval %q", ErrInvalidEnrichmentConfig, interval)
+		}
+
+		cfg.Interval = d
+	}
+
+	if backoff != "" {
+		d, err := time.ParseDuration(backoff)
+		if err != nil || d <= 0 {
+			return EnrichmentConfig{}, fmt.Errorf("%w: backoff %q", ErrInvalidEnrichmentConfig, backoff)
+		}
+
+		cfg.Backoff = d
+	}
+
+	return cfg, nil
+}
+
+// EnrichmentWorker - фоновый воркер, который дозаполняет новых юзеров данными из enricher
+type EnrichmentWorker struct {
+	repo     models.EnrichmentRepo
+	enricher models.Enricher
+	cfg      EnrichmentConfig
+	logger   *zap.SugaredLogger
+}
+
+func NewEnrichmentWorker(
+	repo models.EnrichmentRepo,
+	enricher models.Enricher,
+	cfg EnrichmentConfig,
+	logger *zap.SugaredLogger,
+) *EnrichmentWorker {
+	return &EnrichmentWorker{repo: repo, enricher: enricher, cfg: cfg, logger: logger}
+}
+
+// Run - запускает Workers воркеров, пока не отменён контекст. Блокируется до их остановки
+func (ew *EnrichmentWorker) Run(ctx context.Context) {
+	done := make(chan struct{})
+
+	for range ew.cfg.Workers {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			ew.loop(ctx)
+		}()
+	}
+
+	for range ew.cfg.Workers {
+		<-done
+	}
+}
+
+// loop - пока очередь отдаёт полную пачку, разбирает её без паузы, иначе ждёт Interval
+func (ew *EnrichmentWorker) loop(ctx context.Context) {
+	ticker := time.NewTicker(ew.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := ew.Poll(ctx)
+		if err != nil {
+			ew.logger.Error("EnrichmentWorker Poll Error: ", err)
+		}
+
+		if n == enrichBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll - один проход: забирает пачку готовых задач и обрабатывает их. Возвращает размер пачки
+func (ew *EnrichmentWorker) Poll(ctx context.Context) (int, error) {
+	jobs, err := ew.repo.ClaimEnrichmentJobs(ctx, enrichBatchSize, enrichLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		err = ew.process(ctx, job)
+		if err != nil {
+			// задача остаётся в очереди и после аренды вернётся к воркерам
+			ew.logger.Error("EnrichmentWorker process Error: ", err)
+		}
+	}
+
+	return len(jobs), nil
+}
+
+func (ew *EnrichmentWorker) process(ctx context.Context, job models.EnrichmentJob) error {
+	ctxWthTimeout, cancel := context.WithTimeout(ctx, enrichCallTimeout)
+	defer cancel()
+
+	person, err := ew.enricher.Enrich(ctxWthTimeout, job.PassportNumber)
+	if err == nil {
+		err = ew.repo.CompleteEnrichmentJob(ctx, job.ID, person)
+		if err != nil {
+			return err
+		}
+
+		ew.logger.Infow("user enriched", "user_id", job.UserID, "attempt", job.Attempts)
+
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if errors.Is(err, enrichers.ErrPersonNotFound) || job.Attempts >= ew.cfg.MaxAttempts {
+		return ew.fail(ctx, job, err.Error())
+	}
+
+	runAt := time.Now().Add(ew.backoff(job.Attempts))
+
+	err = ew.repo.RetryEnrichmentJob(ctx, job.ID, runAt, err.Error())
+	if err != nil {
+		return err
+	}
+
+	ew.logger.Infow("user enrichment postponed", "user_id", job.UserID, "attempt", job.Attempts, "run_at", runAt)
+
+	return nil
+}
+
+func (ew *EnrichmentWorker) fail(ctx context.Context, job models.EnrichmentJob, reason string) error {
+	err := ew.repo.FailEnrichmentJob(ctx, job.ID, reason)
+	if err != nil {
+		return err
+	}
+
+	ew.logger.Infow("user enrichment failed", "user_id", job.UserID, "attempt", job.Attempts, "reason", reason)
+
+	return nil
+}
+
+// backoff - пауза перед следующей попыткой: Backoff * 2^(attempt-1), не больше enrichMaxBackoff
+func (ew *EnrichmentWorker) backoff(attempt int) time.Duration {
+	d := ew.cfg.Backoff << max(attempt-1, 0)
+	if d <= 0 || d > enrichMaxBackoff {
+		return enrichMaxBackoff
+	}
+
+	return d
+}
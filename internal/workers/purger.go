@@ -0,0 +1,57 @@
+package workers
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// cachePurgeInterval - как часто из таблицы кэша enricher удаляются просроченные записи
+const cachePurgeInterval = 10 * time.Minute
+
+// CachePurger - фоновый воркер, который чистит таблицу кэша enricher от просроченных записей
+type CachePurger struct {
+	cache  models.EnrichmentCacheRepo
+	logger *zap.SugaredLogger
+}
+
+func NewCachePurger(cache models.EnrichmentCacheRepo, logger *zap.SugaredLogger) *CachePurger {
+	return &CachePurger{cache: cache, logger: logger}
+}
+
+// Run - чистит кэш сразу и затем раз в cachePurgeInterval, пока не отменён контекст
+func (cp *CachePurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(cachePurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := cp.Purge(ctx)
+		if err != nil {
+			cp.logger.Error("CachePurger Purge Error: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge - один проход: удаляет просроченные записи из таблицы кэша. Возвращает их число
+func (cp *CachePurger) Purge(ctx context.Context) (int64, error) {
+	ctxWthTimeout, cancel := context.WithTimeout(ctx, cachePurgeInterval)
+	defer cancel()
+
+	n, err := cp.cache.DeleteExpiredEnrichmentCache(ctxWthTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	if n > 0 {
+		cp.logger.Infow("enrichment cache purged", "deleted", n)
+	}
+
+	return n, nil
+}
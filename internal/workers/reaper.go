@@ -0,0 +1,114 @@
+package workers
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+const defaultReaperInterval = 5 * time.Minute
+
+var ErrInvalidReaperConfig = errors.New("invalid timer reaper config")
+
+// TimerReaper - фоновый воркер, который закрывает таймеры, забытые на ночь или выходные
+type TimerReaper struct {
+	entriesRepo models.TimeEntryRepo
+	rule        models.AutoStopRule
+	interval    time.Duration
+	logger      *zap.SugaredLogger
+}
+
+// ParseAutoStopRule - разбирает настройки автоостановки: максимальную длительность сессии
+// в формате time.ParseDuration, конец рабочего дня "15:04" и период проверки (по умолчанию 5m)
+func ParseAutoStopRule(maxDuration, cutoff, interval string) (models.AutoStopRule, time.Duration, error) {
+	var rule models.AutoStopRule
+
+	if maxDuration != "" {
+		d, err := time.ParseDuration(maxDuration)
+		if err != nil || d < 0 {
+			return models.AutoStopRule{}, 0, fmt.Errorf("%w: max duration %q", ErrInvalidReaperConfig, maxDuration)
+		}
+
+		rule.MaxDuration = d
+	}
+
+	if cutoff != "" {
+		_, err := time.Parse("15:04", cutoff)
+		if err != nil {
+			return models.AutoStopRule{}, 0, fmt.Errorf("%w: cutoff %q", ErrInvalidReaperConfig, cutoff)
+		}
+
+		rule.Cutoff = cutoff
+	}
+
+	every := defaultReaperInterval
+
+	if interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			return models.AutoStopRule{}, 0, fmt.Errorf("%w: interval %q", ErrInvalidReaperConfig, interval)
+		}
+
+		every = d
+	}
+
+	return rule, every, nil
+}
+
+func NewTimerReaper(
+	repo models.TimeEntryRepo,
+	rule models.AutoStopRule,
+	interval time.Duration,
+	logger *zap.SugaredLogger,
+) *TimerReaper {
+	return &TimerReaper{entriesRepo: repo, rule: rule, interval: interval, logger: logger}
+}
+
+// Enabled - воркер не нужен, если не задано ни одно ограничение
+func (tr *TimerReaper) Enabled() bool {
+	return tr.rule.MaxDuration > 0 || tr.rule.Cutoff != ""
+}
+
+// Run - проверяет таймеры сразу и затем раз в interval, пока не отменён контекст
+func (tr *TimerReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(tr.interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := tr.Sweep(ctx)
+		if err != nil {
+			tr.logger.Error("TimerReaper Sweep Error: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep - один проход: закрывает все таймеры, для которых сработало правило
+func (tr *TimerReaper) Sweep(ctx context.Context) ([]models.TimeEntry, error) {
+	ctxWthTimeout, cancel := context.WithTimeout(ctx, tr.interval)
+	defer cancel()
+
+	stopped, err := tr.entriesRepo.AutoStopTimers(ctxWthTimeout, tr.rule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range stopped {
+		tr.logger.Infow("timer auto-stopped",
+			"entry_id", entry.ID,
+			"task_id", entry.TaskID,
+			"user_id", entry.UserID,
+			"end_time", entry.EndTime,
+		)
+	}
+
+	return stopped, nil
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// utf8BOM - без него Excel открывает UTF-8 CSV с кириллицей как кракозябры
+const utf8BOM = "\xEF\xBB\xBF"
+
+// formulaPrefixes - с этих символов Excel и LibreOffice начинают формулу даже в CSV
+const formulaPrefixes = "=+-@\t\r"
+
+// WriteCSV - пишет таблицу в CSV: шапка, строки, затем строка итогов, если она есть.
+// Текстовые ячейки, похожие на формулы, предваряются апострофом
+func WriteCSV(w io.Writer, t Table) error {
+	_, err := w.Write([]byte(utf8BOM))
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+
+	err = cw.Write(t.csvCells(t.Header, true))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		err = cw.Write(t.csvCells(row, false))
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.Totals != nil {
+		err = cw.Write(t.csvCells(t.Totals, false))
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// csvCells - ячейки строки CSV: текстовые защищены от выполнения как формулы.
+// В XLSX это не нужно - inlineStr никогда не вычисляется, а апостроф там остался бы в тексте
+func (t Table) csvCells(cells []string, header bool) []string {
+	escaped := make([]string, len(cells))
+
+	for i, value := range cells {
+		if !header && t.isNumeric(i) {
+			escaped[i] = value
+			continue
+		}
+
+		escaped[i] = escapeFormula(value)
+	}
+
+	return escaped
+}
+
+// escapeFormula - текст, который табличный редактор принял бы за формулу, предваряется апострофом
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	icsTimeFormat = "20060102T150405Z"
+
+	// строки iCalendar длиннее 75 байт переносятся, продолжение начинается с пробела
+	icsLineLen = 75
+)
+
+// Event - событие календаря, время пишется в UTC
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// Calendar - календарь iCalendar (RFC 5545), Stamp - момент выгрузки для DTSTAMP
+type Calendar struct {
+	Name   string
+	Stamp  time.Time
+	Events []Event
+}
+
+// WriteICS - пишет календарь в формате iCalendar
+func WriteICS(w io.Writer, cal Calendar) error {
+	var sb strings.Builder
+
+	line := func(name, value string) {
+		writeICSLine(&sb, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//EMTask//Time Tracker//RU")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeICS(cal.Name))
+
+	for _, event := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeICS(event.UID))
+		line("DTSTAMP", cal.Stamp.UTC().Format(icsTimeFormat))
+		line("DTSTART", event.Start.UTC().Format(icsTimeFormat))
+		line("DTEND", event.End.UTC().Format(icsTimeFormat))
+		line("SUMMARY", escapeICS(event.Summary))
+
+		if event.Description != "" {
+			line("DESCRIPTION", escapeICS(event.Description))
+		}
+
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	_, err := w.Write([]byte(sb.String()))
+
+	return err
+}
+
+// escapeICS - экранирует значение TEXT. Переводы строк любого вида становятся \n, остальные
+// управляющие символы, кроме табуляции, выбрасываются: через них можно дописать в событие свои свойства
+func escapeICS(value string) string {
+	value = strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(value)
+
+	return strings.Map(func(r rune) rune {
+		if r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, value)
+}
+
+// writeICSLine - пишет строку с переносом по 75 байт, не разрывая символы UTF-8
+func writeICSLine(sb *strings.Builder, value string) {
+	limit := icsLineLen
+
+	for len(value) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+
+		sb.WriteString(value[:cut] + "\r\n ")
+		value = value[cut:]
+
+		// пробел в начале строки продолжения тоже занимает байт
+		limit = icsLineLen - 1
+	}
+
+	sb.WriteString(value + "\r\n")
+}
@@ -0,0 +1,476 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// Шрифт встраивается в каждый документ подмножеством глифов: стандартные шрифты PDF не содержат кириллицы
+//
+//go:embed fonts/DejaVuSans.ttf
+var dejaVuSans []byte
+
+// Геометрия страницы A4 в пунктах
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 40.0
+
+	pdfTitleSize   = 16.0
+	pdfTextSize    = 10.0
+	pdfTableSize   = 9.0
+	pdfCaptionSize = 7.0
+
+	pdfRowHeight   = 16.0
+	pdfCellPadding = 4.0
+	pdfHeaderGray  = 0.88
+	pdfTotalsGray  = 0.94
+
+	pdfSignatureHeight = 42.0
+)
+
+// Объекты документа с постоянными номерами, страницы и их содержимое идут за ними парами
+const (
+	pdfCatalogObj = iota + 1
+	pdfPagesObj
+	pdfFontObj
+	pdfCIDFontObj
+	pdfDescriptorObj
+	pdfFontFileObj
+	pdfToUnicodeObj
+	pdfFirstPageObj
+)
+
+var (
+	pdfFontOnce sync.Once
+	pdfFont     *ttfFont
+	pdfFontErr  error
+)
+
+// Field - строка реквизитов под заголовком документа
+type Field struct {
+	Label string
+	Value string
+}
+
+// Document - печатный отчёт: заголовок, реквизиты, таблица с итогами и блок подписей.
+// Widths - доли ширины таблицы по столбцам, без них столбцы равной ширины
+type Document struct {
+	Title      string
+	Fields     []Field
+	Table      Table
+	Widths     []float64
+	Signatures []string
+}
+
+func loadPDFFont() (*ttfFont, error) {
+	pdfFontOnce.Do(func() {
+		pdfFont, pdfFontErr = parseTTF(dejaVuSans)
+	})
+
+	return pdfFont, pdfFontErr
+}
+
+// WritePDF - пишет документ в PDF на страницах A4. Таблица переносится на следующие страницы
+// с повтором шапки, внизу каждой страницы ставится её номер
+func WritePDF(w io.Writer, doc Document) error {
+	font, err := loadPDFFont()
+	if err != nil {
+		return err
+	}
+
+	layout := &pdfLayout{font: font, used: map[uint16]rune{0: 0}}
+	layout.render(doc)
+
+	subset, err := font.subset(layout.sortedGlyphs())
+	if err != nil {
+		return err
+	}
+
+	fontFile, err := deflate(subset)
+	if err != nil {
+		return err
+	}
+
+	pw := &pdfWriter{}
+	pw.buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	kids := make([]string, len(layout.pages))
+	for i := range layout.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pdfFirstPageObj+i*2)
+	}
+
+	pw.object(pdfCatalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj))
+	pw.object(pdfPagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	pw.object(pdfFontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /DejaVuSans /Encoding /Identity-H "+
+			"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		pdfCIDFontObj, pdfToUnicodeObj,
+	))
+	pw.object(pdfCIDFontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /DejaVuSans "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		pdfDescriptorObj, layout.widths(),
+	))
+	pw.object(pdfDescriptorObj, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName /DejaVuSans /Flags 32 /FontBBox [%d %d %d %d] "+
+			"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		font.bbox[0], font.bbox[1], font.bbox[2], font.bbox[3],
+		font.ascent, font.descent, font.capHeight, pdfFontFileObj,
+	))
+	pw.stream(pdfFontFileObj, fmt.Sprintf("/Length1 %d", len(subset)), fontFile)
+
+	toUnicode, err := deflate(layout.toUnicode())
+	if err != nil {
+		return err
+	}
+
+	pw.stream(pdfToUnicodeObj, "", toUnicode)
+
+	for i, page := range layout.pages {
+		pageObj := pdfFirstPageObj + i*2
+
+		pw.object(pageObj, fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] "+
+				"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pdfPagesObj, pdfPageWidth, pdfPageHeight, pdfFontObj, pageObj+1,
+		))
+
+		content, err := deflate(page.Bytes())
+		if err != nil {
+			return err
+		}
+
+		pw.stream(pageObj+1, "", content)
+	}
+
+	pw.finish(pdfCatalogObj)
+
+	_, err = w.Write(pw.buf.Bytes())
+
+	return err
+}
+
+// pdfLayout - раскладка документа по страницам, собирает содержимое страниц и использованные глифы
+type pdfLayout struct {
+	font  *ttfFont
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64
+	used  map[uint16]rune
+}
+
+func (l *pdfLayout) render(doc Document) {
+	l.newPage()
+
+	l.y -= pdfTitleSize
+	l.text(pdfMargin, l.y, pdfTitleSize, doc.Title)
+	l.y -= pdfTitleSize / 2
+
+	var labelWidth float64
+	for _, field := range doc.Fields {
+		labelWidth = max(labelWidth, l.font.textWidth(field.Label+":", pdfTextSize))
+	}
+
+	for _, field := range doc.Fields {
+		l.y -= pdfTextSize * 1.5
+		l.text(pdfMargin, l.y, pdfTextSize, field.Label+":")
+		l.text(pdfMargin+labelWidth+pdfCellPadding*2, l.y, pdfTextSize, field.Value)
+	}
+
+	l.y -= pdfRowHeight
+
+	l.table(doc.Table, doc.Widths)
+	l.signatures(doc.Signatures)
+	l.pageNumbers()
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = pdfPageHeight - pdfMargin
+}
+
+// fits - помещается ли блок высотой height до нижнего поля, ниже только номер страницы
+func (l *pdfLayout) fits(height float64) bool {
+	return l.y-height >= pdfMargin+pdfRowHeight
+}
+
+func (l *pdfLayout) table(t Table, widths []float64) {
+	columns := len(t.Header)
+	if columns == 0 {
+		return
+	}
+
+	tableWidth := pdfPageWidth - 2*pdfMargin
+	colWidths := make([]float64, columns)
+
+	var total float64
+	for i := 0; i < columns && i < len(widths); i++ {
+		total += widths[i]
+	}
+
+	for i := range colWidths {
+		if len(widths) == columns && total > 0 {
+			colWidths[i] = tableWidth * widths[i] / total
+		} else {
+			colWidths[i] = tableWidth / float64(columns)
+		}
+	}
+
+	header := func() {
+		l.row(t.Header, colWidths, pdfHeaderGray, nil)
+	}
+
+	if !l.fits(pdfRowHeight * 2) {
+		l.newPage()
+	}
+
+	header()
+
+	for _, row := range t.Rows {
+		if !l.fits(pdfRowHeight) {
+			l.newPage()
+			header()
+		}
+
+		l.row(row, colWidths, 0, t.Numeric)
+	}
+
+	if t.Totals != nil {
+		if !l.fits(pdfRowHeight) {
+			l.newPage()
+			header()
+		}
+
+		l.row(t.Totals, colWidths, pdfTotalsGray, t.Numeric)
+	}
+}
+
+// row - строка таблицы с заливкой gray (0 - без заливки), числовые столбцы выравниваются вправо
+func (l *pdfLayout) row(cells []string, colWidths []float64, gray float64, numeric []bool) {
+	top := l.y
+	l.y -= pdfRowHeight
+
+	tableWidth := pdfPageWidth - 2*pdfMargin
+
+	if gray > 0 {
+		fmt.Fprintf(l.page, "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, pdfMargin, l.y, tableWidth, pdfRowHeight)
+	}
+
+	x := pdfMargin
+
+	for i, width := range colWidths {
+		if i < len(cells) {
+			value := l.fit(cells[i], pdfTableSize, width-pdfCellPadding*2)
+			textX := x + pdfCellPadding
+
+			if i < len(numeric) && numeric[i] {
+				textX = x + width - pdfCellPadding - l.font.textWidth(value, pdfTableSize)
+			}
+
+			l.text(textX, l.y+(pdfRowHeight-pdfTableSize)/2+1.5, pdfTableSize, value)
+		}
+
+		x += width
+	}
+
+	l.line(pdfMargin, top, pdfMargin+tableWidth, top)
+	l.line(pdfMargin, l.y, pdfMargin+tableWidth, l.y)
+}
+
+// signatures - для каждого подписанта строка с линиями для подписи и даты
+func (l *pdfLayout) signatures(signers []string) {
+	if len(signers) == 0 {
+		return
+	}
+
+	if !l.fits(pdfSignatureHeight * float64(len(signers))) {
+		l.newPage()
+	}
+
+	signX := pdfMargin + 130
+	dateX := signX + 210
+
+	for _, signer := range signers {
+		l.y -= pdfSignatureHeight
+
+		l.text(pdfMargin, l.y+2, pdfTextSize, l.fit(signer, pdfTextSize, signX-pdfMargin-pdfCellPadding))
+		l.line(signX, l.y, signX+180, l.y)
+		l.line(dateX, l.y, dateX+120, l.y)
+		l.text(signX, l.y-pdfCaptionSize-2, pdfCaptionSize, "(подпись, расшифровка)")
+		l.text(dateX, l.y-pdfCaptionSize-2, pdfCaptionSize, "(дата)")
+	}
+}
+
+func (l *pdfLayout) pageNumbers() {
+	for i, page := range l.pages {
+		l.page = page
+
+		label := fmt.Sprintf("Страница %d из %d", i+1, len(l.pages))
+		l.text(pdfPageWidth-pdfMargin-l.font.textWidth(label, pdfCaptionSize), pdfMargin/2, pdfCaptionSize, label)
+	}
+}
+
+func (l *pdfLayout) text(x, y, size float64, value string) {
+	if value == "" {
+		return
+	}
+
+	var hex strings.Builder
+
+	for _, r := range value {
+		glyph := l.font.glyph(r)
+		l.used[glyph] = r
+		fmt.Fprintf(&hex, "%04X", glyph)
+	}
+
+	fmt.Fprintf(l.page, "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, y, hex.String())
+}
+
+func (l *pdfLayout) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(l.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// fit - обрезает строку с многоточием, чтобы она поместилась в width
+func (l *pdfLayout) fit(value string, size, width float64) string {
+	if l.font.textWidth(value, size) <= width {
+		return value
+	}
+
+	runes := []rune(value)
+	for len(runes) > 0 && l.font.textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
+
+func (l *pdfLayout) sortedGlyphs() []uint16 {
+	glyphs := make([]uint16, 0, len(l.used))
+	for glyph := range l.used {
+		glyphs = append(glyphs, glyph)
+	}
+
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	return glyphs
+}
+
+// widths - массив /W с ширинами только использованных глифов
+func (l *pdfLayout) widths() string {
+	var sb strings.Builder
+
+	for _, glyph := range l.sortedGlyphs() {
+		if int(glyph) < len(l.font.advances) {
+			fmt.Fprintf(&sb, "%d [%d] ", glyph, l.font.advances[glyph])
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// toUnicode - обратное отображение глифов в символы, чтобы текст из PDF можно было копировать и искать
+func (l *pdfLayout) toUnicode() []byte {
+	var sb strings.Builder
+
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	var entries []string
+
+	for _, glyph := range l.sortedGlyphs() {
+		if glyph == 0 {
+			continue
+		}
+
+		var code strings.Builder
+		for _, unit := range utf16.Encode([]rune{l.used[glyph]}) {
+			fmt.Fprintf(&code, "%04X", unit)
+		}
+
+		entries = append(entries, fmt.Sprintf("<%04X> <%s>", glyph, code.String()))
+	}
+
+	// в одном блоке bfchar допускается не больше 100 записей
+	for start := 0; start < len(entries); start += 100 {
+		block := entries[start:min(start+100, len(entries))]
+		fmt.Fprintf(&sb, "%d beginbfchar\n%s\nendbfchar\n", len(block), strings.Join(block, "\n"))
+	}
+
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return []byte(sb.String())
+}
+
+// pdfWriter - запись объектов PDF с учётом смещений для таблицы xref
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (pw *pdfWriter) begin(num int) {
+	for len(pw.offsets) <= num {
+		pw.offsets = append(pw.offsets, 0)
+	}
+
+	pw.offsets[num] = pw.buf.Len()
+	fmt.Fprintf(&pw.buf, "%d 0 obj\n", num)
+}
+
+func (pw *pdfWriter) object(num int, body string) {
+	pw.begin(num)
+	pw.buf.WriteString(body + "\nendobj\n")
+}
+
+// stream - поток, сжатый FlateDecode, extra дописывается в словарь потока
+func (pw *pdfWriter) stream(num int, extra string, data []byte) {
+	if extra != "" {
+		extra = " " + extra
+	}
+
+	pw.begin(num)
+	fmt.Fprintf(&pw.buf, "<< /Length %d /Filter /FlateDecode%s >>\nstream\n", len(data), extra)
+	pw.buf.Write(data)
+	pw.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (pw *pdfWriter) finish(root int) {
+	xref := pw.buf.Len()
+
+	fmt.Fprintf(&pw.buf, "xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets))
+
+	for _, offset := range pw.offsets[1:] {
+		fmt.Fprintf(&pw.buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&pw.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets), root, xref)
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zlib.NewWriter(&buf)
+
+	_, err := zw.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
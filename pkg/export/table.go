@@ -0,0 +1,35 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Table - отчёт в виде таблицы для выгрузки: шапка, строки и строка итогов.
+// Numeric - маска числовых столбцов: их значения в строках и итогах пишутся в XLSX числами
+// и не экранируются в CSV, остальные ячейки всегда текст
+type Table struct {
+	Sheet   string
+	Header  []string
+	Rows    [][]string
+	Totals  []string
+	Numeric []bool
+}
+
+func (t Table) isNumeric(col int) bool {
+	return col < len(t.Numeric) && t.Numeric[col]
+}
+
+// FormatDuration - длительность в читаемом виде: "2 ч 05 мин"
+func FormatDuration(seconds int64) string {
+	if seconds < 3600 {
+		return fmt.Sprintf("%d мин", seconds/60)
+	}
+
+	return fmt.Sprintf("%d ч %02d мин", seconds/3600, seconds%3600/60)
+}
+
+// FormatHours - длительность в часах с двумя знаками, чтобы её можно было суммировать в таблице
+func FormatHours(seconds int64) string {
+	return strconv.FormatFloat(float64(seconds)/3600, 'f', 2, 64)
+}
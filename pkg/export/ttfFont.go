@@ -0,0 +1,429 @@
+package export
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidFont = errors.New("invalid TrueType font")
+
+// ttfFont - метрики TrueType-шрифта, нужные для встраивания в PDF: глифы по символам,
+// ширины глифов и размеры шрифта. Все размеры переведены в единицы PDF (1/1000 кегля)
+type ttfFont struct {
+	data      []byte
+	ascent    int
+	descent   int
+	capHeight int
+	bbox      [4]int
+	advances  []int
+	glyphs    map[rune]uint16
+}
+
+type ttfTable struct {
+	offset int
+	length int
+}
+
+func parseTTF(data []byte) (*ttfFont, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+numTables*16 {
+		return nil, ErrInvalidFont
+	}
+
+	tables := make(map[string]ttfTable, numTables)
+
+	for i := 0; i < numTables; i++ {
+		rec := data[12+i*16:]
+		table := ttfTable{
+			offset: int(binary.BigEndian.Uint32(rec[8:])),
+			length: int(binary.BigEndian.Uint32(rec[12:])),
+		}
+
+		if table.offset+table.length > len(data) {
+			return nil, ErrInvalidFont
+		}
+
+		tables[string(rec[:4])] = table
+	}
+
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, ErrInvalidFont
+		}
+	}
+
+	head := data[tables["head"].offset:]
+	hhea := data[tables["hhea"].offset:]
+	maxp := data[tables["maxp"].offset:]
+
+	unitsPerEm := int(binary.BigEndian.Uint16(head[18:]))
+	if unitsPerEm == 0 {
+		return nil, ErrInvalidFont
+	}
+
+	scale := func(v int16) int {
+		return int(v) * 1000 / unitsPerEm
+	}
+
+	font := &ttfFont{
+		data:    data,
+		ascent:  scale(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent: scale(int16(binary.BigEndian.Uint16(hhea[6:]))),
+		bbox: [4]int{
+			scale(int16(binary.BigEndian.Uint16(head[36:]))),
+			scale(int16(binary.BigEndian.Uint16(head[38:]))),
+			scale(int16(binary.BigEndian.Uint16(head[40:]))),
+			scale(int16(binary.BigEndian.Uint16(head[42:]))),
+		},
+	}
+
+	font.capHeight = font.ascent
+
+	if os2, ok := tables["OS/2"]; ok && os2.length >= 90 {
+		font.capHeight = scale(int16(binary.BigEndian.Uint16(data[os2.offset+88:])))
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || numMetrics > numGlyphs || hmtx.length < numMetrics*4 {
+		return nil, ErrInvalidFont
+	}
+
+	// глифы после numberOfHMetrics берут ширину последней записи
+	font.advances = make([]int, numGlyphs)
+	for i := range font.advances {
+		metric := min(i, numMetrics-1)
+		font.advances[i] = scale(int16(binary.BigEndian.Uint16(data[hmtx.offset+metric*4:])))
+	}
+
+	glyphs, err := parseCmap(data[tables["cmap"].offset : tables["cmap"].offset+tables["cmap"].length])
+	if err != nil {
+		return nil, err
+	}
+
+	font.glyphs = glyphs
+
+	return font, nil
+}
+
+// parseCmap - таблица символ -> глиф из подтаблицы Unicode BMP формата 4
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrInvalidFont
+	}
+
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+
+	for i := 0; i < numTables && 4+i*8+8 <= len(cmap); i++ {
+		rec := cmap[4+i*8:]
+		platformID := binary.BigEndian.Uint16(rec)
+		encodingID := binary.BigEndian.Uint16(rec[2:])
+		offset := int(binary.BigEndian.Uint32(rec[4:]))
+
+		isUnicodeBMP := (platformID == 3 && encodingID == 1) || (platformID == 0 && encodingID == 3)
+		if !isUnicodeBMP || offset+14 > len(cmap) || binary.BigEndian.Uint16(cmap[offset:]) != 4 {
+			continue
+		}
+
+		return parseCmapFormat4(cmap[offset:])
+	}
+
+	return nil, ErrInvalidFont
+}
+
+func parseCmapFormat4(sub []byte) (map[rune]uint16, error) {
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+
+	endCodes := 14
+	startCodes := endCodes + segCount*2 + 2
+	idDeltas := startCodes + segCount*2
+	idRangeOffsets := idDeltas + segCount*2
+
+	if len(sub) < idRangeOffsets+segCount*2 {
+		return nil, ErrInvalidFont
+	}
+
+	glyphs := make(map[rune]uint16)
+
+	for seg := 0; seg < segCount; seg++ {
+		end := int(binary.BigEndian.Uint16(sub[endCodes+seg*2:]))
+		start := int(binary.BigEndian.Uint16(sub[startCodes+seg*2:]))
+		delta := binary.BigEndian.Uint16(sub[idDeltas+seg*2:])
+		rangeOffsetPos := idRangeOffsets + seg*2
+		rangeOffset := int(binary.BigEndian.Uint16(sub[rangeOffsetPos:]))
+
+		for code := start; code <= end && code != 0xFFFF; code++ {
+			var glyph uint16
+
+			if rangeOffset == 0 {
+				glyph = uint16(code) + delta
+			} else {
+				pos := rangeOffsetPos + rangeOffset + (code-start)*2
+				if pos+2 > len(sub) {
+					return nil, ErrInvalidFont
+				}
+
+				glyph = binary.BigEndian.Uint16(sub[pos:])
+				if glyph != 0 {
+					glyph += delta
+				}
+			}
+
+			if glyph != 0 {
+				glyphs[rune(code)] = glyph
+			}
+		}
+	}
+
+	return glyphs, nil
+}
+
+// glyph - глиф символа, символы вне шрифта рисуются глифом .notdef
+func (f *ttfFont) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// textWidth - ширина строки в пунктах при кегле size
+func (f *ttfFont) textWidth(text string, size float64) float64 {
+	var width int
+
+	for _, r := range text {
+		if g := int(f.glyph(r)); g < len(f.advances) {
+			width += f.advances[g]
+		}
+	}
+
+	return float64(width) * size / 1000
+}
+
+// Флаги составного глифа, по которым считается длина записи компонента
+const (
+	ttfArgsAreWords    = 0x0001
+	ttfHaveScale       = 0x0008
+	ttfMoreComponents  = 0x0020
+	ttfHaveXYScale     = 0x0040
+	ttfHaveTwoByTwo    = 0x0080
+	ttfHeadChecksumAdj = 0xB1B0AFBA
+)
+
+// subsetTables - таблицы, которые остаются во встраиваемом шрифте
+var subsetTables = []string{"OS/2", "cmap", "cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// subset - копия шрифта, в которой остаются контуры только переданных глифов. Номера глифов
+// не меняются, поэтому CIDToGIDMap остаётся Identity, а размер документа падает в десятки раз
+func (f *ttfFont) subset(glyphs []uint16) ([]byte, error) {
+	tables, err := f.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	glyf, hasGlyf := tables["glyf"]
+	loca, hasLoca := tables["loca"]
+
+	if !hasGlyf || !hasLoca {
+		return nil, ErrInvalidFont
+	}
+
+	head := append([]byte(nil), tables["head"]...)
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+
+	glyphRange := func(g int) (int, int, bool) {
+		if longLoca {
+			if (g+2)*4 > len(loca) {
+				return 0, 0, false
+			}
+
+			return int(binary.BigEndian.Uint32(loca[g*4:])), int(binary.BigEndian.Uint32(loca[g*4+4:])), true
+		}
+
+		if (g+2)*2 > len(loca) {
+			return 0, 0, false
+		}
+
+		return int(binary.BigEndian.Uint16(loca[g*2:])) * 2, int(binary.BigEndian.Uint16(loca[g*2+2:])) * 2, true
+	}
+
+	keep := make(map[int]bool)
+	queue := make([]int, 0, len(glyphs))
+
+	for _, g := range glyphs {
+		queue = append(queue, int(g))
+	}
+
+	// составные глифы ссылаются на другие глифы, их контуры тоже нужны
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+
+		if keep[g] || g >= len(f.advances) {
+			continue
+		}
+
+		keep[g] = true
+
+		start, end, ok := glyphRange(g)
+		if !ok || start > end || end > len(glyf) {
+			return nil, ErrInvalidFont
+		}
+
+		if end-start < 10 || int16(binary.BigEndian.Uint16(glyf[start:])) >= 0 {
+			continue
+		}
+
+		for pos := start + 10; pos+4 <= end; {
+			flags := binary.BigEndian.Uint16(glyf[pos:])
+			queue = append(queue, int(binary.BigEndian.Uint16(glyf[pos+2:])))
+
+			pos += 4
+
+			if flags&ttfArgsAreWords != 0 {
+				pos += 4
+			} else {
+				pos += 2
+			}
+
+			switch {
+			case flags&ttfHaveScale != 0:
+				pos += 2
+			case flags&ttfHaveXYScale != 0:
+				pos += 4
+			case flags&ttfHaveTwoByTwo != 0:
+				pos += 8
+			}
+
+			if flags&ttfMoreComponents == 0 {
+				break
+			}
+		}
+	}
+
+	newGlyf := make([]byte, 0)
+	newLoca := make([]byte, 0, (len(f.advances)+1)*4)
+
+	for g := 0; g < len(f.advances); g++ {
+		newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+
+		if !keep[g] {
+			continue
+		}
+
+		start, end, _ := glyphRange(g)
+		newGlyf = append(newGlyf, glyf[start:end]...)
+
+		for len(newGlyf)%4 != 0 {
+			newGlyf = append(newGlyf, 0)
+		}
+	}
+
+	newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+
+	binary.BigEndian.PutUint16(head[50:], 1)
+	binary.BigEndian.PutUint32(head[8:], 0)
+
+	tables["head"] = head
+	tables["glyf"] = newGlyf
+	tables["loca"] = newLoca
+
+	return buildTTF(tables), nil
+}
+
+// tables - содержимое таблиц шрифта из subsetTables
+func (f *ttfFont) tables() (map[string][]byte, error) {
+	numTables := int(binary.BigEndian.Uint16(f.data[4:]))
+	tables := make(map[string][]byte, len(subsetTables))
+
+	for i := 0; i < numTables; i++ {
+		rec := f.data[12+i*16:]
+		tag := string(rec[:4])
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+
+		for _, keep := range subsetTables {
+			if tag == keep {
+				tables[tag] = f.data[offset : offset+length]
+			}
+		}
+	}
+
+	if len(tables["head"]) < 54 {
+		return nil, ErrInvalidFont
+	}
+
+	return tables, nil
+}
+
+// buildTTF - собирает файл шрифта из таблиц с заголовком, контрольными суммами и выравниванием
+func buildTTF(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for _, tag := range subsetTables {
+		if _, ok := tables[tag]; ok {
+			tags = append(tags, tag)
+		}
+	}
+
+	numTables := len(tags)
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+
+	searchRange := (1 << entrySelector) * 16
+
+	font := make([]byte, 0)
+	font = binary.BigEndian.AppendUint32(font, 0x00010000)
+	font = binary.BigEndian.AppendUint16(font, uint16(numTables))
+	font = binary.BigEndian.AppendUint16(font, uint16(searchRange))
+	font = binary.BigEndian.AppendUint16(font, uint16(entrySelector))
+	font = binary.BigEndian.AppendUint16(font, uint16(numTables*16-searchRange))
+
+	offset := 12 + numTables*16
+	headOffset := 0
+
+	var body []byte
+
+	for _, tag := range tags {
+		data := tables[tag]
+
+		if tag == "head" {
+			headOffset = offset
+		}
+
+		font = append(font, tag...)
+		font = binary.BigEndian.AppendUint32(font, ttfChecksum(data))
+		font = binary.BigEndian.AppendUint32(font, uint32(offset))
+		font = binary.BigEndian.AppendUint32(font, uint32(len(data)))
+
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+
+		offset = 12 + numTables*16 + len(body)
+	}
+
+	font = append(font, body...)
+
+	binary.BigEndian.PutUint32(font[headOffset+8:], ttfHeadChecksumAdj-ttfChecksum(font))
+
+	return font
+}
+
+func ttfChecksum(data []byte) uint32 {
+	var sum uint32
+
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+
+	return sum
+}
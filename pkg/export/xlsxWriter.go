@@ -0,0 +1,172 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Минимальный набор частей книги Office Open XML: одна страница и стиль для жирной шапки
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	xlsxSheetFooter = `</sheetData></worksheet>`
+
+	// максимальная длина имени листа в Excel
+	xlsxSheetNameLen = 31
+)
+
+// WriteXLSX - пишет таблицу в книгу XLSX из одного листа. Столбцы из Table.Numeric пишутся
+// числовыми ячейками, чтобы по ним работали формулы, шапка и итоги - жирным
+func WriteXLSX(w io.Writer, t Table) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "%s", escapeXML(sheetName(t.Sheet)), 1)},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", sheetXML(t)},
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(f, part.body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func sheetXML(t Table) string {
+	var sb strings.Builder
+
+	sb.WriteString(xlsxSheetHeader)
+
+	rowNum := 1
+
+	writeRow := func(cells []string, bold, header bool) {
+		sb.WriteString(`<row r="` + strconv.Itoa(rowNum) + `">`)
+
+		for i, value := range cells {
+			ref := columnName(i) + strconv.Itoa(rowNum)
+
+			style := ""
+			if bold {
+				style = ` s="1"`
+			}
+
+			if !header && t.isNumeric(i) && value != "" {
+				sb.WriteString(`<c r="` + ref + `"` + style + `><v>` + escapeXML(value) + `</v></c>`)
+				continue
+			}
+
+			sb.WriteString(`<c r="` + ref + `" t="inlineStr"` + style + `><is><t>` + escapeXML(value) + `</t></is></c>`)
+		}
+
+		sb.WriteString(`</row>`)
+		rowNum++
+	}
+
+	writeRow(t.Header, true, true)
+
+	for _, row := range t.Rows {
+		writeRow(row, false, false)
+	}
+
+	if t.Totals != nil {
+		writeRow(t.Totals, true, false)
+	}
+
+	sb.WriteString(xlsxSheetFooter)
+
+	return sb.String()
+}
+
+// columnName - буквенное имя столбца: 0 -> A, 25 -> Z, 26 -> AA
+func columnName(i int) string {
+	name := ""
+
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
+
+// sheetName - Excel не принимает в имени листа []:*?/\ и имена длиннее 31 символа
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+
+		return r
+	}, name)
+
+	if name == "" {
+		return "Sheet1"
+	}
+
+	if runes := []rune(name); len(runes) > xlsxSheetNameLen {
+		return string(runes[:xlsxSheetNameLen])
+	}
+
+	return name
+}
+
+func escapeXML(value string) string {
+	var sb strings.Builder
+
+	_ = xml.EscapeText(&sb, []byte(value))
+
+	return sb.String()
+}
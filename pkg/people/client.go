@@ -0,0 +1,218 @@
+package people
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetries          = 2
+	defaultBackoff          = 100 * time.Millisecond
+	defaultMaxBackoff       = 2 * time.Second
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+var (
+	ErrInvalidConfig   = errors.New("invalid people api config")
+	ErrBadRequest      = errors.New("people api rejected the request")
+	ErrUnavailable     = errors.New("people api is unavailable")
+	ErrInvalidResponse = errors.New("people api returned invalid response")
+	ErrCircuitOpen     = errors.New("people api circuit is open")
+)
+
+// Person - данные человека из People API, surname, name и address обязательны по mockAPI.yaml
+type Person struct {
+	Surname    string `json:"surname"`
+	Name       string `json:"name"`
+	Patronymic string `json:"patronymic"`
+	Address    string `json:"address"`
+}
+
+// Config - настройки клиента. MaxRetries - сколько раз повторить запрос после первой неудачи,
+// пауза между повторами растёт от Backoff вдвое до MaxBackoff. После FailureThreshold неудачных
+// запросов подряд клиент OpenTimeout не ходит в API и сразу отвечает ErrCircuitOpen
+type Config struct {
+	BaseURL          string
+	MaxRetries       int
+	Backoff          time.Duration
+	MaxBackoff       time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// ParseConfig - разбирает настройки клиента из конфига: число повторов, начальную паузу
+// и порог и время размыкания в формате time.ParseDuration. Пустые значения - по умолчанию
+func ParseConfig(baseURL, retries, backoff, threshold, openTimeout string) (Config, error) {
+	cfg := Config{
+		BaseURL:          baseURL,
+		MaxRetries:       defaultRetries,
+		Backoff:          defaultBackoff,
+		MaxBackoff:       defaultMaxBackoff,
+		FailureThreshold: defaultFailureThreshold,
+		OpenTimeout:      defaultOpenTimeout,
+	}
+
+	if retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return Config{}, fmt.Errorf("%w: retries %q", ErrInvalidConfig, retries)
+		}
+
+		cfg.MaxRetries = n
+	}
+
+	if backoff != "" {
+		d, err := time.ParseDuration(backoff)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("%w: backoff %q", ErrInvalidConfig, backoff)
+		}
+
+		cfg.Backoff = d
+	}
+
+	if threshold != "" {
+		n, err := strconv.Atoi(threshold)
+		if err != nil || n < 1 {
+			return Config{}, fmt.Errorf("%w: breaker threshold %q", ErrInvalidConfig, threshold)
+		}
+
+		cfg.FailureThreshold = n
+	}
+
+	if openTimeout != "" {
+		d, err := time.ParseDuration(openTimeout)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("%w: breaker timeout %q", ErrInvalidConfig, openTimeout)
+		}
+
+		cfg.OpenTimeout = d
+	}
+
+	return cfg, nil
+}
+
+// Client - клиент People API с повторами временных ошибок и размыканием цепи, пока API лежит
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *breaker
+}
+
+// NewClient - таймаут одной попытки задаётся в httpClient, общий срок - контекстом запроса
+func NewClient(cfg Config, httpClient *http.Client) *Client {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &Client{
+		cfg:     cfg,
+		http:    httpClient,
+		breaker: newBreaker(cfg.FailureThreshold, cfg.OpenTimeout),
+	}
+}
+
+// Info - данные человека по серии и номеру паспорта. 4xx от API не повторяются, 5xx, 429
+// и сетевые ошибки повторяются до MaxRetries раз
+func (c *Client) Info(ctx context.Context, passportSerie, passportNumber string) (Person, error) {
+	if !c.breaker.allow() {
+		return Person{}, ErrCircuitOpen
+	}
+
+	person, err := c.infoWithRetries(ctx, passportSerie, passportNumber)
+
+	switch {
+	case err == nil:
+		c.breaker.success()
+	case errors.Is(err, ErrUnavailable), errors.Is(err, ErrInvalidResponse):
+		c.breaker.failure()
+	default:
+		// API ответил осмысленно или запрос отменил вызывающий - о здоровье API это ничего не говорит
+		c.breaker.release()
+	}
+
+	return person, err
+}
+
+func (c *Client) infoWithRetries(ctx context.Context, passportSerie, passportNumber string) (Person, error) {
+	for attempt := 0; ; attempt++ {
+		person, err := c.fetch(ctx, passportSerie, passportNumber)
+		if err == nil || !errors.Is(err, ErrUnavailable) || attempt >= c.cfg.MaxRetries {
+			return person, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Person{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff - пауза перед повтором: Backoff * 2^attempt, не больше MaxBackoff, со случайным
+// разбросом в верхней половине, чтобы клиенты не повторяли запросы одновременно
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.Backoff << attempt
+	if d <= 0 || (c.cfg.MaxBackoff > 0 && d > c.cfg.MaxBackoff) {
+		d = c.cfg.MaxBackoff
+	}
+
+	half := int64(d / 2)
+
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+func (c *Client) fetch(ctx context.Context, passportSerie, passportNumber string) (Person, error) {
+	query := url.Values{}
+	query.Set("passportSerie", passportSerie)
+	query.Set("passportNumber", passportNumber)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.BaseURL+"/info?"+query.Encode(), nil)
+	if err != nil {
+		return Person{}, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Person{}, ctx.Err()
+		}
+
+		return Person{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= http.StatusInternalServerError:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return Person{}, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	default:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return Person{}, fmt.Errorf("%w: status %d", ErrBadRequest, resp.StatusCode)
+	}
+
+	var person Person
+
+	err = json.NewDecoder(resp.Body).Decode(&person)
+	if err != nil {
+		return Person{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	if person.Surname == "" || person.Name == "" || person.Address == "" {
+		return Person{}, fmt.Errorf("%w: surname, name and address are required", ErrInvalidResponse)
+	}
+
+	return person, nil
+}
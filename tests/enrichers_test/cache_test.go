@@ -0,0 +1,251 @@
+package enrichers_test
+
+import (
+	"EMTask/internal/enrichers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/pkg/people"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// fakeCacheStore - таблица кэша в памяти
+type fakeCacheStore struct {
+	entries map[string]models.EnrichmentCacheEntry
+	err     error
+	saves   int
+}
+
+func (fs *fakeCacheStore) FindEnrichmentCache(_ context.Context, hash string) (models.EnrichmentCacheEntry, error) {
+	if fs.err != nil {
+		return models.EnrichmentCacheEntry{}, fs.err
+	}
+
+	entry, ok := fs.entries[hash]
+	if !ok || !time.Now().Before(entry.ExpiresAt) {
+		return models.EnrichmentCacheEntry{}, repos.ErrEnrichmentCacheMiss
+	}
+
+	return entry, nil
+}
+
+func (fs *fakeCacheStore) SaveEnrichmentCache(_ context.Context, entry models.EnrichmentCacheEntry) error {
+	fs.saves++
+
+	if fs.err != nil {
+		return fs.err
+	}
+
+	fs.entries[entry.PassportHash] = entry
+
+	return nil
+}
+
+func (fs *fakeCacheStore) DeleteExpiredEnrichmentCache(_ context.Context) (int64, error) {
+	return 0, fs.err
+}
+
+var cacheSecret = []byte("0123456789abcdef")
+
+func cacheConfig() enrichers.CacheConfig {
+	return enrichers.CacheConfig{Size: 10, TTL: time.Hour, NegativeTTL: time.Hour, Secret: cacheSecret}
+}
+
+func TestCachedEnricher(t *testing.T) {
+	next := &stubEnricher{person: mockPerson}
+	cache := enrichers.NewCachedEnricher(next, nil, cacheConfig())
+
+	for range 3 {
+		person, err := cache.Enrich(context.Background(), "1234 567890")
+		assert.NoError(t, err)
+		assert.Equal(t, mockPerson, person)
+	}
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, models.EnrichmentCacheStats{Hits: 2, Misses: 1, HitRatio: 2.0 / 3, Size: 1}, cache.Stats())
+}
+
+func TestCachedEnricherNegative(t *testing.T) {
+	next := &stubEnricher{err: enrichers.ErrPersonNotFound}
+	cache := enrichers.NewCachedEnricher(next, nil, cacheConfig())
+
+	for range 2 {
+		_, err := cache.Enrich(context.Background(), "1234 567890")
+		assert.ErrorIs(t, err, enrichers.ErrPersonNotFound)
+	}
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, int64(1), cache.Stats().NegativeHits)
+
+	unavailable := &stubEnricher{err: people.ErrUnavailable}
+	cache = enrichers.NewCachedEnricher(unavailable, nil, cacheConfig())
+
+	for range 2 {
+		_, err := cache.Enrich(context.Background(), "1234 567890")
+		assert.ErrorIs(t, err, people.ErrUnavailable)
+	}
+
+	assert.Equal(t, 2, unavailable.calls, "temporary errors must not be cached")
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestCachedEnricherExpiry(t *testing.T) {
+	next := &stubEnricher{person: mockPerson}
+
+	cfg := cacheConfig()
+	cfg.TTL = 20 * time.Millisecond
+
+	cache := enrichers.NewCachedEnricher(next, nil, cfg)
+
+	_, err := cache.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+
+	time.Sleep(cfg.TTL)
+
+	_, err = cache.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestCachedEnricherEviction(t *testing.T) {
+	next := &stubEnricher{person: mockPerson}
+
+	cfg := cacheConfig()
+	cfg.Size = 2
+
+	cache := enrichers.NewCachedEnricher(next, nil, cfg)
+
+	for _, passport := range []string{"1111 000001", "2222 000002", "1111 000001", "3333 000003"} {
+		_, err := cache.Enrich(context.Background(), passport)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, next.calls)
+
+	// 2222 000002 запрашивали давнее всех, он и вытеснен
+	_, err := cache.Enrich(context.Background(), "1111 000001")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, next.calls)
+
+	_, err = cache.Enrich(context.Background(), "2222 000002")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, next.calls)
+	assert.Equal(t, 2, cache.Stats().Size)
+}
+
+func TestCachedEnricherStore(t *testing.T) {
+	store := &fakeCacheStore{entries: map[string]models.EnrichmentCacheEntry{}}
+	next := &stubEnricher{person: mockPerson}
+
+	_, err := enrichers.NewCachedEnricher(next, store, cacheConfig()).Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, store.saves)
+
+	// новая копия сервиса с пустой памятью берёт ответ из таблицы
+	cache := enrichers.NewCachedEnricher(next, store, cacheConfig())
+
+	person, err := cache.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, mockPerson, person)
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, int64(1), cache.Stats().Hits)
+	assert.Equal(t, mockPerson, store.entries[enrichers.PassportHash(cacheSecret, "1234 567890")].Person)
+}
+
+func TestCachedEnricherStoreError(t *testing.T) {
+	store := &fakeCacheStore{entries: map[string]models.EnrichmentCacheEntry{}, err: errors.New("Эта ошибка ломает базу")}
+	next := &stubEnricher{person: mockPerson}
+	cache := enrichers.NewCachedEnricher(next, store, cacheConfig())
+
+	person, err := cache.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err, "cache table failures must not break enrichment")
+	assert.Equal(t, mockPerson, person)
+	assert.Equal(t, int64(2), cache.Stats().StoreErrors)
+
+	_, err = cache.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, next.calls, "memory cache still works")
+}
+
+func TestPassportHash(t *testing.T) {
+	hash := enrichers.PassportHash(cacheSecret, "1234 567890")
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, enrichers.PassportHash(cacheSecret, " 1234  567890 "))
+	assert.Equal(t, hash, enrichers.PassportHash(cacheSecret, "1234567890"))
+	assert.NotEqual(t, hash, enrichers.PassportHash(cacheSecret, "1234 567891"))
+	assert.NotEqual(t, hash, enrichers.PassportHash([]byte("fedcba9876543210"), "1234 567890"))
+	assert.NotContains(t, hash, "567890")
+
+	// ключ не совпадает с sha256 номера, который перебирается без секрета
+	unsalted := sha256.Sum256([]byte("1234567890"))
+	assert.NotEqual(t, hex.EncodeToString(unsalted[:]), hash)
+}
+
+func TestParseCacheConfig(t *testing.T) {
+	testCases := []struct {
+		id          int
+		name        string
+		size        string
+		ttl         string
+		negativeTTL string
+		postgres    string
+		secret      string
+		expected    enrichers.CacheConfig
+		expectedErr error
+	}{
+		{
+			id:       1,
+			name:     "Defaults",
+			expected: enrichers.CacheConfig{Size: 1000, TTL: 24 * time.Hour, NegativeTTL: time.Hour},
+		},
+		{
+			id:          2,
+			name:        "Custom",
+			size:        "50",
+			ttl:         "10m",
+			negativeTTL: "0s",
+			postgres:    "true",
+			secret:      "0123456789abcdef",
+			expected:    enrichers.CacheConfig{Size: 50, TTL: 10 * time.Minute, Postgres: true, Secret: cacheSecret},
+		},
+		{id: 3, name: "Zero size", size: "0", expectedErr: enrichers.ErrInvalidEnricherConfig},
+		{id: 4, name: "Invalid ttl", ttl: "сутки", expectedErr: enrichers.ErrInvalidEnricherConfig},
+		{id: 5, name: "Negative ttl", negativeTTL: "-1m", expectedErr: enrichers.ErrInvalidEnricherConfig},
+		{id: 6, name: "Invalid postgres flag", postgres: "может быть", expectedErr: enrichers.ErrInvalidEnricherConfig},
+		{id: 7, name: "Postgres without secret", postgres: "true", expectedErr: enrichers.ErrInvalidEnricherConfig},
+		{id: 8, name: "Short secret", secret: "secret", expectedErr: enrichers.ErrInvalidEnricherConfig},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := enrichers.ParseCacheConfig(tc.size, tc.ttl, tc.negativeTTL, tc.postgres, tc.secret)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+
+			// без секрета в конфиге ключ случайный
+			if tc.expectedErr == nil && tc.secret == "" {
+				assert.Len(t, cfg.Secret, 32)
+				cfg.Secret = nil
+			}
+
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+
+	first, err := enrichers.ParseCacheConfig("", "", "", "", "")
+	assert.NoError(t, err)
+
+	second, err := enrichers.ParseCacheConfig("", "", "", "", "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Secret, second.Secret)
+
+	cfg, err := enrichers.ParseCacheConfig("", "0s", "", "true", "")
+	assert.NoError(t, err, "disabled cache does not need a secret")
+	assert.False(t, cfg.Enabled())
+}
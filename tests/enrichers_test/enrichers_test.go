@@ -0,0 +1,296 @@
+package enrichers_test
+
+import (
+	"EMTask/internal/enrichers"
+	"EMTask/internal/models"
+	"EMTask/pkg/people"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var mockPerson = models.APIResponse{
+	Surname:    "Иванов",
+	Name:       "Иван",
+	Patronymic: "Иванович",
+	Address:    "г. Москва, ул. Ленина, д. 5, кв. 1",
+}
+
+const mockPeopleCSV = "passport_number,surname,name,patronymic,address\n" +
+	"1234 567890,Иванов,Иван,Иванович,\"г. Москва, ул. Ленина, д. 5, кв. 1\"\n"
+
+// stubEnricher - провайдер с заранее заданным ответом, считает обращения
+type stubEnricher struct {
+	person models.APIResponse
+	err    error
+	calls  int
+}
+
+func (se *stubEnricher) Enrich(context.Context, string) (models.APIResponse, error) {
+	se.calls++
+	return se.person, se.err
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func peopleClient(t *testing.T, status int, body string) *people.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return people.NewClient(people.Config{
+		BaseURL:          server.URL,
+		Backoff:          time.Millisecond,
+		MaxBackoff:       time.Millisecond,
+		FailureThreshold: 5,
+		OpenTimeout:      time.Second,
+	}, server.Client())
+}
+
+func TestAPIEnricher(t *testing.T) {
+	testCases := []struct {
+		id          int
+		name        string
+		passport    string
+		status      int
+		body        string
+		expected    models.APIResponse
+		expectedErr error
+	}{
+		{
+			id:       1,
+			name:     "Success",
+			passport: "1234 567890",
+			status:   http.StatusOK,
+			body:     `{"surname":"Иванов","name":"Иван","patronymic":"Иванович","address":"г. Москва, ул. Ленина, д. 5, кв. 1"}`,
+			expected: mockPerson,
+		},
+		{
+			id:          2,
+			name:        "Bad request means not found",
+			passport:    "1234 567890",
+			status:      http.StatusBadRequest,
+			expectedErr: enrichers.ErrPersonNotFound,
+		},
+		{
+			id:          3,
+			name:        "Server error is temporary",
+			passport:    "1234 567890",
+			status:      http.StatusInternalServerError,
+			expectedErr: people.ErrUnavailable,
+		},
+		{
+			id:          4,
+			name:        "Invalid passport",
+			passport:    "1234567890",
+			status:      http.StatusOK,
+			expectedErr: enrichers.ErrPersonNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enricher := enrichers.NewAPIEnricher(peopleClient(t, tc.status, tc.body))
+
+			person, err := enricher.Enrich(context.Background(), tc.passport)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, person)
+		})
+	}
+}
+
+func TestFileEnricher(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "a.csv", mockPeopleCSV)
+	writeFile(t, dir, "b.csv", "\xEF\xBB\xBFPassport_Number,Surname,Name,Address\n2234 567890,Петров,Пётр,г. Тверь\n")
+	writeFile(t, dir, "readme.txt", "не CSV")
+
+	enricher, err := enrichers.NewFileEnricher(dir)
+	if err != nil {
+		t.Fatalf("NewFileEnricher Error: %s", err)
+	}
+
+	person, err := enricher.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, mockPerson, person)
+
+	person, err = enricher.Enrich(context.Background(), "2234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, models.APIResponse{Surname: "Петров", Name: "Пётр", Address: "г. Тверь"}, person)
+
+	_, err = enricher.Enrich(context.Background(), "9999 000000")
+	assert.ErrorIs(t, err, enrichers.ErrPersonNotFound)
+
+	single, err := enrichers.NewFileEnricher(filepath.Join(dir, "a.csv"))
+	if err != nil {
+		t.Fatalf("NewFileEnricher Error: %s", err)
+	}
+
+	_, err = single.Enrich(context.Background(), "2234 567890")
+	assert.ErrorIs(t, err, enrichers.ErrPersonNotFound)
+}
+
+func TestFileEnricherInvalid(t *testing.T) {
+	testCases := []struct {
+		id      int
+		name    string
+		content string
+	}{
+		{id: 1, name: "No address column", content: "passport_number,surname,name\n1234 567890,Иванов,Иван\n"},
+		{id: 2, name: "Duplicate passport", content: mockPeopleCSV + "1234 567890,Петров,Пётр,,г. Тверь\n"},
+		{id: 3, name: "Empty passport", content: mockPeopleCSV + ",Петров,Пётр,,г. Тверь\n"},
+		{id: 4, name: "Empty file", content: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "people.csv", tc.content)
+
+			_, err := enrichers.NewFileEnricher(path)
+			assert.ErrorIs(t, err, enrichers.ErrInvalidEnricherConfig)
+		})
+	}
+
+	_, err := enrichers.NewFileEnricher(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.ErrorIs(t, err, enrichers.ErrInvalidEnricherConfig)
+}
+
+func TestChain(t *testing.T) {
+	notFound := func() *stubEnricher { return &stubEnricher{err: enrichers.ErrPersonNotFound} }
+	unavailable := func() *stubEnricher { return &stubEnricher{err: people.ErrUnavailable} }
+	found := func() *stubEnricher { return &stubEnricher{person: mockPerson} }
+
+	testCases := []struct {
+		id            int
+		name          string
+		providers     []*stubEnricher
+		expected      models.APIResponse
+		expectedErr   error
+		expectedCalls []int
+	}{
+		{
+			id:            1,
+			name:          "First provider wins",
+			providers:     []*stubEnricher{found(), found()},
+			expected:      mockPerson,
+			expectedCalls: []int{1, 0},
+		},
+		{
+			id:            2,
+			name:          "Falls through not found",
+			providers:     []*stubEnricher{notFound(), found()},
+			expected:      mockPerson,
+			expectedCalls: []int{1, 1},
+		},
+		{
+			id:            3,
+			name:          "Falls through unavailable",
+			providers:     []*stubEnricher{unavailable(), found()},
+			expected:      mockPerson,
+			expectedCalls: []int{1, 1},
+		},
+		{
+			id:            4,
+			name:          "Nobody knows the passport",
+			providers:     []*stubEnricher{notFound(), notFound()},
+			expectedErr:   enrichers.ErrPersonNotFound,
+			expectedCalls: []int{1, 1},
+		},
+		{
+			id:            5,
+			name:          "Unavailable provider keeps the error temporary",
+			providers:     []*stubEnricher{notFound(), unavailable()},
+			expectedErr:   people.ErrUnavailable,
+			expectedCalls: []int{1, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var chain enrichers.Chain
+
+			for _, provider := range tc.providers {
+				chain = append(chain, provider)
+			}
+
+			person, err := chain.Enrich(context.Background(), "1234 567890")
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, person)
+
+			if tc.id == 5 {
+				assert.False(t, errors.Is(err, enrichers.ErrPersonNotFound), "temporary error must not look permanent")
+			}
+
+			for i, provider := range tc.providers {
+				assert.Equal(t, tc.expectedCalls[i], provider.calls)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "people.csv", mockPeopleCSV)
+	client := peopleClient(t, http.StatusBadRequest, "")
+
+	testCases := []struct {
+		id          int
+		name        string
+		providers   string
+		filePath    string
+		expectedErr error
+	}{
+		{id: 1, name: "Default is api", providers: ""},
+		{id: 2, name: "File", providers: "file", filePath: path},
+		{id: 3, name: "Chain", providers: "file, API", filePath: path},
+		{id: 4, name: "Unknown provider", providers: "ldap", expectedErr: enrichers.ErrInvalidEnricherConfig},
+		{id: 5, name: "File without path", providers: "file", expectedErr: enrichers.ErrInvalidEnricherConfig},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enricher, err := enrichers.New(tc.providers, tc.filePath, client)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+
+			if tc.expectedErr != nil {
+				assert.Nil(t, enricher)
+				return
+			}
+
+			_, err = enricher.Enrich(context.Background(), "9999 000000")
+			assert.ErrorIs(t, err, enrichers.ErrPersonNotFound)
+		})
+	}
+
+	chain, err := enrichers.New("file,api", path, client)
+	assert.NoError(t, err)
+	assert.IsType(t, enrichers.Chain{}, chain)
+
+	person, err := chain.Enrich(context.Background(), "1234 567890")
+	assert.NoError(t, err)
+	assert.Equal(t, mockPerson, person)
+}
@@ -0,0 +1,247 @@
+package export_test
+
+import (
+	"EMTask/pkg/export"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var mockTable = export.Table{
+	Sheet:  "Трудозатраты: июль",
+	Header: []string{"Сотрудник", "Задача", "Время", "Часы"},
+	Rows: [][]string{
+		{"Иванов Иван", "задача с \"кавычками\", запятой & <тегом>", "2 ч 30 мин", "2.50"},
+		{"Петров Пётр", "Inf", "0 мин", "0.00"},
+		{"@Сидоров", "=HYPERLINK(\"http://evil\")", "007", "-1.00"},
+	},
+	Totals:  []string{"Итого", "", "2 ч 30 мин", "2.50"},
+	Numeric: []bool{false, false, false, true},
+}
+
+func TestFormatDuration(t *testing.T) {
+	testCases := []struct {
+		id       int
+		name     string
+		seconds  int64
+		expected string
+		hours    string
+	}{
+		{id: 1, name: "Zero", seconds: 0, expected: "0 мин", hours: "0.00"},
+		{id: 2, name: "Minutes only", seconds: 150, expected: "2 мин", hours: "0.04"},
+		{id: 3, name: "Hours and minutes", seconds: 9000, expected: "2 ч 30 мин", hours: "2.50"},
+		{id: 4, name: "Whole hours", seconds: 36000, expected: "10 ч 00 мин", hours: "10.00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, export.FormatDuration(tc.seconds))
+			assert.Equal(t, tc.hours, export.FormatHours(tc.seconds))
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := export.WriteCSV(&buf, mockTable)
+	if err != nil {
+		t.Fatalf("WriteCSV Error: %s", err)
+	}
+
+	expected := "\xEF\xBB\xBF" +
+		"Сотрудник,Задача,Время,Часы\n" +
+		"Иванов Иван,\"задача с \"\"кавычками\"\", запятой & <тегом>\",2 ч 30 мин,2.50\n" +
+		"Петров Пётр,Inf,0 мин,0.00\n" +
+		"'@Сидоров,\"'=HYPERLINK(\"\"http://evil\"\")\",007,-1.00\n" +
+		"Итого,,2 ч 30 мин,2.50\n"
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := export.WriteXLSX(&buf, mockTable)
+	if err != nil {
+		t.Fatalf("WriteXLSX Error: %s", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader Error: %s", err)
+	}
+
+	parts := make(map[string]string)
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rc.Close()
+
+		parts[f.Name] = string(body)
+	}
+
+	for _, name := range []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/_rels/workbook.xml.rels",
+		"xl/workbook.xml",
+		"xl/styles.xml",
+		"xl/worksheets/sheet1.xml",
+	} {
+		assert.Contains(t, parts, name)
+	}
+
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Трудозатраты_ июль"`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr" s="1"><is><t>Сотрудник</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="D2"><v>2.50</v></c>`)
+	assert.Contains(t, sheet, "задача с &#34;кавычками&#34;, запятой &amp; &lt;тегом&gt;")
+	assert.Contains(t, sheet, `<c r="D5" s="1"><v>2.50</v></c>`)
+	assert.Contains(t, sheet, `<c r="B3" t="inlineStr"><is><t>Inf</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A4" t="inlineStr"><is><t>@Сидоров</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B4" t="inlineStr"><is><t>=HYPERLINK(&#34;http://evil&#34;)</t></is></c>`)
+	assert.NotContains(t, sheet, "&#39;")
+	assert.Contains(t, sheet, `<c r="C4" t="inlineStr"><is><t>007</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="D4"><v>-1.00</v></c>`)
+	assert.Contains(t, sheet, `<c r="B5" t="inlineStr" s="1"><is><t></t></is></c>`)
+	assert.Equal(t, 5, strings.Count(sheet, "<row "))
+}
+
+func TestWritePDF(t *testing.T) {
+	table := export.Table{Header: []string{"Дата", "Задача", "Время", "Часы"}}
+
+	for i := 0; i < 80; i++ {
+		table.Rows = append(table.Rows, []string{"2026-10-01", "написать тестовое", "2 ч 30 мин", "2.50"})
+	}
+
+	table.Totals = []string{"Итого", "", "200 ч 00 мин", "200.00"}
+
+	doc := export.Document{
+		Title:      "Табель учёта рабочего времени",
+		Fields:     []export.Field{{Label: "Сотрудник", Value: "Иванов Иван Иванович"}},
+		Table:      table,
+		Widths:     []float64{1.3, 5, 1.5, 1},
+		Signatures: []string{"Исполнитель", "Заказчик"},
+	}
+
+	var buf bytes.Buffer
+
+	err := export.WritePDF(&buf, doc)
+	if err != nil {
+		t.Fatalf("WritePDF Error: %s", err)
+	}
+
+	pdf := buf.String()
+
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+
+	// 80 строк не помещаются на одну страницу A4
+	assert.Contains(t, pdf, "/Count 2 ")
+	assert.Equal(t, 2, strings.Count(pdf, "/Type /Page "))
+
+	// шрифт встраивается только использованными глифами
+	assert.Less(t, buf.Len(), 100*1024)
+
+	var startXref, size int
+
+	_, err = fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref"):], "startxref\n%d", &startXref)
+	if err != nil {
+		t.Fatalf("startxref Error: %s", err)
+	}
+
+	_, err = fmt.Sscanf(pdf[startXref:], "xref\n0 %d\n", &size)
+	if err != nil {
+		t.Fatalf("xref Error: %s", err)
+	}
+
+	entries := strings.Split(pdf[startXref:], "\n")[3 : 3+size-1]
+
+	for i, entry := range entries {
+		var offset int
+
+		_, err = fmt.Sscanf(entry, "%010d 00000 n", &offset)
+		if err != nil {
+			t.Fatalf("xref entry %d Error: %s", i+1, err)
+		}
+
+		assert.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d offset", i+1)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	start := time.Date(2026, 10, 12, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+
+	cal := export.Calendar{
+		Name:  "Трекинг времени",
+		Stamp: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC),
+		Events: []export.Event{
+			{
+				UID:         "entry-7@emtask",
+				Summary:     "созвон; ревью, правки",
+				Description: "строка 1\nстрока 2",
+				Start:       start,
+				End:         start.Add(90 * time.Minute),
+			},
+			{
+				UID:     "entry-8@emtask",
+				Summary: strings.Repeat("длинное название задачи ", 5),
+				Start:   start,
+				End:     start.Add(time.Hour),
+			},
+			{
+				UID:         "entry-9@emtask",
+				Summary:     "задача\rATTENDEE:mailto:evil@example.com",
+				Description: "звонок\x00\x1b[31m\tитог\u0085",
+				Start:       start,
+				End:         start.Add(time.Hour),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	err := export.WriteICS(&buf, cal)
+	if err != nil {
+		t.Fatalf("WriteICS Error: %s", err)
+	}
+
+	ics := buf.String()
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 3, strings.Count(ics, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, ics, "DTSTAMP:20261017T090000Z\r\nDTSTART:20261012T090000Z\r\nDTEND:20261012T103000Z\r\n")
+	assert.Contains(t, ics, `SUMMARY:созвон\; ревью\, правки`+"\r\n")
+	assert.Contains(t, ics, `DESCRIPTION:строка 1\nстрока 2`+"\r\n")
+	assert.Contains(t, ics, `SUMMARY:задача\nATTENDEE:mailto:evil@example.com`+"\r\n")
+	assert.Contains(t, ics, "DESCRIPTION:звонок[31m\tитог\r\n")
+	assert.NotContains(t, ics, "\rATTENDEE")
+
+	// строки не длиннее 75 байт, перенос не разрывает символы
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("длинное название задачи ", 5)+"\r\n")
+}
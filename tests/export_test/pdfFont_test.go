@@ -0,0 +1,219 @@
+package export_test
+
+import (
+	"EMTask/pkg/export"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var pdfStreamRe = regexp.MustCompile(`(?m)^(\d+) 0 obj\n<< /Length (\d+) [^\n]*>>\nstream\n`)
+
+var bfcharRe = regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`)
+
+// pdfStreams - распакованные потоки документа по номерам объектов
+func pdfStreams(t *testing.T, pdf []byte) map[int][]byte {
+	streams := make(map[int][]byte)
+
+	for _, m := range pdfStreamRe.FindAllSubmatchIndex(pdf, -1) {
+		num, _ := strconv.Atoi(string(pdf[m[2]:m[3]]))
+		length, _ := strconv.Atoi(string(pdf[m[4]:m[5]]))
+
+		zr, err := zlib.NewReader(bytes.NewReader(pdf[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("object %d zlib Error: %s", num, err)
+		}
+
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("object %d inflate Error: %s", num, err)
+		}
+
+		streams[num] = data
+	}
+
+	return streams
+}
+
+// testFont - встроенный шрифт, прочитанный независимо от pkg/export: таблицы, cmap и loca
+type testFont struct {
+	data   []byte
+	tables map[string][]byte
+}
+
+func parseTestFont(t *testing.T, data []byte) testFont {
+	if len(data) < 12 {
+		t.Fatalf("font is too short: %d bytes", len(data))
+	}
+
+	font := testFont{data: data, tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+
+	for i := 0; i < numTables; i++ {
+		rec := data[12+i*16:]
+		tag := string(rec[:4])
+		checksum := binary.BigEndian.Uint32(rec[4:])
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+
+		if offset+length > len(data) {
+			t.Fatalf("table %q is out of font bounds", tag)
+		}
+
+		font.tables[tag] = data[offset : offset+length]
+
+		// у head контрольная сумма считается с обнулённым checkSumAdjustment
+		table := append([]byte(nil), font.tables[tag]...)
+		if tag == "head" {
+			binary.BigEndian.PutUint32(table[8:], 0)
+		}
+
+		assert.Equal(t, checksum, testChecksum(table), "table %q checksum", tag)
+	}
+
+	assert.Equal(t, uint32(0xB1B0AFBA), testChecksum(data), "font checksum")
+
+	return font
+}
+
+func testChecksum(data []byte) uint32 {
+	var sum uint32
+
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+
+	return sum
+}
+
+// glyph - поиск символа в подтаблице cmap формата 4 по сегментам, как это делает просмотрщик
+func (f testFont) glyph(t *testing.T, r rune) uint16 {
+	cmap := f.tables["cmap"]
+
+	for i := 0; i < int(binary.BigEndian.Uint16(cmap[2:])); i++ {
+		rec := cmap[4+i*8:]
+		sub := cmap[binary.BigEndian.Uint32(rec[4:]):]
+
+		if binary.BigEndian.Uint16(sub) != 4 {
+			continue
+		}
+
+		segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+
+		for seg := 0; seg < segCount; seg++ {
+			end := rune(binary.BigEndian.Uint16(sub[14+seg*2:]))
+			start := rune(binary.BigEndian.Uint16(sub[16+segCount*2+seg*2:]))
+
+			if r < start || r > end {
+				continue
+			}
+
+			delta := binary.BigEndian.Uint16(sub[16+segCount*4+seg*2:])
+			rangeOffsetPos := 16 + segCount*6 + seg*2
+			rangeOffset := int(binary.BigEndian.Uint16(sub[rangeOffsetPos:]))
+
+			if rangeOffset == 0 {
+				return uint16(r) + delta
+			}
+
+			glyph := binary.BigEndian.Uint16(sub[rangeOffsetPos+rangeOffset+int(r-start)*2:])
+			if glyph == 0 {
+				return 0
+			}
+
+			return glyph + delta
+		}
+	}
+
+	t.Fatalf("no cmap format 4 entry for %q", r)
+
+	return 0
+}
+
+// outlineLen - длина контура глифа по loca, у выброшенных из подмножества глифов она нулевая
+func (f testFont) outlineLen(glyph uint16) int {
+	loca := f.tables["loca"]
+
+	if binary.BigEndian.Uint16(f.tables["head"][50:]) == 1 {
+		return int(binary.BigEndian.Uint32(loca[int(glyph)*4+4:])) - int(binary.BigEndian.Uint32(loca[int(glyph)*4:]))
+	}
+
+	return (int(binary.BigEndian.Uint16(loca[int(glyph)*2+2:])) - int(binary.BigEndian.Uint16(loca[int(glyph)*2:]))) * 2
+}
+
+func TestWritePDFFontSubset(t *testing.T) {
+	doc := export.Document{
+		Title:  "Табель учёта рабочего времени",
+		Fields: []export.Field{{Label: "Сотрудник", Value: "Иванов Иван Иванович"}},
+		Table: export.Table{
+			Header: []string{"Дата", "Задача", "Время", "Часы"},
+			Rows:   [][]string{{"2026-10-01", "написать тестовое", "2 ч 30 мин", "2.50"}},
+			Totals: []string{"Итого", "", "2 ч 30 мин", "2.50"},
+		},
+		Signatures: []string{"Исполнитель", "Заказчик"},
+	}
+
+	var buf bytes.Buffer
+
+	err := export.WritePDF(&buf, doc)
+	if err != nil {
+		t.Fatalf("WritePDF Error: %s", err)
+	}
+
+	// 6 - файл шрифта, 7 - ToUnicode, 9 - содержимое первой страницы
+	streams := pdfStreams(t, buf.Bytes())
+	for _, num := range []int{6, 7, 9} {
+		if _, ok := streams[num]; !ok {
+			t.Fatalf("stream %d not found", num)
+		}
+	}
+
+	font := parseTestFont(t, streams[6])
+
+	for _, tag := range []string{"cmap", "glyf", "head", "hhea", "hmtx", "loca", "maxp"} {
+		assert.Contains(t, font.tables, tag)
+	}
+
+	toUnicode := make(map[uint16]rune)
+
+	for _, m := range bfcharRe.FindAllStringSubmatch(string(streams[7]), -1) {
+		glyph, _ := strconv.ParseUint(m[1], 16, 16)
+		code, _ := strconv.ParseUint(m[2], 16, 32)
+		toUnicode[uint16(glyph)] = rune(code)
+	}
+
+	for _, r := range "ТабельучётаИвановЗаказчикСотрудникДатаЧасы" {
+		glyph := font.glyph(t, r)
+
+		assert.NotZero(t, glyph, "glyph for %q", r)
+		assert.Positive(t, font.outlineLen(glyph), "outline for %q", r)
+		assert.Equal(t, r, toUnicode[glyph], "ToUnicode for %q", r)
+	}
+
+	// символы, которых нет в документе, остаются в cmap, но без контуров
+	for _, r := range "ЖЩЮ" {
+		glyph := font.glyph(t, r)
+
+		assert.NotZero(t, glyph, "glyph for %q", r)
+		assert.Zero(t, font.outlineLen(glyph), "outline for %q", r)
+		assert.NotContains(t, toUnicode, glyph)
+	}
+
+	// текст на странице закодирован теми же номерами глифов, что и в cmap шрифта
+	var title strings.Builder
+	for _, r := range doc.Title {
+		fmt.Fprintf(&title, "%04X", font.glyph(t, r))
+	}
+
+	assert.Contains(t, string(streams[9]), "<"+title.String()+"> Tj")
+}
@@ -0,0 +1,450 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mockCalendarToken = "3f2a9c1e7b5d4f6a8c0e2b4d6f8a1c3e5b7d9f1a3c5e7b9d1f3a5c7e9b1d3f5a"
+
+func mockCalendarTokenHash() string {
+	sum := sha256.Sum256([]byte(mockCalendarToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIssueCalendarToken(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/users/1/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/users/asfasf/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "User Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/users/1/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockError:      repos.ErrUsrNotExists,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/users/1/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockCalendarRepo := new(reposmocks.MockCalendarRepo)
+
+			mockCalendarService := services.NewCalendarService(mockCalendarRepo)
+
+			calendarHandler := handlers.NewCalendarHandler(mockCalendarService, logger)
+
+			var savedHash string
+
+			mockCalendarRepo.On(
+				"SaveCalendarToken",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+				mock.AnythingOfType("string"),
+			).Run(func(args mock.Arguments) {
+				savedHash = args.String(2)
+			}).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Host = "tracker.example.com"
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/calendar/token", calendarHandler.IssueCalendarToken).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedStatus == http.StatusCreated {
+				var token models.CalendarToken
+
+				err = json.NewDecoder(rr.Body).Decode(&token)
+				assert.NoError(t, err)
+				assert.Len(t, token.Token, 64)
+				assert.Equal(t, "http://tracker.example.com/users/1/calendar.ics?token="+token.Token, token.URL)
+
+				// в базу попадает только хэш токена
+				sum := sha256.Sum256([]byte(token.Token))
+				assert.Equal(t, hex.EncodeToString(sum[:]), savedHash)
+			}
+
+			if tc.callRepo {
+				mockCalendarRepo.AssertCalled(t, "SaveCalendarToken", mock.Anything, 1, mock.Anything)
+			} else {
+				mockCalendarRepo.AssertNotCalled(t, "SaveCalendarToken", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRevokeCalendarToken(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/users/1/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/users/asfasf/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Token Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/users/1/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockError:      repos.ErrCalendarTokenNotFound,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/users/1/calendar/token",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockCalendarRepo := new(reposmocks.MockCalendarRepo)
+
+			mockCalendarService := services.NewCalendarService(mockCalendarRepo)
+
+			calendarHandler := handlers.NewCalendarHandler(mockCalendarService, logger)
+
+			mockCalendarRepo.On(
+				"DeleteCalendarToken",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/calendar/token", calendarHandler.RevokeCalendarToken).Methods(http.MethodDelete)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockCalendarRepo.AssertCalled(t, "DeleteCalendarToken", mock.Anything, 1)
+			} else {
+				mockCalendarRepo.AssertNotCalled(t, "DeleteCalendarToken", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetCalendar(t *testing.T) {
+	type mockRepoResp struct {
+		tokenHash  string
+		tokenError error
+		events     []models.CalendarEvent
+		mockError  error
+	}
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.CalendarFilter{UserID: 1, From: from, To: to}
+
+	endTime := time.Date(2026, 10, 12, 11, 30, 0, 0, time.UTC)
+
+	events := []models.CalendarEvent{
+		{
+			EntryID:  7,
+			TaskID:   1,
+			TaskName: "написать тестовое",
+			Source:   "tracker",
+			Start:    time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+			End:      &endTime,
+		},
+		{
+			EntryID:  8,
+			TaskID:   2,
+			TaskName: "mockTask1",
+			Source:   "tracker",
+			Start:    time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC),
+		},
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/calendar.ics?token=" + mockCalendarToken + "&from=2026-10-01&to=2026-11-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{tokenHash: mockCalendarTokenHash(), events: events},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody: []string{
+				"BEGIN:VCALENDAR\r\n",
+				"UID:entry-7@emtask\r\n",
+				"DTSTART:20261012T090000Z\r\nDTEND:20261012T113000Z\r\nSUMMARY:написать тестовое\r\n",
+				"SUMMARY:mockTask1 (идёт)\r\n",
+				"END:VCALENDAR\r\n",
+			},
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/asfasf/calendar.ics?token=" + mockCalendarToken,
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Invalid period",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/calendar.ics?token=" + mockCalendarToken + "&from=2026-11-01&to=2026-10-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Wrong token",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/calendar.ics?token=wrong&from=2026-10-01&to=2026-11-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{tokenHash: mockCalendarTokenHash()},
+			callRepo:       false,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			id:   5,
+			name: "Token not issued",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/calendar.ics?token=" + mockCalendarToken + "&from=2026-10-01&to=2026-11-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{tokenError: repos.ErrCalendarTokenNotFound},
+			callRepo:       false,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/calendar.ics?token=" + mockCalendarToken + "&from=2026-10-01&to=2026-11-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp: mockRepoResp{
+				tokenHash: mockCalendarTokenHash(),
+				mockError: errors.New("эта ошибка ломает service"),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   7,
+			name: "Export Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/calendar.ics?token=" + mockCalendarToken + "&from=2026-10-01&to=2026-11-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{tokenHash: mockCalendarTokenHash(), events: events},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockCalendarRepo := new(reposmocks.MockCalendarRepo)
+
+			mockCalendarService := services.NewCalendarService(mockCalendarRepo)
+
+			calendarHandler := handlers.NewCalendarHandler(mockCalendarService, logger)
+
+			mockCalendarRepo.On(
+				"FindCalendarTokenHash",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.repoResp.tokenHash, tc.repoResp.tokenError)
+
+			mockCalendarRepo.On(
+				"FindCalendarEvents",
+				mock.AnythingOfType("*context.timerCtx"),
+				filter,
+			).Return(tc.repoResp.events, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/calendar.ics", calendarHandler.GetCalendar).Methods(http.MethodGet)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedBody != nil {
+				assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+
+				for _, part := range tc.expectedBody {
+					assert.Contains(t, rr.Body.String(), part)
+				}
+			}
+
+			if tc.callRepo {
+				mockCalendarRepo.AssertCalled(t, "FindCalendarEvents", mock.Anything, filter)
+			} else {
+				mockCalendarRepo.AssertNotCalled(t, "FindCalendarEvents", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var mockClient = models.Client{ID: 5, Name: "ООО Ромашка", TotalSeconds: 9000}
+
+func TestCreateClient(t *testing.T) {
+	type mockRepoResp struct {
+		client    models.Client
+		mockError error
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		clientName     string
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+		expectedClient models.Client
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/clients",
+				mockRequestBody:   strings.NewReader(`{"name":"  ООО Ромашка "}`),
+			},
+			clientName:     "ООО Ромашка",
+			repoResp:       mockRepoResp{client: models.Client{ID: 5, Name: "ООО Ромашка"}},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedClient: models.Client{ID: 5, Name: "ООО Ромашка"},
+		},
+		{
+			id:   2,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/clients",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Empty name Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/clients",
+				mockRequestBody:   strings.NewReader(`{"name":"   "}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Client exists Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/clients",
+				mockRequestBody:   strings.NewReader(`{"name":"ООО Ромашка"}`),
+			},
+			clientName:     "ООО Ромашка",
+			repoResp:       mockRepoResp{mockError: repos.ErrClientExists},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/clients",
+				mockRequestBody:   strings.NewReader(`{"name":"ООО Ромашка"}`),
+			},
+			clientName:     "ООО Ромашка",
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   6,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/clients",
+				mockRequestBody:   strings.NewReader(`{"name":"ООО Ромашка"}`),
+			},
+			clientName:     "ООО Ромашка",
+			repoResp:       mockRepoResp{client: models.Client{ID: 5, Name: "ООО Ромашка"}},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockProjectRepo := new(reposmocks.MockProjectRepo)
+
+			mockProjectService := services.NewProjectService(mockProjectRepo)
+
+			clientHandler := handlers.NewClientHandler(mockProjectService, logger)
+
+			mockProjectRepo.On(
+				"CreateClient",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.clientName,
+			).Return(tc.repoResp.client, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			if tc.breakWrite {
+				clientHandler.CreateClient(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				clientHandler.CreateClient(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedClient.ID != 0 {
+				var client models.Client
+
+				err = json.NewDecoder(rr.Body).Decode(&client)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedClient, client)
+			}
+
+			if tc.callRepo {
+				mockProjectRepo.AssertCalled(t, "CreateClient", mock.Anything, tc.clientName)
+			} else {
+				mockProjectRepo.AssertNotCalled(t, "CreateClient", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetClientByID(t *testing.T) {
+	type mockRepoResp struct {
+		client    models.Client
+		mockError error
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		clientID       int
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/clients/5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       5,
+			repoResp:       mockRepoResp{client: mockClient},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/clients/asfasf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/clients/6",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       6,
+			repoResp:       mockRepoResp{mockError: repos.ErrClientNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/clients/5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       5,
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockProjectRepo := new(reposmocks.MockProjectRepo)
+
+			mockProjectService := services.NewProjectService(mockProjectRepo)
+
+			clientHandler := handlers.NewClientHandler(mockProjectService, logger)
+
+			mockProjectRepo.On(
+				"FindClientByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.clientID,
+			).Return(tc.repoResp.client, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/clients/{client_id}", clientHandler.GetClientByID).Methods(http.MethodGet)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockProjectRepo.AssertCalled(t, "FindClientByID", mock.Anything, tc.clientID)
+			} else {
+				mockProjectRepo.AssertNotCalled(t, "FindClientByID", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDeleteClient(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		clientID       int
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/clients/5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       5,
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/clients/asfasf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Has projects Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/clients/5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       5,
+			mockError:      repos.ErrClientHasProjects,
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   4,
+			name: "Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/clients/6",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       6,
+			mockError:      repos.ErrClientNotFound,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/clients/5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			clientID:       5,
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockProjectRepo := new(reposmocks.MockProjectRepo)
+
+			mockProjectService := services.NewProjectService(mockProjectRepo)
+
+			clientHandler := handlers.NewClientHandler(mockProjectService, logger)
+
+			mockProjectRepo.On("DeleteClient", mock.AnythingOfType("*context.timerCtx"), tc.clientID).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/clients/{client_id}", clientHandler.DeleteClient).Methods(http.MethodDelete)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockProjectRepo.AssertCalled(t, "DeleteClient", mock.Anything, tc.clientID)
+			} else {
+				mockProjectRepo.AssertNotCalled(t, "DeleteClient", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
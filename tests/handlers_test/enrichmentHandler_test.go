@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stubEnrichmentCache struct {
+	stats models.EnrichmentCacheStats
+}
+
+func (sc stubEnrichmentCache) Stats() models.EnrichmentCacheStats {
+	return sc.stats
+}
+
+func TestGetCacheStats(t *testing.T) {
+	stats := models.EnrichmentCacheStats{Hits: 6, NegativeHits: 2, Misses: 2, HitRatio: 0.8, Size: 5}
+
+	testCases := []struct {
+		id             int
+		name           string
+		cache          models.EnrichmentCache
+		breakWrite     bool
+		expectedStatus int
+	}{
+		{
+			id:             1,
+			name:           "Success",
+			cache:          stubEnrichmentCache{stats: stats},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:             2,
+			name:           "Cache Disabled",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:             3,
+			name:           "Encode Error",
+			cache:          stubEnrichmentCache{stats: stats},
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			enrichmentHandler := handlers.NewEnrichmentHandler(tc.cache, zapLogger.Sugar())
+
+			req, err := http.NewRequest(http.MethodGet, "/enrichment/cache/stats", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.breakWrite {
+				mockWriter := &errorResponseWriter{}
+				enrichmentHandler.GetCacheStats(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+
+				return
+			}
+
+			rr := httptest.NewRecorder()
+			enrichmentHandler.GetCacheStats(rr, req)
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedStatus == http.StatusOK {
+				var got models.EnrichmentCacheStats
+
+				err = json.NewDecoder(rr.Body).Decode(&got)
+				assert.NoError(t, err)
+				assert.Equal(t, stats, got)
+			}
+		})
+	}
+}
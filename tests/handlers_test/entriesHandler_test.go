@@ -0,0 +1,739 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	mockEntryStart = time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	mockEntryEnd   = time.Date(2024, 7, 1, 11, 30, 0, 0, time.UTC)
+)
+
+func TestCreateEntry(t *testing.T) {
+	type mockRepoResp struct {
+		entry     models.TimeEntry
+		mockError error
+	}
+
+	repoEntry := models.TimeEntry{
+		TaskID:    1,
+		UserID:    1,
+		StartTime: mockEntryStart,
+		EndTime:   &mockEntryEnd,
+		Reason:    "забыл включить таймер",
+	}
+
+	createdEntry := repoEntry
+	createdEntry.ID = 5
+	createdEntry.ClosedBy = "stop"
+	createdEntry.Source = "manual"
+	createdEntry.DurationSeconds = 5400
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+		expectedEntry  *models.TimeEntry
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"забыл включить таймер"}`),
+			},
+			repoResp:       mockRepoResp{entry: createdEntry},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedEntry:  &createdEntry,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/dsfdsf/tasks/1/entries",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody:   strings.NewReader(`{"start_time":`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Inverted period Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T11:30:00Z",` +
+					`"end_time":"2024-07-01T10:00:00Z","reason":"забыл включить таймер"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Future Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2999-07-01T10:00:00Z","reason":"забыл включить таймер"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "Empty reason Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"  "}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   7,
+			name: "Task Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"забыл включить таймер"}`),
+			},
+			repoResp:       mockRepoResp{mockError: repos.ErrTaskNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   8,
+			name: "Overlap Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"забыл включить таймер"}`),
+			},
+			repoResp:       mockRepoResp{mockError: repos.ErrEntryOverlap},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   9,
+			name: "Task Done Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"забыл включить таймер"}`),
+			},
+			repoResp:       mockRepoResp{mockError: repos.ErrTaskStatusConflict},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   10,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"забыл включить таймер"}`),
+			},
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   11,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/1/tasks/1/entries",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"забыл включить таймер"}`),
+			},
+			repoResp:       mockRepoResp{entry: createdEntry},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockEntryService := services.NewTimeEntryService(mockEntriesRepo)
+
+			entryHandler := handlers.NewEntryHandler(mockEntryService, logger)
+
+			mockEntriesRepo.On(
+				"CreateEntry",
+				mock.AnythingOfType("*context.timerCtx"),
+				repoEntry,
+				[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+			).Return(tc.repoResp.entry, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/user/{user_id}/tasks/{task_id}/entries", entryHandler.CreateEntry).Methods(http.MethodPost)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedEntry != nil {
+				var entry models.TimeEntry
+
+				err = json.NewDecoder(rr.Body).Decode(&entry)
+				assert.NoError(t, err)
+				assert.Equal(t, *tc.expectedEntry, entry)
+			}
+
+			if tc.callRepo {
+				mockEntriesRepo.AssertCalled(t, "CreateEntry", mock.Anything, repoEntry, mock.Anything)
+			} else {
+				mockEntriesRepo.AssertNotCalled(t, "CreateEntry", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUpdateEntry(t *testing.T) {
+	type mockRepoResp struct {
+		entry     models.TimeEntry
+		mockError error
+	}
+
+	repoEntry := models.TimeEntry{
+		ID:        5,
+		UserID:    1,
+		StartTime: mockEntryStart,
+		EndTime:   &mockEntryEnd,
+		Reason:    "таймер работал всю ночь",
+	}
+
+	updatedEntry := repoEntry
+	updatedEntry.TaskID = 2
+	updatedEntry.ClosedBy = "stop"
+	updatedEntry.Source = "tracker"
+	updatedEntry.DurationSeconds = 5400
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+		expectedEntry  *models.TimeEntry
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1/entries/5",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"таймер работал всю ночь"}`),
+			},
+			repoResp:       mockRepoResp{entry: updatedEntry},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedEntry:  &updatedEntry,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1/entries/dsfdsf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Empty period Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1/entries/5",
+				mockRequestBody:   strings.NewReader(`{"reason":"таймер работал всю ночь"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Entry Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1/entries/5",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"таймер работал всю ночь"}`),
+			},
+			repoResp:       mockRepoResp{mockError: repos.ErrEntryNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Running Entry Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1/entries/5",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"таймер работал всю ночь"}`),
+			},
+			repoResp:       mockRepoResp{mockError: repos.ErrEntryRunning},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1/entries/5",
+				mockRequestBody: strings.NewReader(`{"start_time":"2024-07-01T10:00:00Z",` +
+					`"end_time":"2024-07-01T11:30:00Z","reason":"таймер работал всю ночь"}`),
+			},
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockEntryService := services.NewTimeEntryService(mockEntriesRepo)
+
+			entryHandler := handlers.NewEntryHandler(mockEntryService, logger)
+
+			mockEntriesRepo.On(
+				"UpdateEntry",
+				mock.AnythingOfType("*context.timerCtx"),
+				repoEntry,
+			).Return(tc.repoResp.entry, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/user/{user_id}/entries/{entry_id}", entryHandler.UpdateEntry).Methods(http.MethodPatch)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedEntry != nil {
+				var entry models.TimeEntry
+
+				err = json.NewDecoder(rr.Body).Decode(&entry)
+				assert.NoError(t, err)
+				assert.Equal(t, *tc.expectedEntry, entry)
+			}
+
+			if tc.callRepo {
+				mockEntriesRepo.AssertCalled(t, "UpdateEntry", mock.Anything, repoEntry)
+			} else {
+				mockEntriesRepo.AssertNotCalled(t, "UpdateEntry", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDeleteEntry(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/user/1/entries/5?reason=дубль",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/user/dsfdsf/entries/5?reason=дубль",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Empty reason Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/user/1/entries/5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Entry Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/user/1/entries/5?reason=дубль",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockError:      repos.ErrEntryNotFound,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/user/1/entries/5?reason=дубль",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockEntryService := services.NewTimeEntryService(mockEntriesRepo)
+
+			entryHandler := handlers.NewEntryHandler(mockEntryService, logger)
+
+			mockEntriesRepo.On(
+				"DeleteEntry",
+				mock.AnythingOfType("*context.timerCtx"),
+				5,
+				1,
+				"дубль",
+			).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/user/{user_id}/entries/{entry_id}", entryHandler.DeleteEntry).Methods(http.MethodDelete)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockEntriesRepo.AssertCalled(t, "DeleteEntry", mock.Anything, 5, 1, "дубль")
+			} else {
+				mockEntriesRepo.AssertNotCalled(t, "DeleteEntry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetActiveTimers(t *testing.T) {
+	type mockRepoResp struct {
+		timers    []models.ActiveTimer
+		mockError error
+	}
+
+	mockTimers := []models.ActiveTimer{
+		{
+			EntryID:        3,
+			TaskID:         1,
+			TaskName:       "написать тестовое",
+			UserID:         1,
+			Surname:        "Иванов",
+			Name:           "Иван",
+			Patronymic:     "Иванович",
+			StartTime:      mockEntryStart,
+			ElapsedSeconds: 5400,
+		},
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		route          string
+		mockReq        mockRequest
+		filter         models.ActiveTimerFilter
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+		expectedTimers []models.ActiveTimer
+	}{
+		{
+			id:    1,
+			name:  "Success",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?surname=Иванов&page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         models.ActiveTimerFilter{Surname: "Иванов"},
+			repoResp:       mockRepoResp{timers: mockTimers},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedTimers: mockTimers,
+		},
+		{
+			id:    2,
+			name:  "Success user_id filter",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?user_id=1&page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         models.ActiveTimerFilter{UserID: 1},
+			repoResp:       mockRepoResp{timers: mockTimers},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedTimers: mockTimers,
+		},
+		{
+			id:    3,
+			name:  "Success per user",
+			route: "/users/{user_id}/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timers/active?page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         models.ActiveTimerFilter{UserID: 1},
+			repoResp:       mockRepoResp{timers: mockTimers},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedTimers: mockTimers,
+		},
+		{
+			id:    4,
+			name:  "Invalid user_id Error",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?user_id=dsfdsf&page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:    5,
+			name:  "Atoi Error per user",
+			route: "/users/{user_id}/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/dsfdsf/timers/active?page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:    6,
+			name:  "Invalid Page Error",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?page=0&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:    7,
+			name:  "Invalid Limit Error",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?page=1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:    8,
+			name:  "Service Error",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:    9,
+			name:  "Encode Error",
+			route: "/timers/active",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/timers/active?page=1&limit=10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timers: mockTimers},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockEntryService := services.NewTimeEntryService(mockEntriesRepo)
+
+			entryHandler := handlers.NewEntryHandler(mockEntryService, logger)
+
+			mockEntriesRepo.On(
+				"FindActiveTimers",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+				1,
+				10,
+			).Return(tc.repoResp.timers, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/timers/active", entryHandler.GetActiveTimers).Methods(http.MethodGet)
+			router.HandleFunc("/users/{user_id}/timers/active", entryHandler.GetUserActiveTimers).Methods(http.MethodGet)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedTimers != nil {
+				var timers []models.ActiveTimer
+
+				err = json.NewDecoder(rr.Body).Decode(&timers)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedTimers, timers)
+			}
+
+			if tc.callRepo {
+				mockEntriesRepo.AssertCalled(t, "FindActiveTimers", mock.Anything, tc.filter, 1, 10)
+			} else {
+				mockEntriesRepo.AssertNotCalled(t, "FindActiveTimers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
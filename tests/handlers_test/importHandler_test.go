@@ -0,0 +1,368 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mockTogglCSV = "\xEF\xBB\xBFUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n" +
+	"Ivan Petrov,ivan@example.com,,Backend,,Code review,No,2024-07-01,10:00:00,2024-07-01,11:30:00,01:30:00,\n" +
+	"Ivan Petrov,IVAN@example.com,,Backend,,code review,No,2024-07-02,09:00:00,2024-07-02,10:00:00,01:00:00,\n" +
+	"Anna  Smirnova,,,Backend,,,No,2024-07-02,09:00:00,2024-07-02,09:45:00,00:45:00,\n" +
+	"Anna Smirnova,,,,,,No,2024-07-03,09:00:00,2024-07-03,09:45:00,00:45:00,\n"
+
+const mockImportBoundary = "import-boundary"
+
+func TestImportEntries(t *testing.T) {
+	testCases := []struct {
+		id               int
+		name             string
+		mockReq          mockRequest
+		contentType      string
+		userErr          error
+		entryErr         error
+		callTransactor   bool
+		createUser       bool
+		breakWrite       bool
+		expectedStatus   int
+		expectedReport   models.ImportReport
+		expectedImported int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?skip_invalid=true&tz=Europe/Moscow",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: true,
+			createUser:     true,
+			expectedStatus: http.StatusOK,
+			expectedReport: models.ImportReport{
+				Format:       models.ImportFormatToggl,
+				Committed:    true,
+				Rows:         4,
+				Imported:     3,
+				CreatedUsers: []string{"Anna Smirnova"},
+				CreatedTasks: []string{"Ivan Petrov <ivan@example.com>: Code review"},
+				Invalid:      []models.ImportIssue{{Line: 5, User: "Anna Smirnova", Reason: "no description, task or project"}},
+				Unmatched:    []models.ImportIssue{},
+				Conflicts:    []models.ImportIssue{},
+			},
+			expectedImported: 3,
+		},
+		{
+			id:   2,
+			name: "Dry Run",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?dry_run=true&format=toggl",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: true,
+			createUser:     true,
+			expectedStatus: http.StatusOK,
+			expectedReport: models.ImportReport{
+				Format:       models.ImportFormatToggl,
+				DryRun:       true,
+				Rows:         4,
+				Imported:     3,
+				CreatedUsers: []string{"Anna Smirnova"},
+				CreatedTasks: []string{"Ivan Petrov <ivan@example.com>: Code review"},
+				Invalid:      []models.ImportIssue{{Line: 5, User: "Anna Smirnova", Reason: "no description, task or project"}},
+				Unmatched:    []models.ImportIssue{},
+				Conflicts:    []models.ImportIssue{},
+			},
+			expectedImported: 3,
+		},
+		{
+			id:   3,
+			name: "Rejected",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: true,
+			createUser:     true,
+			expectedStatus: http.StatusConflict,
+			expectedReport: models.ImportReport{
+				Format:       models.ImportFormatToggl,
+				Rows:         4,
+				Imported:     3,
+				CreatedUsers: []string{"Anna Smirnova"},
+				CreatedTasks: []string{"Ivan Petrov <ivan@example.com>: Code review"},
+				Invalid:      []models.ImportIssue{{Line: 5, User: "Anna Smirnova", Reason: "no description, task or project"}},
+				Unmatched:    []models.ImportIssue{},
+				Conflicts:    []models.ImportIssue{},
+			},
+			expectedImported: 3,
+		},
+		{
+			id:   4,
+			name: "Create Users Disabled",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?create_users=false&skip_invalid=1",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: true,
+			expectedStatus: http.StatusOK,
+			expectedReport: models.ImportReport{
+				Format:       models.ImportFormatToggl,
+				Committed:    true,
+				Rows:         4,
+				Imported:     2,
+				CreatedUsers: []string{},
+				CreatedTasks: []string{"Ivan Petrov <ivan@example.com>: Code review"},
+				Invalid:      []models.ImportIssue{{Line: 5, User: "Anna Smirnova", Reason: "no description, task or project"}},
+				Unmatched:    []models.ImportIssue{{Line: 4, User: "Anna Smirnova", Task: "Backend", Reason: "user not found"}},
+				Conflicts:    []models.ImportIssue{},
+			},
+			expectedImported: 2,
+		},
+		{
+			id:   5,
+			name: "Multipart Upload",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?skip_invalid=true",
+				mockRequestBody: strings.NewReader("--" + mockImportBoundary + "\r\n" +
+					"Content-Disposition: form-data; name=\"file\"; filename=\"toggl.csv\"\r\n" +
+					"Content-Type: text/csv\r\n\r\n" +
+					mockTogglCSV + "\r\n" +
+					"--" + mockImportBoundary + "--\r\n"),
+			},
+			contentType:    "multipart/form-data; boundary=" + mockImportBoundary,
+			callTransactor: true,
+			createUser:     true,
+			expectedStatus: http.StatusOK,
+			expectedReport: models.ImportReport{
+				Format:       models.ImportFormatToggl,
+				Committed:    true,
+				Rows:         4,
+				Imported:     3,
+				CreatedUsers: []string{"Anna Smirnova"},
+				CreatedTasks: []string{"Ivan Petrov <ivan@example.com>: Code review"},
+				Invalid:      []models.ImportIssue{{Line: 5, User: "Anna Smirnova", Reason: "no description, task or project"}},
+				Unmatched:    []models.ImportIssue{},
+				Conflicts:    []models.ImportIssue{},
+			},
+			expectedImported: 3,
+		},
+		{
+			id:   6,
+			name: "Invalid Format",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?format=harvest",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   7,
+			name: "Invalid Bool Param",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?dry_run=maybe",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   8,
+			name: "Invalid File",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries",
+				mockRequestBody:   strings.NewReader("User,Description,Start date,Start time\nIvan Petrov,Code review,2024-07-01,10:00:00\n"),
+			},
+			callTransactor: false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   9,
+			name: "Invalid Timezone",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?tz=Mars/Olympus",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   10,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?skip_invalid=true",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			userErr:        errors.New("эта ошибка ломает service"),
+			callTransactor: true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   11,
+			name: "Conflicts",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?dry_run=true",
+				mockRequestBody: strings.NewReader(
+					"Project,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal)\n" +
+						"Backend,,Code review,,Ivan Petrov,,ivan@example.com,,No,07/01/2024,10:00:00 AM,07/01/2024,11:30:00 AM,01:30:00,1.50\n"),
+			},
+			entryErr:       repos.ErrEntryOverlap,
+			callTransactor: true,
+			expectedStatus: http.StatusOK,
+			expectedReport: models.ImportReport{
+				Format:       models.ImportFormatClockify,
+				DryRun:       true,
+				Rows:         1,
+				CreatedUsers: []string{},
+				CreatedTasks: []string{"Ivan Petrov <ivan@example.com>: Code review"},
+				Invalid:      []models.ImportIssue{},
+				Unmatched:    []models.ImportIssue{},
+				Conflicts: []models.ImportIssue{{
+					Line:   2,
+					User:   "Ivan Petrov <ivan@example.com>",
+					Task:   "Code review",
+					Reason: "overlaps another entry of the user (2024-07-01T10:00:00Z - 2024-07-01T11:30:00Z)",
+				}},
+			},
+		},
+		{
+			id:   12,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/import/entries?skip_invalid=true",
+				mockRequestBody:   strings.NewReader(mockTogglCSV),
+			},
+			callTransactor: true,
+			createUser:     true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTransactor := new(reposmocks.MockTransactor)
+			mockUserRepo := new(reposmocks.MockUserRepo)
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			importService := services.NewImportService(mockTransactor, mockUserRepo, mockTasksRepo, mockEntriesRepo)
+
+			importHandler := handlers.NewImportHandler(importService, logger)
+
+			ctxType := mock.AnythingOfType("*context.timerCtx")
+
+			mockTransactor.On("InTx", ctxType).Return(nil)
+			mockUserRepo.On("FindUserByEmail", ctxType, "ivan@example.com").Return(models.User{ID: 1}, tc.userErr)
+			mockUserRepo.On("FindUsersByFullName", ctxType, "Anna Smirnova").Return([]models.User{}, nil)
+
+			var createdUser models.ServiceUser
+
+			mockUserRepo.On("AddUser", ctxType, mock.AnythingOfType("models.ServiceUser")).
+				Run(func(args mock.Arguments) {
+					createdUser = args.Get(1).(models.ServiceUser)
+				}).
+				Return(2, nil)
+			mockTasksRepo.On("FindTaskByName", ctxType, 1, "Code review").Return(models.Task{}, repos.ErrTaskNotFound)
+			mockTasksRepo.On("AddTask", ctxType, "Code review", 1, (*int)(nil), (*int)(nil), (*int64)(nil)).Return(models.Task{ID: 10}, nil)
+			mockTasksRepo.On("FindTaskByName", ctxType, 2, "Backend").Return(models.Task{ID: 11}, nil)
+
+			var entries []models.TimeEntry
+
+			mockEntriesRepo.On("ImportEntry", ctxType, mock.AnythingOfType("models.TimeEntry")).
+				Run(func(args mock.Arguments) {
+					entries = append(entries, args.Get(1).(models.TimeEntry))
+				}).
+				Return(models.TimeEntry{}, tc.entryErr)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			if tc.breakWrite {
+				importHandler.ImportEntries(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				importHandler.ImportEntries(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if !tc.callTransactor {
+				mockTransactor.AssertNotCalled(t, "InTx", ctxType)
+
+				return
+			}
+
+			mockTransactor.AssertCalled(t, "InTx", ctxType)
+
+			if tc.expectedStatus == http.StatusOK || tc.expectedStatus == http.StatusConflict {
+				var report models.ImportReport
+
+				err = json.NewDecoder(rr.Body).Decode(&report)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReport, report)
+				assert.Len(t, entries, tc.expectedImported+len(tc.expectedReport.Conflicts))
+
+				// задача ищется один раз на юзера, регистр названия не важен
+				mockTasksRepo.AssertNumberOfCalls(t, "AddTask", 1)
+			}
+
+			if tc.createUser {
+				mockUserRepo.AssertCalled(t, "AddUser", ctxType, mock.AnythingOfType("models.ServiceUser"))
+				assert.Equal(t, "Smirnova", createdUser.Surname)
+				assert.Equal(t, "Anna", createdUser.Name)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "AddUser", ctxType, mock.AnythingOfType("models.ServiceUser"))
+			}
+
+			if tc.id == 1 {
+				// время в файле - в поясе из tz
+				assert.Equal(t, time.Date(2024, 7, 1, 7, 0, 0, 0, time.UTC), entries[0].StartTime.UTC())
+				assert.Equal(t, 1, entries[0].UserID)
+				assert.Equal(t, 10, entries[0].TaskID)
+				assert.Equal(t, "Europe/Moscow", createdUser.Timezone)
+			}
+		})
+	}
+}
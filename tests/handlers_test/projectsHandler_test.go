@@ -0,0 +1,402 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var mockClientID = 5
+
+var mockProject = models.Project{ID: 7, Name: "Сайт", ClientID: &mockClientID, ClientName: "ООО Ромашка", TotalSeconds: 5400}
+
+func TestCreateProject(t *testing.T) {
+	type mockRepoResp struct {
+		project   models.Project
+		mockError error
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		projectReq     models.ProjectRequest
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт ","client_id":5}`),
+			},
+			projectReq:     models.ProjectRequest{Name: "Сайт", ClientID: &mockClientID},
+			repoResp:       mockRepoResp{project: mockProject},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			id:   2,
+			name: "Success without client",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{"name":"Внутренний"}`),
+			},
+			projectReq:     models.ProjectRequest{Name: "Внутренний"},
+			repoResp:       mockRepoResp{project: models.Project{ID: 8, Name: "Внутренний"}},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			id:   3,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Too long name Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{"name":"` + strings.Repeat("я", 256) + `"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Client not found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт","client_id":5}`),
+			},
+			projectReq:     models.ProjectRequest{Name: "Сайт", ClientID: &mockClientID},
+			repoResp:       mockRepoResp{mockError: repos.ErrClientNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   6,
+			name: "Project exists Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт","client_id":5}`),
+			},
+			projectReq:     models.ProjectRequest{Name: "Сайт", ClientID: &mockClientID},
+			repoResp:       mockRepoResp{mockError: repos.ErrProjectExists},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   7,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт","client_id":5}`),
+			},
+			projectReq:     models.ProjectRequest{Name: "Сайт", ClientID: &mockClientID},
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockProjectRepo := new(reposmocks.MockProjectRepo)
+
+			mockProjectService := services.NewProjectService(mockProjectRepo)
+
+			projectHandler := handlers.NewProjectHandler(mockProjectService, logger)
+
+			mockProjectRepo.On(
+				"CreateProject",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.projectReq,
+			).Return(tc.repoResp.project, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			projectHandler.CreateProject(rr, req)
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedStatus == http.StatusCreated {
+				var project models.Project
+
+				err = json.NewDecoder(rr.Body).Decode(&project)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.repoResp.project, project)
+			}
+
+			if tc.callRepo {
+				mockProjectRepo.AssertCalled(t, "CreateProject", mock.Anything, tc.projectReq)
+			} else {
+				mockProjectRepo.AssertNotCalled(t, "CreateProject", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetProjects(t *testing.T) {
+	type mockRepoResp struct {
+		projects  []models.Project
+		mockError error
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		filter         models.ProjectFilter
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{projects: []models.Project{mockProject, {ID: 8, Name: "Внутренний"}}},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Success by client",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/projects?client_id=5",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         models.ProjectFilter{ClientID: 5},
+			repoResp:       mockRepoResp{projects: []models.Project{mockProject}},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   3,
+			name: "Invalid client_id Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/projects?client_id=asfasf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   5,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/projects",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{projects: []models.Project{mockProject}},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockProjectRepo := new(reposmocks.MockProjectRepo)
+
+			mockProjectService := services.NewProjectService(mockProjectRepo)
+
+			projectHandler := handlers.NewProjectHandler(mockProjectService, logger)
+
+			mockProjectRepo.On(
+				"FindProjects",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(tc.repoResp.projects, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			if tc.breakWrite {
+				projectHandler.GetProjects(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				projectHandler.GetProjects(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.callRepo {
+				mockProjectRepo.AssertCalled(t, "FindProjects", mock.Anything, tc.filter)
+			} else {
+				mockProjectRepo.AssertNotCalled(t, "FindProjects", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUpdateProject(t *testing.T) {
+	type mockRepoResp struct {
+		project   models.Project
+		mockError error
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		projectID      int
+		projectReq     models.ProjectRequest
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success move to internal",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/projects/7",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт","client_id":null}`),
+			},
+			projectID:      7,
+			projectReq:     models.ProjectRequest{Name: "Сайт"},
+			repoResp:       mockRepoResp{project: models.Project{ID: 7, Name: "Сайт", TotalSeconds: 5400}},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/projects/asfasf",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/projects/9",
+				mockRequestBody:   strings.NewReader(`{"name":"Сайт"}`),
+			},
+			projectID:      9,
+			projectReq:     models.ProjectRequest{Name: "Сайт"},
+			repoResp:       mockRepoResp{mockError: repos.ErrProjectNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockProjectRepo := new(reposmocks.MockProjectRepo)
+
+			mockProjectService := services.NewProjectService(mockProjectRepo)
+
+			projectHandler := handlers.NewProjectHandler(mockProjectService, logger)
+
+			mockProjectRepo.On(
+				"UpdateProject",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.projectID,
+				tc.projectReq,
+			).Return(tc.repoResp.project, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/projects/{project_id}", projectHandler.UpdateProject).Methods(http.MethodPatch)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockProjectRepo.AssertCalled(t, "UpdateProject", mock.Anything, tc.projectID, tc.projectReq)
+			} else {
+				mockProjectRepo.AssertNotCalled(t, "UpdateProject", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
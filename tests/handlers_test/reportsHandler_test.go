@@ -0,0 +1,1344 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetWorkload(t *testing.T) {
+	type mockRepoResp struct {
+		timezone      string
+		timezoneError error
+		workload      []models.TaskWorkload
+		mockError     error
+	}
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	projectID, otherProjectID, clientID := 7, 8, 5
+
+	testCases := []struct {
+		id               int
+		name             string
+		mockReq          mockRequest
+		filter           models.ReportFilter
+		repoResp         mockRepoResp
+		callRepo         bool
+		breakWrite       bool
+		expectedStatus   int
+		expectedWorkload []models.TaskWorkload
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC},
+			repoResp: mockRepoResp{
+				timezone: "UTC",
+				workload: []models.TaskWorkload{
+					{ReportScope: models.ReportScope{TaskID: 2}, Name: "mockTask1", TotalSeconds: 9000},
+					{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", TotalSeconds: 150},
+				},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedWorkload: []models.TaskWorkload{
+				{ReportScope: models.ReportScope{TaskID: 2}, Name: "mockTask1", Hours: 2, Minutes: 30, TotalSeconds: 9000},
+				{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Hours: 0, Minutes: 2, TotalSeconds: 150},
+			},
+		},
+		{
+			id:   2,
+			name: "Success grouped by client",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&group_by=client",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC, GroupBy: models.ReportGroupClient},
+			repoResp: mockRepoResp{
+				timezone: "UTC",
+				workload: []models.TaskWorkload{
+					{ReportScope: models.ReportScope{TaskID: 3}, Name: "mockTask2", TotalSeconds: 5400},
+					{
+						ReportScope: models.ReportScope{
+							TaskID:      2,
+							ProjectID:   &projectID,
+							ProjectName: "Сайт",
+							ClientID:    &clientID,
+							ClientName:  "ООО Ромашка",
+						},
+						Name:               "mockTask1",
+						TotalSeconds:       3600,
+						AutoStoppedEntries: 1,
+					},
+					{
+						ReportScope: models.ReportScope{
+							TaskID:      1,
+							ProjectID:   &otherProjectID,
+							ProjectName: "Приложение",
+							ClientID:    &clientID,
+							ClientName:  "ООО Ромашка",
+						},
+						Name:         "написать тестовое",
+						TotalSeconds: 3000,
+					},
+				},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedWorkload: []models.TaskWorkload{
+				{
+					ReportScope:        models.ReportScope{ClientID: &clientID},
+					Name:               "ООО Ромашка",
+					Hours:              1,
+					Minutes:            50,
+					TotalSeconds:       6600,
+					AutoStoppedEntries: 1,
+				},
+				{Name: "Без клиента", Hours: 1, Minutes: 30, TotalSeconds: 5400},
+			},
+		},
+		{
+			id:   3,
+			name: "Invalid client_id Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?client_id=-1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "UTC"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/asfasf/workload",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Success dates in user timezone",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01&to=2024-08-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{
+				UserID:   1,
+				From:     time.Date(2024, 7, 1, 0, 0, 0, 0, moscow),
+				To:       time.Date(2024, 8, 1, 0, 0, 0, 0, moscow),
+				Location: moscow,
+			},
+			repoResp: mockRepoResp{
+				timezone: "Europe/Moscow",
+				workload: []models.TaskWorkload{{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", TotalSeconds: 150}},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedWorkload: []models.TaskWorkload{
+				{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Hours: 0, Minutes: 2, TotalSeconds: 150},
+			},
+		},
+		{
+			id:   6,
+			name: "Success tz override",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01&to=2024-08-01&tz=Europe/Moscow",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{
+				UserID:   1,
+				From:     time.Date(2024, 7, 1, 0, 0, 0, 0, moscow),
+				To:       time.Date(2024, 8, 1, 0, 0, 0, 0, moscow),
+				Location: moscow,
+			},
+			repoResp: mockRepoResp{
+				timezone: "UTC",
+				workload: []models.TaskWorkload{{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", TotalSeconds: 150}},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedWorkload: []models.TaskWorkload{
+				{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Hours: 0, Minutes: 2, TotalSeconds: 150},
+			},
+		},
+		{
+			id:   7,
+			name: "Invalid tz Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?tz=Марс/Олимп",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   8,
+			name: "User Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp: mockRepoResp{
+				timezoneError: repos.ErrUsrNotExists,
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   9,
+			name: "Invalid from Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=dsfdsf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "UTC"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   10,
+			name: "Inverted period Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-08-01T00:00:00Z&to=2024-07-01T00:00:00Z",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "UTC"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   11,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC},
+			repoResp: mockRepoResp{
+				timezone:  "UTC",
+				mockError: errors.New("эта ошибка ломает service"),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   12,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC},
+			repoResp: mockRepoResp{
+				timezone: "UTC",
+				workload: []models.TaskWorkload{{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", TotalSeconds: 150}},
+			},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockReportsRepo := new(reposmocks.MockReportsRepo)
+
+			mockReportService := services.NewReportService(mockReportsRepo)
+
+			reportHandler := handlers.NewReportHandler(mockReportService, logger)
+
+			mockReportsRepo.On(
+				"FindUserTimezone",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.repoResp.timezone, tc.repoResp.timezoneError)
+
+			mockReportsRepo.On(
+				"GetWorkload",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(tc.repoResp.workload, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/workload", reportHandler.GetWorkload).Methods(http.MethodGet)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedWorkload != nil {
+				var workload []models.TaskWorkload
+
+				err = json.NewDecoder(rr.Body).Decode(&workload)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedWorkload, workload)
+			}
+
+			if tc.callRepo {
+				mockReportsRepo.AssertCalled(t, "GetWorkload", mock.Anything, tc.filter)
+			} else {
+				mockReportsRepo.AssertNotCalled(t, "GetWorkload", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetTagWorkload(t *testing.T) {
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	bugfixID, meetingID := 3, 4
+
+	testCases := []struct {
+		id               int
+		name             string
+		mockReq          mockRequest
+		filter           models.ReportFilter
+		workload         []models.TaskWorkload
+		mockError        error
+		expectedStatus   int
+		expectedWorkload []models.TaskWorkload
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&group_by=tag",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC, GroupBy: models.ReportGroupTag},
+			workload: []models.TaskWorkload{
+				{ReportScope: models.ReportScope{TagID: &bugfixID}, Name: "bugfix", TotalSeconds: 9000},
+				{Name: "", TotalSeconds: 5400, AutoStoppedEntries: 1},
+				{ReportScope: models.ReportScope{TagID: &meetingID}, Name: "meeting", TotalSeconds: 1800},
+			},
+			expectedStatus: http.StatusOK,
+			expectedWorkload: []models.TaskWorkload{
+				{ReportScope: models.ReportScope{TagID: &bugfixID}, Name: "bugfix", Hours: 2, Minutes: 30, TotalSeconds: 9000},
+				{Name: "Без тега", Hours: 1, Minutes: 30, TotalSeconds: 5400, AutoStoppedEntries: 1},
+				{ReportScope: models.ReportScope{TagID: &meetingID}, Name: "meeting", Minutes: 30, TotalSeconds: 1800},
+			},
+		},
+		{
+			id:   2,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&group_by=tag",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC, GroupBy: models.ReportGroupTag},
+			mockError:      errors.New("эта ошибка ломает service"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockReportsRepo := new(reposmocks.MockReportsRepo)
+
+			mockReportService := services.NewReportService(mockReportsRepo)
+
+			reportHandler := handlers.NewReportHandler(mockReportService, logger)
+
+			mockReportsRepo.On("FindUserTimezone", mock.AnythingOfType("*context.timerCtx"), 1).Return("UTC", nil)
+
+			mockReportsRepo.On(
+				"GetTagWorkload",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(tc.workload, tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/workload", reportHandler.GetWorkload).Methods(http.MethodGet)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedWorkload != nil {
+				var workload []models.TaskWorkload
+
+				err = json.NewDecoder(rr.Body).Decode(&workload)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedWorkload, workload)
+			}
+
+			mockReportsRepo.AssertCalled(t, "GetTagWorkload", mock.Anything, tc.filter)
+			mockReportsRepo.AssertNotCalled(t, "GetWorkload", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetWorkloadExport(t *testing.T) {
+	type mockRepoResp struct {
+		user      models.User
+		userError error
+	}
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	filter := models.ReportFilter{UserID: 1, From: from, To: to, Location: time.UTC}
+
+	workload := []models.TaskWorkload{
+		{ReportScope: models.ReportScope{TaskID: 2}, Name: "mockTask1", TotalSeconds: 9000},
+		{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", TotalSeconds: 150},
+	}
+
+	user := models.User{ID: 1, Surname: "Иванов", Name: "Иван", Patronymic: "Иванович", Timezone: "UTC"}
+
+	testCases := []struct {
+		id                  int
+		name                string
+		mockReq             mockRequest
+		accept              string
+		repoResp            mockRepoResp
+		callRepo            bool
+		breakWrite          bool
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        []string
+	}{
+		{
+			id:   1,
+			name: "Success CSV by format",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&format=csv",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:            mockRepoResp{user: user},
+			callRepo:            true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: []string{
+				"Сотрудник,Задача,Время,Часы\n",
+				"Иванов Иван Иванович,mockTask1,2 ч 30 мин,2.50\n",
+				"Иванов Иван Иванович,написать тестовое,2 мин,0.04\n",
+				"Итого,,2 ч 32 мин,2.54\n",
+			},
+		},
+		{
+			id:   2,
+			name: "Success XLSX by Accept",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			accept:              "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			repoResp:            mockRepoResp{user: user},
+			callRepo:            true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			expectedBody:        []string{"PK"},
+		},
+		{
+			id:   3,
+			name: "Invalid format",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&format=pdf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "User not found",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&tz=UTC&format=csv",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{userError: repos.ErrUsrNotExists},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Export Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/workload?from=2024-07-01T00:00:00Z&to=2024-08-01T00:00:00Z&format=csv",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{user: user},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockReportsRepo := new(reposmocks.MockReportsRepo)
+
+			mockReportService := services.NewReportService(mockReportsRepo)
+
+			reportHandler := handlers.NewReportHandler(mockReportService, logger)
+
+			mockReportsRepo.On(
+				"FindUserTimezone",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return("UTC", nil)
+
+			mockReportsRepo.On(
+				"GetWorkload",
+				mock.AnythingOfType("*context.timerCtx"),
+				filter,
+			).Return(workload, nil)
+
+			mockReportsRepo.On(
+				"FindUserByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.repoResp.user, tc.repoResp.userError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/workload", reportHandler.GetWorkload).Methods(http.MethodGet)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, rr.Header().Get("Content-Type"))
+				assert.Contains(t, rr.Header().Get("Content-Disposition"), "workload_1.")
+			}
+
+			for _, part := range tc.expectedBody {
+				assert.Contains(t, rr.Body.String(), part)
+			}
+
+			if tc.callRepo {
+				mockReportsRepo.AssertCalled(t, "GetWorkload", mock.Anything, filter)
+			} else {
+				mockReportsRepo.AssertNotCalled(t, "GetWorkload", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	type mockRepoResp struct {
+		timezone  string
+		summary   []models.SummaryCell
+		mockError error
+	}
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, moscow)
+	to := time.Date(2024, 7, 3, 0, 0, 0, 0, moscow)
+	projectID := 7
+
+	testCases := []struct {
+		id              int
+		name            string
+		mockReq         mockRequest
+		filter          models.ReportFilter
+		repoResp        mockRepoResp
+		callRepo        bool
+		breakWrite      bool
+		expectedStatus  int
+		expectedSummary []models.PeriodSummary
+	}{
+		{
+			id:   1,
+			name: "Success by day",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: moscow, Bucket: models.ReportBucketDay},
+			repoResp: mockRepoResp{
+				timezone: "Europe/Moscow",
+				summary: []models.SummaryCell{
+					{Start: from.UTC(), End: from.AddDate(0, 0, 1).UTC(), TotalSeconds: 9000},
+					{Start: from.AddDate(0, 0, 1).UTC(), End: to.UTC(), TotalSeconds: 0},
+				},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedSummary: []models.PeriodSummary{
+				{Period: "2024-07-01", Start: from, End: from.AddDate(0, 0, 1), Hours: 2, Minutes: 30, TotalSeconds: 9000},
+				{Period: "2024-07-02", Start: from.AddDate(0, 0, 1), End: to, TotalSeconds: 0},
+			},
+		},
+		{
+			id:   2,
+			name: "Success by week with tz override",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03&group=week&tz=Europe/Moscow",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: moscow, Bucket: models.ReportBucketWeek},
+			repoResp: mockRepoResp{
+				timezone: "UTC",
+				summary: []models.SummaryCell{
+					{Start: from.UTC(), End: from.AddDate(0, 0, 7).UTC(), TotalSeconds: 9000},
+				},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedSummary: []models.PeriodSummary{
+				{Period: "2024-W27", Start: from, End: from.AddDate(0, 0, 7), Hours: 2, Minutes: 30, TotalSeconds: 9000},
+			},
+		},
+		{
+			id:   3,
+			name: "Missing from Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Invalid group Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03&group=month",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Period too long Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2020-07-01&to=2024-07-03",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: moscow, Bucket: models.ReportBucketDay},
+			repoResp: mockRepoResp{
+				timezone:  "Europe/Moscow",
+				mockError: errors.New("эта ошибка ломает service"),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   7,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{UserID: 1, From: from, To: to, Location: moscow, Bucket: models.ReportBucketDay},
+			repoResp: mockRepoResp{
+				timezone: "Europe/Moscow",
+				summary:  []models.SummaryCell{{Start: from.UTC(), End: to.UTC(), TotalSeconds: 9000}},
+			},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   8,
+			name: "Success grouped by project",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03&client_id=5&group_by=project",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.ReportFilter{
+				UserID:   1,
+				From:     from,
+				To:       to,
+				Location: moscow,
+				Bucket:   models.ReportBucketDay,
+				ClientID: 5,
+				GroupBy:  models.ReportGroupProject,
+			},
+			repoResp: mockRepoResp{
+				timezone: "Europe/Moscow",
+				summary: []models.SummaryCell{
+					{
+						ReportScope:  models.ReportScope{TaskID: 1, ProjectID: &projectID, ProjectName: "Сайт"},
+						Name:         "написать тестовое",
+						Start:        from.UTC(),
+						End:          from.AddDate(0, 0, 1).UTC(),
+						TotalSeconds: 1800,
+					},
+					{
+						ReportScope:  models.ReportScope{TaskID: 2, ProjectID: &projectID, ProjectName: "Сайт"},
+						Name:         "mockTask1",
+						Start:        from.UTC(),
+						End:          from.AddDate(0, 0, 1).UTC(),
+						TotalSeconds: 3600,
+					},
+					{ReportScope: models.ReportScope{TaskID: 3}, Name: "mockTask2", Start: from.UTC(), End: from.AddDate(0, 0, 1).UTC(), TotalSeconds: 600},
+					{Start: from.AddDate(0, 0, 1).UTC(), End: to.UTC()},
+				},
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedSummary: []models.PeriodSummary{
+				{
+					Period:       "2024-07-01",
+					Start:        from,
+					End:          from.AddDate(0, 0, 1),
+					Hours:        1,
+					Minutes:      40,
+					TotalSeconds: 6000,
+					Groups: []models.ReportGroupTotal{
+						{ReportScope: models.ReportScope{ProjectID: &projectID}, Name: "Сайт", Hours: 1, Minutes: 30, TotalSeconds: 5400},
+						{Name: "Без проекта", Minutes: 10, TotalSeconds: 600},
+					},
+				},
+				{Period: "2024-07-02", Start: from.AddDate(0, 0, 1), End: to},
+			},
+		},
+		{
+			id:   9,
+			name: "Invalid group_by Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03&group_by=user",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   10,
+			name: "Invalid project_id Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03&project_id=abc",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   11,
+			name: "Tag group_by Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/summary?from=2024-07-01&to=2024-07-03&group_by=tag",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockReportsRepo := new(reposmocks.MockReportsRepo)
+
+			mockReportService := services.NewReportService(mockReportsRepo)
+
+			reportHandler := handlers.NewReportHandler(mockReportService, logger)
+
+			mockReportsRepo.On(
+				"FindUserTimezone",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.repoResp.timezone, nil)
+
+			mockReportsRepo.On(
+				"GetSummary",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(tc.repoResp.summary, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/summary", reportHandler.GetSummary).Methods(http.MethodGet)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedSummary != nil {
+				var summary []models.PeriodSummary
+
+				err = json.NewDecoder(rr.Body).Decode(&summary)
+				assert.NoError(t, err)
+				assert.Len(t, summary, len(tc.expectedSummary))
+
+				for i := range summary {
+					assert.Equal(t, tc.expectedSummary[i].Period, summary[i].Period)
+					assert.True(t, tc.expectedSummary[i].Start.Equal(summary[i].Start))
+					assert.True(t, tc.expectedSummary[i].End.Equal(summary[i].End))
+					assert.Equal(t, tc.expectedSummary[i].Hours, summary[i].Hours)
+					assert.Equal(t, tc.expectedSummary[i].Minutes, summary[i].Minutes)
+					assert.Equal(t, tc.expectedSummary[i].Groups, summary[i].Groups)
+				}
+			}
+
+			if tc.callRepo {
+				mockReportsRepo.AssertCalled(t, "GetSummary", mock.Anything, tc.filter)
+			} else {
+				mockReportsRepo.AssertNotCalled(t, "GetSummary", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetTimesheet(t *testing.T) {
+	type mockRepoResp struct {
+		cells     []models.TimesheetCell
+		mockError error
+	}
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, moscow)
+	weekFilter := models.ReportFilter{UserID: 1, From: monday, To: monday.AddDate(0, 0, 7), Location: moscow}
+	dayFilter := models.ReportFilter{UserID: 1, From: monday, To: monday.AddDate(0, 0, 1), Location: moscow}
+
+	// Сессия с 23:00 понедельника до 01:30 вторника разбита запросом на два дня
+	cells := []models.TimesheetCell{
+		{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Day: monday.UTC(), TotalSeconds: 3600},
+		{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Day: monday.AddDate(0, 0, 1).UTC(), TotalSeconds: 5400},
+		{ReportScope: models.ReportScope{TaskID: 2}, Name: "mockTask1", Day: monday.AddDate(0, 0, 1).UTC(), TotalSeconds: 1259},
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		filter         models.ReportFilter
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+		expectedSheet  *models.Timesheet
+	}{
+		{
+			id:   1,
+			name: "Success week",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?week=2026-W42",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         weekFilter,
+			repoResp:       mockRepoResp{cells: cells},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedSheet: &models.Timesheet{
+				Period:   "2026-W42",
+				Timezone: "Europe/Moscow",
+				Days: []string{
+					"2026-10-12", "2026-10-13", "2026-10-14", "2026-10-15", "2026-10-16", "2026-10-17", "2026-10-18",
+				},
+				Rows: []models.TimesheetRow{
+					{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Minutes: []int64{60, 90, 0, 0, 0, 0, 0}, TotalMinutes: 150},
+					{ReportScope: models.ReportScope{TaskID: 2}, Name: "mockTask1", Minutes: []int64{0, 20, 0, 0, 0, 0, 0}, TotalMinutes: 20},
+				},
+				DayTotals:    []int64{60, 110, 0, 0, 0, 0, 0},
+				TotalMinutes: 170,
+			},
+		},
+		{
+			id:   2,
+			name: "Success day",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?date=2026-10-12",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         dayFilter,
+			repoResp:       mockRepoResp{cells: cells[:1]},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedSheet: &models.Timesheet{
+				Period:       "2026-10-12",
+				Timezone:     "Europe/Moscow",
+				Days:         []string{"2026-10-12"},
+				Rows:         []models.TimesheetRow{{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Minutes: []int64{60}, TotalMinutes: 60}},
+				DayTotals:    []int64{60},
+				TotalMinutes: 60,
+			},
+		},
+		{
+			id:   3,
+			name: "Success empty week",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?week=2026-W42",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         weekFilter,
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedSheet: &models.Timesheet{
+				Period:   "2026-W42",
+				Timezone: "Europe/Moscow",
+				Days: []string{
+					"2026-10-12", "2026-10-13", "2026-10-14", "2026-10-15", "2026-10-16", "2026-10-17", "2026-10-18",
+				},
+				Rows:      []models.TimesheetRow{},
+				DayTotals: []int64{0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		{
+			id:   4,
+			name: "Invalid week Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?week=2026-W54",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Invalid date Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?date=12.10.2026",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?week=2026-W42",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         weekFilter,
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   7,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?week=2026-W42",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         weekFilter,
+			repoResp:       mockRepoResp{cells: cells},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   8,
+			name: "Tag group_by Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet?week=2026-W42&group_by=tag",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockReportsRepo := new(reposmocks.MockReportsRepo)
+
+			mockReportService := services.NewReportService(mockReportsRepo)
+
+			reportHandler := handlers.NewReportHandler(mockReportService, logger)
+
+			mockReportsRepo.On(
+				"FindUserTimezone",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return("Europe/Moscow", nil)
+
+			mockReportsRepo.On(
+				"GetTimesheet",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(tc.repoResp.cells, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/timesheet", reportHandler.GetTimesheet).Methods(http.MethodGet)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedSheet != nil {
+				var sheet models.Timesheet
+
+				err = json.NewDecoder(rr.Body).Decode(&sheet)
+				assert.NoError(t, err)
+				assert.Equal(t, *tc.expectedSheet, sheet)
+			}
+
+			if tc.callRepo {
+				mockReportsRepo.AssertCalled(t, "GetTimesheet", mock.Anything, tc.filter)
+			} else {
+				mockReportsRepo.AssertNotCalled(t, "GetTimesheet", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetTimesheetPDF(t *testing.T) {
+	type mockRepoResp struct {
+		timezone  string
+		user      models.User
+		userError error
+		mockError error
+	}
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, moscow)
+	monthFilter := models.ReportFilter{UserID: 1, From: october, To: october.AddDate(0, 1, 0), Location: moscow}
+	midDay := time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)
+
+	cells := []models.TimesheetCell{
+		{ReportScope: models.ReportScope{TaskID: 1}, Name: "написать тестовое", Day: october.AddDate(0, 0, 11).UTC(), TotalSeconds: 3600},
+		{ReportScope: models.ReportScope{TaskID: 2}, Name: "mockTask1", Day: october.AddDate(0, 0, 12).UTC(), TotalSeconds: 1259},
+	}
+
+	user := models.User{ID: 1, PassportNumber: "1234 567890", Surname: "Иванов", Name: "Иван", Timezone: "Europe/Moscow"}
+
+	testCases := []struct {
+		id                 int
+		name               string
+		mockReq            mockRequest
+		filter             models.ReportFilter
+		repoResp           mockRepoResp
+		callRepo           bool
+		expectedStatus     int
+		expectedAttachment string
+	}{
+		{
+			id:   1,
+			name: "Success month",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?month=2026-10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:             monthFilter,
+			repoResp:           mockRepoResp{timezone: "Europe/Moscow", user: user},
+			callRepo:           true,
+			expectedStatus:     http.StatusOK,
+			expectedAttachment: `attachment; filename="timesheet_1_2026-10.pdf"`,
+		},
+		{
+			id:   2,
+			name: "Success from to",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?from=2026-10-01&to=2026-11-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:             monthFilter,
+			repoResp:           mockRepoResp{timezone: "Europe/Moscow", user: user},
+			callRepo:           true,
+			expectedStatus:     http.StatusOK,
+			expectedAttachment: `attachment; filename="timesheet_1_2026-10-01_2026-11-01.pdf"`,
+		},
+		{
+			id:   3,
+			name: "Success to in the middle of a day",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?from=2026-10-01&to=2026-10-10T09:00:00Z",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			// to не округляется до конца дня: время после него запрос не считает
+			filter:             models.ReportFilter{UserID: 1, From: october, To: midDay, Location: moscow},
+			repoResp:           mockRepoResp{timezone: "Europe/Moscow", user: user},
+			callRepo:           true,
+			expectedStatus:     http.StatusOK,
+			expectedAttachment: `attachment; filename="timesheet_1_2026-10-01_2026-10-10.pdf"`,
+		},
+		{
+			id:   4,
+			name: "Invalid month",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?month=2026-13",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Period too long",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?from=2020-01-01&to=2026-01-01",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow"},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "User not found",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?month=2026-10&tz=Europe/Moscow",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         monthFilter,
+			repoResp:       mockRepoResp{userError: repos.ErrUsrNotExists},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   7,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/users/1/timesheet/pdf?month=2026-10",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter:         monthFilter,
+			repoResp:       mockRepoResp{timezone: "Europe/Moscow", mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockReportsRepo := new(reposmocks.MockReportsRepo)
+
+			mockReportService := services.NewReportService(mockReportsRepo)
+
+			reportHandler := handlers.NewReportHandler(mockReportService, logger)
+
+			mockReportsRepo.On(
+				"FindUserTimezone",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.repoResp.timezone, nil)
+
+			mockReportsRepo.On(
+				"GetTimesheet",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(cells, tc.repoResp.mockError)
+
+			mockReportsRepo.On(
+				"FindUserByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				1,
+			).Return(tc.repoResp.user, tc.repoResp.userError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/users/{user_id}/timesheet/pdf", reportHandler.GetTimesheetPDF).Methods(http.MethodGet)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedAttachment != "" {
+				assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+				assert.Equal(t, tc.expectedAttachment, rr.Header().Get("Content-Disposition"))
+				assert.True(t, strings.HasPrefix(rr.Body.String(), "%PDF-1.4"))
+				assert.True(t, strings.HasSuffix(rr.Body.String(), "%%EOF\n"))
+			}
+
+			if tc.callRepo {
+				mockReportsRepo.AssertCalled(t, "GetTimesheet", mock.Anything, tc.filter)
+			} else {
+				mockReportsRepo.AssertNotCalled(t, "GetTimesheet", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package handlers_test
+
+import (
+	"EMTask/internal/handlers"
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/services"
+	"EMTask/tests/mocks/reposmocks"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateTag(t *testing.T) {
+	type mockRepoResp struct {
+		tag       models.Tag
+		mockError error
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		tagName        string
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+		expectedTag    models.Tag
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tags",
+				mockRequestBody:   strings.NewReader(`{"name":" bugfix "}`),
+			},
+			tagName:        "bugfix",
+			repoResp:       mockRepoResp{tag: models.Tag{ID: 3, Name: "bugfix"}},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedTag:    models.Tag{ID: 3, Name: "bugfix"},
+		},
+		{
+			id:   2,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tags",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Comma in name Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tags",
+				mockRequestBody:   strings.NewReader(`{"name":"bugfix,review"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Tag exists Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tags",
+				mockRequestBody:   strings.NewReader(`{"name":"Bugfix"}`),
+			},
+			tagName:        "Bugfix",
+			repoResp:       mockRepoResp{mockError: repos.ErrTagExists},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tags",
+				mockRequestBody:   strings.NewReader(`{"name":"bugfix"}`),
+			},
+			tagName:        "bugfix",
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   6,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tags",
+				mockRequestBody:   strings.NewReader(`{"name":"bugfix"}`),
+			},
+			tagName:        "bugfix",
+			repoResp:       mockRepoResp{tag: models.Tag{ID: 3, Name: "bugfix"}},
+			callRepo:       true,
+			breakWrite:     true,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTagRepo := new(reposmocks.MockTagRepo)
+
+			mockTagService := services.NewTagService(mockTagRepo)
+
+			tagHandler := handlers.NewTagHandler(mockTagService, logger)
+
+			mockTagRepo.On(
+				"CreateTag",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.tagName,
+			).Return(tc.repoResp.tag, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			if tc.breakWrite {
+				tagHandler.CreateTag(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				tagHandler.CreateTag(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.expectedTag.ID != 0 {
+				var tag models.Tag
+
+				err = json.NewDecoder(rr.Body).Decode(&tag)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedTag, tag)
+			}
+
+			if tc.callRepo {
+				mockTagRepo.AssertCalled(t, "CreateTag", mock.Anything, tc.tagName)
+			} else {
+				mockTagRepo.AssertNotCalled(t, "CreateTag", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAttachTag(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		tagID          int
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/tags/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			tagID:          3,
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/tags/asfasf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Task Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/2/tags/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         2,
+			tagID:          3,
+			mockError:      repos.ErrTaskNotFound,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "Tag Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/tags/4",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			tagID:          4,
+			mockError:      repos.ErrTagNotFound,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/tags/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			tagID:          3,
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTagRepo := new(reposmocks.MockTagRepo)
+
+			mockTagService := services.NewTagService(mockTagRepo)
+
+			tagHandler := handlers.NewTagHandler(mockTagService, logger)
+
+			mockTagRepo.On("AttachTag", mock.AnythingOfType("*context.timerCtx"), tc.taskID, tc.tagID).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/tags/{tag_id}", tagHandler.AttachTag).Methods(http.MethodPut)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTagRepo.AssertCalled(t, "AttachTag", mock.Anything, tc.taskID, tc.tagID)
+			} else {
+				mockTagRepo.AssertNotCalled(t, "AttachTag", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDetachTag(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		tagID          int
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/tags/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			tagID:          3,
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/asfasf/tags/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Not Attached Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/tags/4",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			tagID:          4,
+			mockError:      repos.ErrTagNotAttached,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/tags/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			tagID:          3,
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTagRepo := new(reposmocks.MockTagRepo)
+
+			mockTagService := services.NewTagService(mockTagRepo)
+
+			tagHandler := handlers.NewTagHandler(mockTagService, logger)
+
+			mockTagRepo.On("DetachTag", mock.AnythingOfType("*context.timerCtx"), tc.taskID, tc.tagID).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/tags/{tag_id}", tagHandler.DetachTag).Methods(http.MethodDelete)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTagRepo.AssertCalled(t, "DetachTag", mock.Anything, tc.taskID, tc.tagID)
+			} else {
+				mockTagRepo.AssertNotCalled(t, "DetachTag", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
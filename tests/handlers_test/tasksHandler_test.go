@@ -7,6 +7,7 @@ import (
 	"EMTask/internal/services"
 	"EMTask/tests/mocks/reposmocks"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -16,6 +17,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 var mockTask = models.Task{
@@ -26,6 +28,23 @@ var mockTask = models.Task{
 	EndTime:   nil,
 }
 
+var mockProjectID = 9
+
+var mockParentID = 5
+
+var mockEstimate = int64(7200)
+
+var mockEntries = []models.TimeEntry{
+	{
+		ID:              1,
+		TaskID:          1,
+		UserID:          1,
+		StartTime:       time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC),
+		EndTime:         nil,
+		DurationSeconds: 60,
+	},
+}
+
 func TestCreateTask(t *testing.T) {
 	type mockRepoResp struct {
 		task      models.Task
@@ -113,6 +132,26 @@ func TestCreateTask(t *testing.T) {
 		},
 		{
 			id:   5,
+			name: "Service ProjectNotFound Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks",
+				mockRequestBody: strings.NewReader(`{
+					"name":"написать тестовое",
+					"user_id":1,
+					"project_id":9
+				}`),
+			},
+			taskReq: models.NewTaskRequest{Name: "написать тестовое", UserID: 1, ProjectID: &mockProjectID},
+			repoResp: mockRepoResp{
+				task:      models.Task{},
+				mockError: repos.ErrProjectNotFound,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
 			name: "Encode Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
@@ -131,6 +170,61 @@ func TestCreateTask(t *testing.T) {
 			callRepo:       true,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			id:   7,
+			name: "Service ParentNotFound Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks",
+				mockRequestBody: strings.NewReader(`{
+					"name":"написать тестовое",
+					"user_id":1,
+					"parent_id":5
+				}`),
+			},
+			taskReq: models.NewTaskRequest{Name: "написать тестовое", UserID: 1, ParentID: &mockParentID},
+			repoResp: mockRepoResp{
+				task:      models.Task{},
+				mockError: repos.ErrParentTaskNotFound,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   8,
+			name: "Success with estimate",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks",
+				mockRequestBody: strings.NewReader(`{
+					"name":"написать тестовое",
+					"user_id":1,
+					"estimate_seconds":7200
+				}`),
+			},
+			taskReq: models.NewTaskRequest{Name: "написать тестовое", UserID: 1, EstimateSeconds: &mockEstimate},
+			repoResp: mockRepoResp{
+				task:      mockTask,
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   9,
+			name: "Invalid estimate",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks",
+				mockRequestBody: strings.NewReader(`{
+					"name":"написать тестовое",
+					"user_id":1,
+					"estimate_seconds":0
+				}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -144,11 +238,21 @@ func TestCreateTask(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
-			mockTasksRepo.On("AddTask", mock.AnythingOfType("*context.timerCtx"), tc.taskReq.Name, tc.taskReq.UserID).Return(tc.repoResp.task, tc.repoResp.mockError)
+			mockTasksRepo.On(
+				"AddTask",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskReq.Name,
+				tc.taskReq.UserID,
+				tc.taskReq.ProjectID,
+				tc.taskReq.ParentID,
+				tc.taskReq.EstimateSeconds,
+			).Return(tc.repoResp.task, tc.repoResp.mockError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
 			if err != nil {
@@ -168,7 +272,16 @@ func TestCreateTask(t *testing.T) {
 			}
 
 			if tc.callRepo {
-				mockTasksRepo.AssertCalled(t, "AddTask", mock.Anything, tc.taskReq.Name, tc.taskReq.UserID)
+				mockTasksRepo.AssertCalled(
+					t,
+					"AddTask",
+					mock.Anything,
+					tc.taskReq.Name,
+					tc.taskReq.UserID,
+					tc.taskReq.ProjectID,
+					tc.taskReq.ParentID,
+					tc.taskReq.EstimateSeconds,
+				)
 			}
 		})
 	}
@@ -176,8 +289,24 @@ func TestCreateTask(t *testing.T) {
 
 func TestGetTaskByID(t *testing.T) {
 	type mockRepoResp struct {
-		task      models.Task
-		mockError error
+		task           models.Task
+		mockError      error
+		assigneesError error
+		entriesError   error
+	}
+
+	mockAssignees := []models.TaskAssignee{
+		{UserID: 1, UserFullName: "Иванов Иван Иванович", TotalSeconds: 3600},
+		{UserID: 2, UserFullName: "Петров Пётр Петрович", TotalSeconds: 1800},
+	}
+
+	taskID, subtaskID := 1, 2
+
+	mockTree := []models.TaskTree{
+		{ID: 1, Name: "написать тестовое", Status: models.TaskInProgress, TotalSeconds: 3600, RollupSeconds: 6300},
+		{ID: 2, Name: "хендлеры", ParentID: &taskID, Status: models.TaskTodo, TotalSeconds: 1800, RollupSeconds: 2400},
+		{ID: 3, Name: "тесты", ParentID: &taskID, Status: models.TaskDone, TotalSeconds: 300, RollupSeconds: 300},
+		{ID: 4, Name: "моки", ParentID: &subtaskID, Status: models.TaskTodo, TotalSeconds: 600, RollupSeconds: 600},
 	}
 
 	testCases := []struct {
@@ -186,6 +315,7 @@ func TestGetTaskByID(t *testing.T) {
 		mockReq        mockRequest
 		reqUserID      int
 		repoResp       mockRepoResp
+		withSubtasks   bool
 		callRepo       bool
 		breakWrite     bool
 		expectedStatus int
@@ -251,6 +381,65 @@ func TestGetTaskByID(t *testing.T) {
 		},
 		{
 			id:   5,
+			name: "Entries error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			reqUserID: 1,
+			repoResp: mockRepoResp{
+				task:         mockTask,
+				entriesError: errors.New("эта ошибка ломает сервис"),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   6,
+			name: "Assignees error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			reqUserID: 1,
+			repoResp: mockRepoResp{
+				task:           mockTask,
+				assigneesError: errors.New("эта ошибка ломает сервис"),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   7,
+			name: "Subtasks tree",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/1?include=subtasks",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			reqUserID: 1,
+			repoResp: mockRepoResp{
+				task: mockTask,
+			},
+			withSubtasks:   true,
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   8,
+			name: "Invalid include",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/1?include=parents",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   9,
 			name: "Encode error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodGet,
@@ -279,11 +468,16 @@ func TestGetTaskByID(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
 			mockTasksRepo.On("FindTaskByID", mock.AnythingOfType("*context.timerCtx"), tc.reqUserID).Return(tc.repoResp.task, tc.repoResp.mockError)
+			mockTasksRepo.On("FindTaskAssignees", mock.AnythingOfType("*context.timerCtx"), tc.reqUserID).Return(mockAssignees, tc.repoResp.assigneesError)
+			mockTasksRepo.On("FindTaskTree", mock.AnythingOfType("*context.timerCtx"), tc.reqUserID).Return(mockTree, nil)
+			mockEntriesRepo.On("FindEntriesByTaskID", mock.AnythingOfType("*context.timerCtx"), tc.reqUserID).Return(mockEntries, tc.repoResp.entriesError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
 			if err != nil {
@@ -305,6 +499,25 @@ func TestGetTaskByID(t *testing.T) {
 				assert.Equal(t, tc.expectedStatus, rr.Code)
 			}
 
+			if tc.expectedStatus == http.StatusOK {
+				var task models.Task
+
+				err = json.NewDecoder(rr.Body).Decode(&task)
+				assert.NoError(t, err)
+				assert.Equal(t, mockAssignees, task.Assignees)
+
+				if tc.withSubtasks {
+					assert.Equal(t, int64(6300), task.RollupSeconds)
+					assert.Len(t, task.Subtasks, 2)
+					assert.Equal(t, 2, task.Subtasks[0].ID)
+					assert.Equal(t, []models.TaskTree{mockTree[3]}, task.Subtasks[0].Subtasks)
+					assert.Empty(t, task.Subtasks[1].Subtasks)
+				} else {
+					assert.Empty(t, task.Subtasks)
+					mockTasksRepo.AssertNotCalled(t, "FindTaskTree", mock.Anything, mock.Anything)
+				}
+			}
+
 			if tc.callRepo {
 				mockTasksRepo.AssertCalled(t, "FindTaskByID", mock.Anything, tc.reqUserID)
 			}
@@ -381,7 +594,9 @@ func TestDeleteTaskByID(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
@@ -424,6 +639,7 @@ func TestGetUsersTasks(t *testing.T) {
 		usrID     int
 		startTime string
 		endTime   string
+		filter    models.TaskFilter
 	}
 
 	mockTask1 := models.Task{
@@ -545,6 +761,55 @@ func TestGetUsersTasks(t *testing.T) {
 			callRepo:       true,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			id:   7,
+			name: "Tags filter",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/user/tasks?user_id=1&tags=Bugfix,%20review,,bugfix",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockFindReq: findTasksReq{
+				usrID:  1,
+				filter: models.TaskFilter{Tags: []string{"bugfix", "review"}},
+			},
+			repoResp: mockRepoResp{
+				tasks:     []models.Task{mockTask1},
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   8,
+			name: "Status filter",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/user/tasks?user_id=1&status=todo,in_progress,todo",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockFindReq: findTasksReq{
+				usrID:  1,
+				filter: models.TaskFilter{Statuses: []models.TaskStatus{models.TaskTodo, models.TaskInProgress}},
+			},
+			repoResp: mockRepoResp{
+				tasks:     []models.Task{mockTask},
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   9,
+			name: "Unknown status",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/user/tasks?user_id=1&status=blocked",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -558,7 +823,9 @@ func TestGetUsersTasks(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
@@ -568,6 +835,7 @@ func TestGetUsersTasks(t *testing.T) {
 				tc.mockFindReq.usrID,
 				tc.mockFindReq.startTime,
 				tc.mockFindReq.endTime,
+				tc.mockFindReq.filter,
 			).Return(tc.repoResp.tasks, tc.repoResp.mockError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
@@ -595,6 +863,7 @@ func TestGetUsersTasks(t *testing.T) {
 					tc.mockFindReq.usrID,
 					tc.mockFindReq.startTime,
 					tc.mockFindReq.endTime,
+					tc.mockFindReq.filter,
 				)
 			}
 		})
@@ -603,12 +872,15 @@ func TestGetUsersTasks(t *testing.T) {
 
 func TestStartTracker(t *testing.T) {
 	type mockRepoResp struct {
+		state     models.TimerState
+		stopped   []int
 		mockError error
 	}
 
 	type mockReqParam struct {
 		taskID int
 		usrID  int
+		from   []models.TimerState
 	}
 
 	testCases := []struct {
@@ -632,15 +904,39 @@ func TestStartTracker(t *testing.T) {
 			mockReqParams: mockReqParam{
 				taskID: 1,
 				usrID:  1,
+				from:   []models.TimerState{models.TimerIdle, models.TimerStopped},
 			},
 			repoResp: mockRepoResp{
+				state:     models.TimerStopped,
+				stopped:   []int{2},
 				mockError: nil,
 			},
 			callRepo:       true,
-			expectedStatus: http.StatusNoContent,
+			expectedStatus: http.StatusOK,
 		},
 		{
 			id:   2,
+			name: "Another Timer Running Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerIdle, models.TimerStopped},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerStopped,
+				stopped:   []int{2},
+				mockError: repos.ErrAnotherTimerRunning,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   3,
 			name: "Atoi UserID Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
@@ -651,7 +947,7 @@ func TestStartTracker(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			id:   3,
+			id:   4,
 			name: "Atoi TaskID Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
@@ -662,7 +958,7 @@ func TestStartTracker(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			id:   4,
+			id:   5,
 			name: "Service NotFound Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
@@ -672,6 +968,7 @@ func TestStartTracker(t *testing.T) {
 			mockReqParams: mockReqParam{
 				taskID: 1,
 				usrID:  1,
+				from:   []models.TimerState{models.TimerIdle, models.TimerStopped},
 			},
 			repoResp: mockRepoResp{
 				mockError: repos.ErrTaskNotFound,
@@ -680,7 +977,27 @@ func TestStartTracker(t *testing.T) {
 			expectedStatus: http.StatusNotFound,
 		},
 		{
-			id:   5,
+			id:   6,
+			name: "Timer Conflict Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerIdle, models.TimerStopped},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerRunning,
+				mockError: repos.ErrTimerConflict,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   7,
 			name: "Service Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
@@ -690,6 +1007,7 @@ func TestStartTracker(t *testing.T) {
 			mockReqParams: mockReqParam{
 				taskID: 1,
 				usrID:  1,
+				from:   []models.TimerState{models.TimerIdle, models.TimerStopped},
 			},
 			repoResp: mockRepoResp{
 				mockError: errors.New("эта ошибка ломает service"),
@@ -697,6 +1015,25 @@ func TestStartTracker(t *testing.T) {
 			callRepo:       true,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			id:   8,
+			name: "Task Not Trackable Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerIdle, models.TimerStopped},
+			},
+			repoResp: mockRepoResp{
+				mockError: repos.ErrTaskStatusConflict,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -710,7 +1047,9 @@ func TestStartTracker(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
@@ -719,7 +1058,10 @@ func TestStartTracker(t *testing.T) {
 				mock.AnythingOfType("*context.timerCtx"),
 				tc.mockReqParams.taskID,
 				tc.mockReqParams.usrID,
-			).Return(tc.repoResp.mockError)
+				mock.Anything,
+				mock.Anything,
+				models.TimerPolicySwitch,
+			).Return(tc.repoResp.state, tc.repoResp.stopped, tc.repoResp.mockError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
 			if err != nil {
@@ -748,20 +1090,25 @@ func TestStartTracker(t *testing.T) {
 					mock.Anything,
 					tc.mockReqParams.taskID,
 					tc.mockReqParams.usrID,
+					tc.mockReqParams.from,
+					[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+					models.TimerPolicySwitch,
 				)
 			}
 		})
 	}
 }
 
-func TestStopTracker(t *testing.T) {
+func TestPauseTracker(t *testing.T) {
 	type mockRepoResp struct {
+		state     models.TimerState
 		mockError error
 	}
 
 	type mockReqParam struct {
 		taskID int
 		usrID  int
+		from   []models.TimerState
 	}
 
 	testCases := []struct {
@@ -779,14 +1126,16 @@ func TestStopTracker(t *testing.T) {
 			name: "Success",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
-				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestURL:    "/user/task/pause/1/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
 			mockReqParams: mockReqParam{
 				taskID: 1,
 				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning},
 			},
 			repoResp: mockRepoResp{
+				state:     models.TimerPaused,
 				mockError: nil,
 			},
 			callRepo:       true,
@@ -797,7 +1146,7 @@ func TestStopTracker(t *testing.T) {
 			name: "Atoi UserID Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
-				mockRequestURL:    "/user/task/track/safafs/1",
+				mockRequestURL:    "/user/task/pause/safafs/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
 			callRepo:       false,
@@ -808,7 +1157,7 @@ func TestStopTracker(t *testing.T) {
 			name: "Atoi TaskID Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
-				mockRequestURL:    "/user/task/track/1/sadasd",
+				mockRequestURL:    "/user/task/pause/1/sadasd",
 				mockRequestBody:   strings.NewReader(``),
 			},
 			callRepo:       false,
@@ -819,12 +1168,13 @@ func TestStopTracker(t *testing.T) {
 			name: "Service NotFound Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
-				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestURL:    "/user/task/pause/1/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
 			mockReqParams: mockReqParam{
 				taskID: 1,
 				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning},
 			},
 			repoResp: mockRepoResp{
 				mockError: repos.ErrTaskNotFound,
@@ -834,15 +1184,36 @@ func TestStopTracker(t *testing.T) {
 		},
 		{
 			id:   5,
+			name: "Timer Conflict Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/pause/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerPaused,
+				mockError: repos.ErrTimerConflict,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   6,
 			name: "Service Error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
-				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestURL:    "/user/task/pause/1/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
 			mockReqParams: mockReqParam{
 				taskID: 1,
 				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning},
 			},
 			repoResp: mockRepoResp{
 				mockError: errors.New("эта ошибка ломает service"),
@@ -863,16 +1234,19 @@ func TestStopTracker(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
 			mockTasksRepo.On(
-				"StopTimeTracker",
+				"PauseTimeTracker",
 				mock.AnythingOfType("*context.timerCtx"),
 				tc.mockReqParams.taskID,
 				tc.mockReqParams.usrID,
-			).Return(tc.repoResp.mockError)
+				mock.Anything,
+			).Return(tc.repoResp.state, tc.repoResp.mockError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
 			if err != nil {
@@ -884,7 +1258,7 @@ func TestStopTracker(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			router := mux.NewRouter()
-			router.HandleFunc("/user/task/track/{user_id}/{task_id}", taskHandler.StopTracker).Methods(http.MethodPost)
+			router.HandleFunc("/user/task/pause/{user_id}/{task_id}", taskHandler.PauseTracker).Methods(http.MethodPost)
 
 			if tc.breakWrite {
 				router.ServeHTTP(mockWriter, req)
@@ -897,41 +1271,35 @@ func TestStopTracker(t *testing.T) {
 			if tc.callRepo {
 				mockTasksRepo.AssertCalled(
 					t,
-					"StopTimeTracker",
+					"PauseTimeTracker",
 					mock.Anything,
 					tc.mockReqParams.taskID,
 					tc.mockReqParams.usrID,
+					tc.mockReqParams.from,
 				)
 			}
 		})
 	}
 }
 
-func TestGetAllTasks(t *testing.T) {
+func TestResumeTracker(t *testing.T) {
 	type mockRepoResp struct {
-		tasks     []models.Task
+		state     models.TimerState
+		stopped   []int
 		mockError error
 	}
 
-	mockTask1 := models.Task{
-		ID:        2,
-		Name:      "mockTask1",
-		UserID:    1,
-		StartTime: nil,
-		EndTime:   nil,
-	}
-	mockTask2 := models.Task{
-		ID:        3,
-		Name:      "mockTask2",
-		UserID:    2,
-		StartTime: nil,
-		EndTime:   nil,
+	type mockReqParam struct {
+		taskID int
+		usrID  int
+		from   []models.TimerState
 	}
 
 	testCases := []struct {
 		id             int
 		name           string
 		mockReq        mockRequest
+		mockReqParams  mockReqParam
 		repoResp       mockRepoResp
 		callRepo       bool
 		breakWrite     bool
@@ -941,12 +1309,18 @@ func TestGetAllTasks(t *testing.T) {
 			id:   1,
 			name: "Success",
 			mockReq: mockRequest{
-				mockRequestMethod: http.MethodGet,
-				mockRequestURL:    "/tasks",
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/1/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerPaused},
+			},
 			repoResp: mockRepoResp{
-				tasks:     []models.Task{mockTask, mockTask1},
+				state:     models.TimerPaused,
+				stopped:   []int{2},
 				mockError: nil,
 			},
 			callRepo:       true,
@@ -954,32 +1328,289 @@ func TestGetAllTasks(t *testing.T) {
 		},
 		{
 			id:   2,
+			name: "Another Timer Running Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerPaused},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerPaused,
+				stopped:   []int{2},
+				mockError: repos.ErrAnotherTimerRunning,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   3,
+			name: "Atoi UserID Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/safafs/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Atoi TaskID Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/1/sadasd",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Service NotFound Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerPaused},
+			},
+			repoResp: mockRepoResp{
+				mockError: repos.ErrTaskNotFound,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   6,
+			name: "Timer Conflict Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerPaused},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerStopped,
+				mockError: repos.ErrTimerConflict,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   7,
 			name: "Service Error",
 			mockReq: mockRequest{
-				mockRequestMethod: http.MethodGet,
-				mockRequestURL:    "/tasks",
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/resume/1/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerPaused},
+			},
 			repoResp: mockRepoResp{
-				tasks:     nil,
 				mockError: errors.New("эта ошибка ломает service"),
 			},
 			callRepo:       true,
 			expectedStatus: http.StatusInternalServerError,
 		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"ResumeTimeTracker",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.mockReqParams.taskID,
+				tc.mockReqParams.usrID,
+				mock.Anything,
+				mock.Anything,
+				models.TimerPolicySwitch,
+			).Return(tc.repoResp.state, tc.repoResp.stopped, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/user/task/resume/{user_id}/{task_id}", taskHandler.ResumeTracker).Methods(http.MethodPost)
+
+			if tc.breakWrite {
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(
+					t,
+					"ResumeTimeTracker",
+					mock.Anything,
+					tc.mockReqParams.taskID,
+					tc.mockReqParams.usrID,
+					tc.mockReqParams.from,
+					[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+					models.TimerPolicySwitch,
+				)
+			}
+		})
+	}
+}
+
+func TestStopTracker(t *testing.T) {
+	type mockRepoResp struct {
+		state     models.TimerState
+		mockError error
+	}
+
+	type mockReqParam struct {
+		taskID int
+		usrID  int
+		from   []models.TimerState
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		mockReqParams  mockReqParam
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning, models.TimerPaused},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerRunning,
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi UserID Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/safafs/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			id:   3,
-			name: "Encode Error",
+			name: "Atoi TaskID Error",
 			mockReq: mockRequest{
-				mockRequestMethod: http.MethodGet,
-				mockRequestURL:    "/tasks",
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/sadasd",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Service NotFound Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
 				mockRequestBody:   strings.NewReader(``),
 			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning, models.TimerPaused},
+			},
 			repoResp: mockRepoResp{
-				tasks:     []models.Task{mockTask, mockTask1, mockTask2},
-				mockError: nil,
+				mockError: repos.ErrTaskNotFound,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Timer Conflict Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning, models.TimerPaused},
+			},
+			repoResp: mockRepoResp{
+				state:     models.TimerStopped,
+				mockError: repos.ErrTimerConflict,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user/task/track/1/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			mockReqParams: mockReqParam{
+				taskID: 1,
+				usrID:  1,
+				from:   []models.TimerState{models.TimerRunning, models.TimerPaused},
+			},
+			repoResp: mockRepoResp{
+				mockError: errors.New("эта ошибка ломает service"),
 			},
-			breakWrite:     true,
 			callRepo:       true,
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -996,14 +1627,19 @@ func TestGetAllTasks(t *testing.T) {
 
 			mockTasksRepo := new(reposmocks.MockTasksRepo)
 
-			mockTaskService := services.NewTaskService(mockTasksRepo)
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
 
 			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
 
 			mockTasksRepo.On(
-				"GetAllTasks",
+				"StopTimeTracker",
 				mock.AnythingOfType("*context.timerCtx"),
-			).Return(tc.repoResp.tasks, tc.repoResp.mockError)
+				tc.mockReqParams.taskID,
+				tc.mockReqParams.usrID,
+				mock.Anything,
+			).Return(tc.repoResp.state, tc.repoResp.mockError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
 			if err != nil {
@@ -1014,21 +1650,1524 @@ func TestGetAllTasks(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
+			router := mux.NewRouter()
+			router.HandleFunc("/user/task/track/{user_id}/{task_id}", taskHandler.StopTracker).Methods(http.MethodPost)
+
 			if tc.breakWrite {
-				taskHandler.GetAllTasks(mockWriter, req)
+				router.ServeHTTP(mockWriter, req)
 				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
 			} else {
-				taskHandler.GetAllTasks(rr, req)
+				router.ServeHTTP(rr, req)
 				assert.Equal(t, tc.expectedStatus, rr.Code)
 			}
 
 			if tc.callRepo {
 				mockTasksRepo.AssertCalled(
 					t,
-					"GetAllTasks",
+					"StopTimeTracker",
 					mock.Anything,
+					tc.mockReqParams.taskID,
+					tc.mockReqParams.usrID,
+					tc.mockReqParams.from,
 				)
 			}
 		})
 	}
 }
+
+func TestGetAllTasks(t *testing.T) {
+	type mockRepoResp struct {
+		tasks     []models.Task
+		mockError error
+	}
+
+	mockTask1 := models.Task{
+		ID:        2,
+		Name:      "mockTask1",
+		UserID:    1,
+		StartTime: nil,
+		EndTime:   nil,
+	}
+	mockTask2 := models.Task{
+		ID:        3,
+		Name:      "mockTask2",
+		UserID:    2,
+		StartTime: nil,
+		EndTime:   nil,
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		filter         models.TaskFilter
+		repoResp       mockRepoResp
+		callRepo       bool
+		breakWrite     bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp: mockRepoResp{
+				tasks:     []models.Task{mockTask, mockTask1},
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp: mockRepoResp{
+				tasks:     nil,
+				mockError: errors.New("эта ошибка ломает service"),
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   3,
+			name: "Encode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			repoResp: mockRepoResp{
+				tasks:     []models.Task{mockTask, mockTask1, mockTask2},
+				mockError: nil,
+			},
+			breakWrite:     true,
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:   4,
+			name: "Tags filter",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks?tags=Meeting",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.TaskFilter{Tags: []string{"meeting"}},
+			repoResp: mockRepoResp{
+				tasks:     []models.Task{mockTask2},
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   5,
+			name: "Status filter",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks?status=done&tags=meeting",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			filter: models.TaskFilter{Tags: []string{"meeting"}, Statuses: []models.TaskStatus{models.TaskDone}},
+			repoResp: mockRepoResp{
+				tasks:     []models.Task{mockTask2},
+				mockError: nil,
+			},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   6,
+			name: "Unknown status",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks?status=closed",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"GetAllTasks",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.filter,
+			).Return(tc.repoResp.tasks, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockWriter := &errorResponseWriter{}
+
+			rr := httptest.NewRecorder()
+
+			if tc.breakWrite {
+				taskHandler.GetAllTasks(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+			} else {
+				taskHandler.GetAllTasks(rr, req)
+				assert.Equal(t, tc.expectedStatus, rr.Code)
+			}
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(
+					t,
+					"GetAllTasks",
+					mock.Anything,
+					tc.filter,
+				)
+			}
+		})
+	}
+}
+
+func TestGetAllTasksExport(t *testing.T) {
+	startTime := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	tasks := []models.Task{
+		{
+			ID:           1,
+			Name:         "написать тестовое",
+			UserID:       1,
+			UserFullName: "Иванов Иван Иванович",
+			StartTime:    &startTime,
+			State:        models.TimerRunning,
+			TotalSeconds: 5400,
+		},
+		{ID: 2, Name: "mockTask1", UserID: 2, UserFullName: "Петров Пётр", State: models.TimerIdle},
+	}
+
+	testCases := []struct {
+		id                  int
+		name                string
+		mockReq             mockRequest
+		accept              string
+		callRepo            bool
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        []string
+	}{
+		{
+			id:   1,
+			name: "Success CSV by Accept",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			accept:              "text/csv",
+			callRepo:            true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: []string{
+				"Иванов Иван Иванович,написать тестовое,2024-07-01 10:00:00,,running,1 ч 30 мин,1.50\n",
+				"Петров Пётр,mockTask1,,,idle,0 мин,0.00\n",
+				"Итого,,,,,1 ч 30 мин,1.50\n",
+			},
+		},
+		{
+			id:   2,
+			name: "Success JSON format overrides Accept",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks?format=json",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			accept:         "text/csv",
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"user_full_name":"Петров Пётр"`},
+		},
+		{
+			id:   3,
+			name: "Invalid format",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks?format=xml",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"GetAllTasks",
+				mock.AnythingOfType("*context.timerCtx"),
+				mock.Anything,
+			).Return(tasks, nil)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks", taskHandler.GetAllTasks).Methods(http.MethodGet)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, rr.Header().Get("Content-Type"))
+			}
+
+			for _, part := range tc.expectedBody {
+				assert.Contains(t, rr.Body.String(), part)
+			}
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "GetAllTasks", mock.Anything, mock.Anything)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "GetAllTasks", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSetTaskProject(t *testing.T) {
+	type mockRepoResp struct {
+		setError  error
+		task      models.Task
+		findError error
+	}
+
+	projectTask := mockTask
+	projectTask.ProjectID = &mockProjectID
+	projectTask.ProjectName = "Сайт"
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		projectID      *int
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/project",
+				mockRequestBody:   strings.NewReader(`{"project_id":9}`),
+			},
+			taskID:         1,
+			projectID:      &mockProjectID,
+			repoResp:       mockRepoResp{task: projectTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Success detach",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/project",
+				mockRequestBody:   strings.NewReader(`{"project_id":null}`),
+			},
+			taskID:         1,
+			repoResp:       mockRepoResp{task: mockTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   3,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/asfasf/project",
+				mockRequestBody:   strings.NewReader(`{"project_id":9}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/project",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Project not found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/project",
+				mockRequestBody:   strings.NewReader(`{"project_id":9}`),
+			},
+			taskID:         1,
+			projectID:      &mockProjectID,
+			repoResp:       mockRepoResp{setError: repos.ErrProjectNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   6,
+			name: "Task not found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/2/project",
+				mockRequestBody:   strings.NewReader(`{"project_id":9}`),
+			},
+			taskID:         2,
+			projectID:      &mockProjectID,
+			repoResp:       mockRepoResp{setError: repos.ErrTaskNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   7,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/project",
+				mockRequestBody:   strings.NewReader(`{"project_id":9}`),
+			},
+			taskID:         1,
+			projectID:      &mockProjectID,
+			repoResp:       mockRepoResp{findError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"SetTaskProject",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+				tc.projectID,
+			).Return(tc.repoResp.setError)
+
+			mockTasksRepo.On(
+				"FindTaskByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+			).Return(tc.repoResp.task, tc.repoResp.findError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/project", taskHandler.SetTaskProject).Methods(http.MethodPut)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "SetTaskProject", mock.Anything, tc.taskID, tc.projectID)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "SetTaskProject", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCreateSubtask(t *testing.T) {
+	type mockRepoResp struct {
+		task      models.Task
+		mockError error
+	}
+
+	subtask := mockTask
+	subtask.ID = 6
+	subtask.ParentID = &mockParentID
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskReq        models.NewTaskRequest
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks/5/subtasks",
+				mockRequestBody:   strings.NewReader(`{"name":"написать тестовое","user_id":1,"parent_id":7}`),
+			},
+			taskReq:        models.NewTaskRequest{Name: "написать тестовое", UserID: 1},
+			repoResp:       mockRepoResp{task: subtask},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks/asfasf/subtasks",
+				mockRequestBody:   strings.NewReader(`{"name":"написать тестовое","user_id":1}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks/5/subtasks",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Parent Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks/5/subtasks",
+				mockRequestBody:   strings.NewReader(`{"name":"написать тестовое","user_id":1}`),
+			},
+			taskReq:        models.NewTaskRequest{Name: "написать тестовое", UserID: 1},
+			repoResp:       mockRepoResp{mockError: repos.ErrParentTaskNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "User Not Exists Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks/5/subtasks",
+				mockRequestBody:   strings.NewReader(`{"name":"написать тестовое","user_id":1}`),
+			},
+			taskReq:        models.NewTaskRequest{Name: "написать тестовое", UserID: 1},
+			repoResp:       mockRepoResp{mockError: repos.ErrUsrNotExists},
+			callRepo:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/tasks/5/subtasks",
+				mockRequestBody:   strings.NewReader(`{"name":"написать тестовое","user_id":1}`),
+			},
+			taskReq:        models.NewTaskRequest{Name: "написать тестовое", UserID: 1},
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"AddTask",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskReq.Name,
+				tc.taskReq.UserID,
+				tc.taskReq.ProjectID,
+				&mockParentID,
+				tc.taskReq.EstimateSeconds,
+			).Return(tc.repoResp.task, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/subtasks", taskHandler.CreateSubtask).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "AddTask", mock.Anything, tc.taskReq.Name, tc.taskReq.UserID, tc.taskReq.ProjectID, &mockParentID, tc.taskReq.EstimateSeconds)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "AddTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSetTaskParent(t *testing.T) {
+	type mockRepoResp struct {
+		setError  error
+		task      models.Task
+		findError error
+	}
+
+	movedTask := mockTask
+	movedTask.ParentID = &mockParentID
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		parentID       *int
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/parent",
+				mockRequestBody:   strings.NewReader(`{"parent_id":5}`),
+			},
+			taskID:         1,
+			parentID:       &mockParentID,
+			repoResp:       mockRepoResp{task: movedTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Success to top level",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/parent",
+				mockRequestBody:   strings.NewReader(`{"parent_id":null}`),
+			},
+			taskID:         1,
+			repoResp:       mockRepoResp{task: mockTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   3,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/parent",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Parent Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/parent",
+				mockRequestBody:   strings.NewReader(`{"parent_id":5}`),
+			},
+			taskID:         1,
+			parentID:       &mockParentID,
+			repoResp:       mockRepoResp{setError: repos.ErrParentTaskNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Cycle Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/parent",
+				mockRequestBody:   strings.NewReader(`{"parent_id":5}`),
+			},
+			taskID:         1,
+			parentID:       &mockParentID,
+			repoResp:       mockRepoResp{setError: repos.ErrTaskCycle},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   6,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/parent",
+				mockRequestBody:   strings.NewReader(`{"parent_id":5}`),
+			},
+			taskID:         1,
+			parentID:       &mockParentID,
+			repoResp:       mockRepoResp{findError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"SetTaskParent",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+				tc.parentID,
+			).Return(tc.repoResp.setError)
+
+			mockTasksRepo.On(
+				"FindTaskByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+			).Return(tc.repoResp.task, tc.repoResp.findError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/parent", taskHandler.SetTaskParent).Methods(http.MethodPut)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "SetTaskParent", mock.Anything, tc.taskID, tc.parentID)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "SetTaskParent", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSetTaskEstimate(t *testing.T) {
+	type mockRepoResp struct {
+		setError  error
+		task      models.Task
+		findError error
+	}
+
+	estimatedTask := mockTask
+	estimatedTask.EstimateSeconds = &mockEstimate
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		estimate       *int64
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/estimate",
+				mockRequestBody:   strings.NewReader(`{"estimate_seconds":7200}`),
+			},
+			taskID:         1,
+			estimate:       &mockEstimate,
+			repoResp:       mockRepoResp{task: estimatedTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Success remove estimate",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/estimate",
+				mockRequestBody:   strings.NewReader(`{"estimate_seconds":null}`),
+			},
+			taskID:         1,
+			repoResp:       mockRepoResp{task: mockTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   3,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/asfasf/estimate",
+				mockRequestBody:   strings.NewReader(`{"estimate_seconds":7200}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/estimate",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Negative estimate",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/estimate",
+				mockRequestBody:   strings.NewReader(`{"estimate_seconds":-60}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/estimate",
+				mockRequestBody:   strings.NewReader(`{"estimate_seconds":7200}`),
+			},
+			taskID:         1,
+			estimate:       &mockEstimate,
+			repoResp:       mockRepoResp{setError: repos.ErrTaskNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   7,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/estimate",
+				mockRequestBody:   strings.NewReader(`{"estimate_seconds":7200}`),
+			},
+			taskID:         1,
+			estimate:       &mockEstimate,
+			repoResp:       mockRepoResp{findError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"SetTaskEstimate",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+				tc.estimate,
+			).Return(tc.repoResp.setError)
+
+			mockTasksRepo.On(
+				"FindTaskByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+			).Return(tc.repoResp.task, tc.repoResp.findError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/estimate", taskHandler.SetTaskEstimate).Methods(http.MethodPut)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "SetTaskEstimate", mock.Anything, tc.taskID, tc.estimate)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "SetTaskEstimate", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetOverBudgetTasks(t *testing.T) {
+	type mockRepoResp struct {
+		tasks     []models.TaskBudget
+		mockError error
+	}
+
+	mockBudgets := []models.TaskBudget{
+		{
+			ID:               1,
+			Name:             "написать тестовое",
+			UserID:           1,
+			UserFullName:     "Иванов Иван Иванович",
+			Status:           models.TaskInProgress,
+			EstimateSeconds:  3600,
+			TotalSeconds:     4500,
+			RemainingSeconds: -900,
+			UsedPercent:      125,
+		},
+	}
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		threshold      int
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success default threshold",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/over-budget",
+			},
+			threshold:      services.DefaultBudgetThreshold,
+			repoResp:       mockRepoResp{tasks: mockBudgets},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Success custom threshold",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/over-budget?threshold=80",
+			},
+			threshold:      80,
+			repoResp:       mockRepoResp{tasks: mockBudgets},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   3,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/over-budget?threshold=много",
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Invalid threshold",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/over-budget?threshold=0",
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodGet,
+				mockRequestURL:    "/tasks/over-budget",
+			},
+			threshold:      services.DefaultBudgetThreshold,
+			repoResp:       mockRepoResp{mockError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"FindOverBudgetTasks",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.threshold,
+			).Return(tc.repoResp.tasks, tc.repoResp.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			taskHandler.GetOverBudgetTasks(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "FindOverBudgetTasks", mock.Anything, tc.threshold)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "FindOverBudgetTasks", mock.Anything, mock.Anything)
+			}
+
+			if tc.expectedStatus == http.StatusOK {
+				var tasks []models.TaskBudget
+
+				err = json.NewDecoder(rr.Body).Decode(&tasks)
+				assert.NoError(t, err)
+				assert.Equal(t, mockBudgets, tasks)
+			}
+		})
+	}
+}
+
+func TestSetTaskStatus(t *testing.T) {
+	type mockRepoResp struct {
+		from      models.TaskStatus
+		setError  error
+		task      models.Task
+		findError error
+	}
+
+	doneTask := mockTask
+	doneTask.Status = models.TaskDone
+
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		status         models.TaskStatus
+		from           []models.TaskStatus
+		stopTimer      bool
+		repoResp       mockRepoResp
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success done",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/status",
+				mockRequestBody:   strings.NewReader(`{"status":"done"}`),
+			},
+			taskID:         1,
+			status:         models.TaskDone,
+			from:           []models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+			stopTimer:      true,
+			repoResp:       mockRepoResp{from: models.TaskInProgress, task: doneTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   2,
+			name: "Success reopen",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/status",
+				mockRequestBody:   strings.NewReader(`{"status":"in_progress"}`),
+			},
+			taskID:         1,
+			status:         models.TaskInProgress,
+			from:           []models.TaskStatus{models.TaskTodo, models.TaskDone},
+			stopTimer:      false,
+			repoResp:       mockRepoResp{from: models.TaskDone, task: mockTask},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			id:   3,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/asfasf/status",
+				mockRequestBody:   strings.NewReader(`{"status":"done"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   4,
+			name: "Decode Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/status",
+				mockRequestBody:   strings.NewReader(`{Это я сломал decode}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
+			name: "Unknown status Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/status",
+				mockRequestBody:   strings.NewReader(`{"status":"blocked"}`),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   6,
+			name: "Task not found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/2/status",
+				mockRequestBody:   strings.NewReader(`{"status":"done"}`),
+			},
+			taskID:         2,
+			status:         models.TaskDone,
+			from:           []models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+			stopTimer:      true,
+			repoResp:       mockRepoResp{setError: repos.ErrTaskNotFound},
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   7,
+			name: "Transition Conflict Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/status",
+				mockRequestBody:   strings.NewReader(`{"status":"done"}`),
+			},
+			taskID:         1,
+			status:         models.TaskDone,
+			from:           []models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+			stopTimer:      true,
+			repoResp:       mockRepoResp{from: models.TaskArchived, setError: repos.ErrTaskStatusConflict},
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   8,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/status",
+				mockRequestBody:   strings.NewReader(`{"status":"archived"}`),
+			},
+			taskID:         1,
+			status:         models.TaskArchived,
+			from:           []models.TaskStatus{models.TaskTodo, models.TaskInProgress, models.TaskDone},
+			stopTimer:      true,
+			repoResp:       mockRepoResp{setError: errors.New("эта ошибка ломает service")},
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On(
+				"SetTaskStatus",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+				tc.status,
+				tc.from,
+				tc.stopTimer,
+			).Return(tc.repoResp.from, tc.repoResp.setError)
+
+			mockTasksRepo.On(
+				"FindTaskByID",
+				mock.AnythingOfType("*context.timerCtx"),
+				tc.taskID,
+			).Return(tc.repoResp.task, tc.repoResp.findError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/status", taskHandler.SetTaskStatus).Methods(http.MethodPut)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "SetTaskStatus", mock.Anything, tc.taskID, tc.status, tc.from, tc.stopTimer)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "SetTaskStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAssignTask(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		usrID          int
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/assignees/2",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          2,
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/assignees/asfasf",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Task Not Found Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/5/assignees/2",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         5,
+			usrID:          2,
+			mockError:      repos.ErrTaskNotFound,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "User Not Exists Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/assignees/9",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          9,
+			mockError:      repos.ErrUsrNotExists,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPut,
+				mockRequestURL:    "/tasks/1/assignees/2",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          2,
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On("AssignTask", mock.AnythingOfType("*context.timerCtx"), tc.taskID, tc.usrID).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/assignees/{user_id}", taskHandler.AssignTask).Methods(http.MethodPut)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "AssignTask", mock.Anything, tc.taskID, tc.usrID)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "AssignTask", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUnassignTask(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		mockReq        mockRequest
+		taskID         int
+		usrID          int
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+	}{
+		{
+			id:   1,
+			name: "Success",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/assignees/2",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          2,
+			callRepo:       true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			id:   2,
+			name: "Atoi Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/asfasf/assignees/2",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   3,
+			name: "Not Assigned Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/assignees/3",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          3,
+			mockError:      repos.ErrTaskNotAssigned,
+			callRepo:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:   4,
+			name: "Owner Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/assignees/1",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          1,
+			mockError:      repos.ErrTaskOwnerAssignee,
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			id:   5,
+			name: "Service Error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodDelete,
+				mockRequestURL:    "/tasks/1/assignees/2",
+				mockRequestBody:   strings.NewReader(``),
+			},
+			taskID:         1,
+			usrID:          2,
+			mockError:      errors.New("эта ошибка ломает service"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zapLogger.Sugar()
+
+			mockTasksRepo := new(reposmocks.MockTasksRepo)
+
+			mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+
+			mockTaskService := services.NewTaskService(mockTasksRepo, mockEntriesRepo, models.TimerPolicySwitch)
+
+			taskHandler := handlers.NewTaskHandler(mockTaskService, logger)
+
+			mockTasksRepo.On("UnassignTask", mock.AnythingOfType("*context.timerCtx"), tc.taskID, tc.usrID).Return(tc.mockError)
+
+			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/tasks/{task_id}/assignees/{user_id}", taskHandler.UnassignTask).Methods(http.MethodDelete)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.callRepo {
+				mockTasksRepo.AssertCalled(t, "UnassignTask", mock.Anything, tc.taskID, tc.usrID)
+			} else {
+				mockTasksRepo.AssertNotCalled(t, "UnassignTask", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
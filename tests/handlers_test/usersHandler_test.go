@@ -1,10 +1,15 @@
 package handlers_test
 
 import (
+	"EMTask/internal/enrichers"
 	"EMTask/internal/handlers"
 	"EMTask/internal/models"
+	"EMTask/internal/repos"
 	"EMTask/internal/services"
+	"EMTask/pkg/people"
 	"EMTask/tests/mocks/reposmocks"
+	"context"
+	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -12,7 +17,6 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 )
@@ -31,14 +35,6 @@ type mockRequest struct {
 	mockRequestBody   *strings.Reader
 }
 
-var mockServiceUser = models.ServiceUser{
-	PassportNum: "1234 567890",
-	Surname:     "Иванов",
-	Name:        "Иван",
-	Patronymic:  "Иванович",
-	Address:     "г. Москва, ул. Ленина, д. 5, кв. 1",
-}
-
 var mockUser = models.User{
 	ID:             1,
 	PassportNumber: "1234 567890",
@@ -46,6 +42,7 @@ var mockUser = models.User{
 	Name:           "Иван",
 	Patronymic:     "Иванович",
 	Address:        "г. Москва, ул. Ленина, д. 5, кв. 1",
+	Timezone:       "UTC",
 }
 
 var mockAPIUser = models.APIResponse{
@@ -66,7 +63,7 @@ func TestAddUser(t *testing.T) {
 		name           string
 		mockReq        mockRequest
 		repoResp       mockRepoResp
-		apiURL         string
+		timezone       string
 		callRepo       bool
 		breakWrite     bool
 		expectedStatus int
@@ -83,9 +80,9 @@ func TestAddUser(t *testing.T) {
 				usrID:     1,
 				mockError: nil,
 			},
-			apiURL:         "http://0.0.0.0:4010",
+			timezone:       "UTC",
 			callRepo:       true,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusAccepted,
 		},
 		{
 			id:   2,
@@ -95,21 +92,19 @@ func TestAddUser(t *testing.T) {
 				mockRequestURL:    "/user",
 				mockRequestBody:   strings.NewReader(`{"passportNumber": "bad input"}`),
 			},
-			apiURL:         "http://0.0.0.0:4010",
 			callRepo:       false,
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			id:   3,
-			name: "GetInfoError",
+			name: "Invalid Timezone",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPost,
 				mockRequestURL:    "/user",
-				mockRequestBody:   strings.NewReader(`{"passportNumber": "1234 567890"}`),
+				mockRequestBody:   strings.NewReader(`{"passportNumber": "1234 567890", "timezone": "Mars/Olympus"}`),
 			},
-			apiURL:         "http://0.0.0.0:4011",
 			callRepo:       false,
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			id:   4,
@@ -122,7 +117,7 @@ func TestAddUser(t *testing.T) {
 			repoResp: mockRepoResp{
 				mockError: errors.New("Эта ошибка ломает сервис"),
 			},
-			apiURL:         "http://0.0.0.0:4010",
+			timezone:       "UTC",
 			callRepo:       true,
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -134,7 +129,6 @@ func TestAddUser(t *testing.T) {
 				mockRequestURL:    "/user",
 				mockRequestBody:   strings.NewReader(`{Вот эти слова сломают DECODE}`),
 			},
-			apiURL:         "http://0.0.0.0:4010",
 			callRepo:       false,
 			expectedStatus: http.StatusBadRequest,
 		},
@@ -146,10 +140,30 @@ func TestAddUser(t *testing.T) {
 				mockRequestURL:    "/user",
 				mockRequestBody:   strings.NewReader(`{"passportNumber": "1234 567890"}`),
 			},
-			apiURL:         "http://0.0.0.0:4010",
-			callRepo:       false,
+			repoResp: mockRepoResp{
+				usrID:     1,
+				mockError: nil,
+			},
+			timezone:       "UTC",
+			callRepo:       true,
 			breakWrite:     true,
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			id:   7,
+			name: "Custom Timezone",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPost,
+				mockRequestURL:    "/user",
+				mockRequestBody:   strings.NewReader(`{"passportNumber": "1234 567890", "timezone": "Europe/Moscow"}`),
+			},
+			repoResp: mockRepoResp{
+				usrID:     1,
+				mockError: nil,
+			},
+			timezone:       "Europe/Moscow",
+			callRepo:       true,
+			expectedStatus: http.StatusAccepted,
 		},
 	}
 
@@ -162,17 +176,14 @@ func TestAddUser(t *testing.T) {
 
 			logger := zapLogger.Sugar()
 
-			os.Setenv("API_URL", tc.apiURL)
-
 			mockUserRepo := new(reposmocks.MockUserRepo)
 
-			mockUserService := services.NewUserService(mockUserRepo)
-
-			client := &http.Client{}
+			mockUserService := services.NewUserService(mockUserRepo, nil, nil)
 
-			userHandler := handlers.NewUserHandler(mockUserService, logger, client)
+			userHandler := handlers.NewUserHandler(mockUserService, logger)
 
-			mockUserRepo.On("AddUser", mock.AnythingOfType("*context.timerCtx"), mockServiceUser).Return(tc.repoResp.usrID, tc.repoResp.mockError)
+			mockUserRepo.On("AddPendingUser", mock.AnythingOfType("*context.timerCtx"), "1234 567890", tc.timezone).
+				Return(tc.repoResp.usrID, tc.repoResp.mockError)
 
 			req, err := http.NewRequest(tc.mockReq.mockRequestMethod, tc.mockReq.mockRequestURL, tc.mockReq.mockRequestBody)
 			if err != nil {
@@ -191,8 +202,14 @@ func TestAddUser(t *testing.T) {
 				assert.Equal(t, tc.expectedStatus, rr.Code)
 			}
 
+			if tc.expectedStatus == http.StatusAccepted && !tc.breakWrite {
+				assert.Contains(t, rr.Body.String(), `"enrichment_status":"pending"`)
+			}
+
 			if tc.callRepo {
-				mockUserRepo.AssertCalled(t, "AddUser", mock.Anything, mockServiceUser)
+				mockUserRepo.AssertCalled(t, "AddPendingUser", mock.Anything, "1234 567890", tc.timezone)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "AddPendingUser", mock.Anything, mock.Anything, mock.Anything)
 			}
 		})
 	}
@@ -416,11 +433,9 @@ func TestGetUsers(t *testing.T) {
 
 			mockUserRepo := new(reposmocks.MockUserRepo)
 
-			mockUserService := services.NewUserService(mockUserRepo)
+			mockUserService := services.NewUserService(mockUserRepo, nil, nil)
 
-			client := &http.Client{}
-
-			userHandler := handlers.NewUserHandler(mockUserService, logger, client)
+			userHandler := handlers.NewUserHandler(mockUserService, logger)
 
 			mockUserRepo.On("GetAllUsers", mock.AnythingOfType("*context.timerCtx"), tc.mockFilter, tc.mockPageNum, tc.mockLimitNum).Return(tc.repoResp.users, tc.repoResp.err)
 
@@ -520,11 +535,9 @@ func TestDeleteUser(t *testing.T) {
 
 			mockUserRepo := new(reposmocks.MockUserRepo)
 
-			mockUserService := services.NewUserService(mockUserRepo)
-
-			client := &http.Client{}
+			mockUserService := services.NewUserService(mockUserRepo, nil, nil)
 
-			userHandler := handlers.NewUserHandler(mockUserService, logger, client)
+			userHandler := handlers.NewUserHandler(mockUserService, logger)
 
 			mockUserRepo.On("DeleteUser", mock.AnythingOfType("*context.timerCtx"), tc.mockUsrID).Return(tc.repoResp.err)
 
@@ -626,6 +639,24 @@ func TestUpdateUser(t *testing.T) {
 		},
 		{
 			id:   4,
+			name: "Invalid timezone error",
+			mockReq: mockRequest{
+				mockRequestMethod: http.MethodPatch,
+				mockRequestURL:    "/user/1",
+				mockRequestBody: strings.NewReader(`{
+				  "surname": "Викторов",
+				  "name": "Виктор",
+				  "patronymic": "Викторович",
+				  "address": "г.Санкт-Петербург",
+				  "timezone": "Марс/Олимп"
+				}`),
+			},
+			userID:         1,
+			callRepo:       false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:   5,
 			name: "Service error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPatch,
@@ -646,7 +677,7 @@ func TestUpdateUser(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 		},
 		{
-			id:   5,
+			id:   6,
 			name: "Encode error",
 			mockReq: mockRequest{
 				mockRequestMethod: http.MethodPatch,
@@ -680,11 +711,9 @@ func TestUpdateUser(t *testing.T) {
 
 			mockUserRepo := new(reposmocks.MockUserRepo)
 
-			mockUserService := services.NewUserService(mockUserRepo)
-
-			client := &http.Client{}
+			mockUserService := services.NewUserService(mockUserRepo, nil, nil)
 
-			userHandler := handlers.NewUserHandler(mockUserService, logger, client)
+			userHandler := handlers.NewUserHandler(mockUserService, logger)
 
 			mockUserRepo.On("UpdateUser", mock.AnythingOfType("*context.timerCtx"), mockAPIUser, tc.userID).Return(tc.repoResp.user, tc.repoResp.err)
 
@@ -710,7 +739,329 @@ func TestUpdateUser(t *testing.T) {
 
 			if tc.callRepo {
 				mockUserRepo.AssertCalled(t, "UpdateUser", mock.Anything, mockAPIUser, tc.userID)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// stubPeople - провайдер данных юзеров: ответ по номеру паспорта, неизвестный паспорт - не найден
+type stubPeople struct {
+	people map[string]models.APIResponse
+	err    error
+}
+
+func (sp stubPeople) Enrich(_ context.Context, passport string) (models.APIResponse, error) {
+	if sp.err != nil {
+		return models.APIResponse{}, sp.err
+	}
+
+	person, ok := sp.people[passport]
+	if !ok {
+		return models.APIResponse{}, enrichers.ErrPersonNotFound
+	}
+
+	return person, nil
+}
+
+var movedUser = models.APIResponse{
+	Surname:    "Иванов",
+	Name:       "Иван",
+	Patronymic: "Иванович",
+	Address:    "г. Тверь, ул. Советская, д. 1",
+}
+
+var movedUserChanges = []models.FieldChange{
+	{Field: "address", Old: "г. Москва, ул. Ленина, д. 5, кв. 1", New: "г. Тверь, ул. Советская, д. 1"},
+}
+
+func TestRefreshUser(t *testing.T) {
+	type mockRepoResp struct {
+		user     models.User
+		findErr  error
+		locked   *models.User
+		applyErr error
+	}
+
+	// адрес успели поправить PATCH-ем, пока шёл запрос к провайдеру
+	patchedUser := mockUser
+	patchedUser.Surname = "Петров"
+	patchedUser.Address = movedUser.Address
+
+	testCases := []struct {
+		id              int
+		name            string
+		url             string
+		repoResp        mockRepoResp
+		enricherErr     error
+		breakWrite      bool
+		expectedStatus  int
+		expectedRefresh models.UserRefresh
+		callApply       bool
+	}{
+		{
+			id:              1,
+			name:            "Dry run",
+			url:             "/user/1/refresh",
+			repoResp:        mockRepoResp{user: mockUser},
+			expectedStatus:  http.StatusOK,
+			expectedRefresh: models.UserRefresh{UserID: 1, Changes: movedUserChanges},
+		},
+		{
+			id:              2,
+			name:            "Apply",
+			url:             "/user/1/refresh?apply=true",
+			repoResp:        mockRepoResp{user: mockUser},
+			expectedStatus:  http.StatusOK,
+			expectedRefresh: models.UserRefresh{UserID: 1, Changes: movedUserChanges, Applied: true},
+			callApply:       true,
+		},
+		{
+			id:   3,
+			name: "Nothing changed",
+			url:  "/user/1/refresh?apply=true",
+			repoResp: mockRepoResp{user: models.User{
+				ID:             1,
+				PassportNumber: "1234 567890",
+				Surname:        movedUser.Surname,
+				Name:           movedUser.Name,
+				Patronymic:     movedUser.Patronymic,
+				Address:        movedUser.Address,
+			}},
+			expectedStatus:  http.StatusOK,
+			expectedRefresh: models.UserRefresh{UserID: 1, Changes: []models.FieldChange{}},
+		},
+		{
+			id:             4,
+			name:           "Atoi error",
+			url:            "/user/abc/refresh",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:             5,
+			name:           "Invalid apply",
+			url:            "/user/1/refresh?apply=может",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:             6,
+			name:           "User not found",
+			url:            "/user/1/refresh",
+			repoResp:       mockRepoResp{findErr: repos.ErrUsrNotExists},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			id:             7,
+			name:           "Person not found",
+			url:            "/user/1/refresh",
+			repoResp:       mockRepoResp{user: models.User{ID: 1, PassportNumber: "9999 000000"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			id:             8,
+			name:           "People API unavailable",
+			url:            "/user/1/refresh",
+			repoResp:       mockRepoResp{user: mockUser},
+			enricherErr:    people.ErrUnavailable,
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			id:             9,
+			name:           "Circuit open",
+			url:            "/user/1/refresh",
+			repoResp:       mockRepoResp{user: mockUser},
+			enricherErr:    people.ErrCircuitOpen,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			id:             10,
+			name:           "Apply error",
+			url:            "/user/1/refresh?apply=1",
+			repoResp:       mockRepoResp{user: mockUser, applyErr: errors.New("Эта ошибка ломает сервис")},
+			expectedStatus: http.StatusInternalServerError,
+			callApply:      true,
+		},
+		{
+			id:             11,
+			name:           "Encode error",
+			url:            "/user/1/refresh",
+			repoResp:       mockRepoResp{user: mockUser},
+			breakWrite:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			id:             12,
+			name:           "Diff is recomputed on the locked row",
+			url:            "/user/1/refresh?apply=true",
+			repoResp:       mockRepoResp{user: mockUser, locked: &patchedUser},
+			expectedStatus: http.StatusOK,
+			expectedRefresh: models.UserRefresh{
+				UserID:  1,
+				Changes: []models.FieldChange{{Field: "surname", Old: "Петров", New: "Иванов"}},
+				Applied: true,
+			},
+			callApply: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockUserRepo := new(reposmocks.MockUserRepo)
+
+			enricher := stubPeople{people: map[string]models.APIResponse{"1234 567890": movedUser}, err: tc.enricherErr}
+
+			mockTransactor := new(reposmocks.MockTransactor)
+			mockTransactor.On("InTx", mock.AnythingOfType("*context.timerCtx")).Return(nil)
+
+			userHandler := handlers.NewUserHandler(
+				services.NewUserService(mockUserRepo, mockTransactor, enricher),
+				zapLogger.Sugar(),
+			)
+
+			locked := tc.repoResp.user
+			if tc.repoResp.locked != nil {
+				locked = *tc.repoResp.locked
+			}
+
+			expectedChanges := tc.expectedRefresh.Changes
+			if expectedChanges == nil {
+				expectedChanges = movedUserChanges
+			}
+
+			mockUserRepo.On("FindUserByID", mock.AnythingOfType("*context.timerCtx"), 1).Return(tc.repoResp.user, tc.repoResp.findErr)
+			mockUserRepo.On("LockUserByID", mock.AnythingOfType("*context.timerCtx"), 1).Return(locked, nil)
+			mockUserRepo.On("ApplyUserRefresh", mock.AnythingOfType("*context.timerCtx"), 1, expectedChanges).
+				Return(tc.repoResp.applyErr)
+
+			req, err := http.NewRequest(http.MethodPost, tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			router := mux.NewRouter()
+			router.HandleFunc("/user/{user_id}/refresh", userHandler.RefreshUser).Methods(http.MethodPost)
+
+			if tc.breakWrite {
+				mockWriter := &errorResponseWriter{}
+				router.ServeHTTP(mockWriter, req)
+				assert.Equal(t, tc.expectedStatus, mockWriter.Code)
+
+				return
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedStatus == http.StatusOK {
+				var refresh models.UserRefresh
+
+				err = json.NewDecoder(rr.Body).Decode(&refresh)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRefresh, refresh)
+			}
+
+			if tc.callApply {
+				mockUserRepo.AssertCalled(t, "ApplyUserRefresh", mock.Anything, 1, expectedChanges)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "ApplyUserRefresh", mock.Anything, mock.Anything, mock.Anything)
 			}
 		})
 	}
 }
+
+func TestRefreshUsers(t *testing.T) {
+	testCases := []struct {
+		id             int
+		name           string
+		body           string
+		expectedStatus int
+		expected       []models.UserRefresh
+	}{
+		{
+			id:             1,
+			name:           "Success with per-user errors",
+			body:           `{"user_ids": [1, 2, 1, 3], "apply": true}`,
+			expectedStatus: http.StatusOK,
+			expected: []models.UserRefresh{
+				{UserID: 1, Changes: movedUserChanges, Applied: true},
+				{UserID: 2, Error: repos.ErrUsrNotExists.Error()},
+				{UserID: 3, Error: enrichers.ErrPersonNotFound.Error()},
+			},
+		},
+		{
+			id:             2,
+			name:           "Empty ids",
+			body:           `{"user_ids": []}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:             3,
+			name:           "Too many ids",
+			body:           `{"user_ids": [` + strings.Repeat("1,", services.MaxRefreshUsers) + `1]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			id:             4,
+			name:           "Decode error",
+			body:           `{Это я сломал decode}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockUserRepo := new(reposmocks.MockUserRepo)
+
+			enricher := stubPeople{people: map[string]models.APIResponse{"1234 567890": movedUser}}
+
+			ctxType := mock.AnythingOfType("*context.timerCtx")
+
+			mockTransactor := new(reposmocks.MockTransactor)
+			mockTransactor.On("InTx", ctxType).Return(nil)
+
+			userHandler := handlers.NewUserHandler(
+				services.NewUserService(mockUserRepo, mockTransactor, enricher),
+				zapLogger.Sugar(),
+			)
+
+			mockUserRepo.On("FindUserByID", ctxType, 1).Return(mockUser, nil)
+			mockUserRepo.On("FindUserByID", ctxType, 2).Return(models.User{}, repos.ErrUsrNotExists)
+			mockUserRepo.On("FindUserByID", ctxType, 3).Return(models.User{ID: 3, PassportNumber: "9999 000000"}, nil)
+			mockUserRepo.On("LockUserByID", ctxType, 1).Return(mockUser, nil)
+			mockUserRepo.On("ApplyUserRefresh", ctxType, 1, movedUserChanges).Return(nil)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/refresh", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			userHandler.RefreshUsers(rr, req)
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedStatus != http.StatusOK {
+				mockUserRepo.AssertNotCalled(t, "FindUserByID", mock.Anything, mock.Anything)
+				return
+			}
+
+			var results []models.UserRefresh
+
+			err = json.NewDecoder(rr.Body).Decode(&results)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, results)
+			mockUserRepo.AssertNumberOfCalls(t, "FindUserByID", 3)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package reposmocks
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCalendarRepo struct {
+	mock.Mock
+}
+
+func (cr *MockCalendarRepo) SaveCalendarToken(ctx context.Context, usrID int, tokenHash string) error {
+	args := cr.Called(ctx, usrID, tokenHash)
+	return args.Error(0)
+}
+
+func (cr *MockCalendarRepo) DeleteCalendarToken(ctx context.Context, usrID int) error {
+	args := cr.Called(ctx, usrID)
+	return args.Error(0)
+}
+
+func (cr *MockCalendarRepo) FindCalendarTokenHash(ctx context.Context, usrID int) (string, error) {
+	args := cr.Called(ctx, usrID)
+	return args.String(0), args.Error(1)
+}
+
+func (cr *MockCalendarRepo) FindCalendarEvents(ctx context.Context, filter models.CalendarFilter) ([]models.CalendarEvent, error) {
+	args := cr.Called(ctx, filter)
+	return args.Get(0).([]models.CalendarEvent), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package reposmocks
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type MockEnrichmentRepo struct {
+	mock.Mock
+}
+
+func (er *MockEnrichmentRepo) ClaimEnrichmentJobs(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]models.EnrichmentJob, error) {
+	args := er.Called(ctx, limit, lease)
+	return args.Get(0).([]models.EnrichmentJob), args.Error(1)
+}
+
+func (er *MockEnrichmentRepo) CompleteEnrichmentJob(ctx context.Context, jobID int, info models.APIResponse) error {
+	args := er.Called(ctx, jobID, info)
+	return args.Error(0)
+}
+
+func (er *MockEnrichmentRepo) RetryEnrichmentJob(ctx context.Context, jobID int, runAt time.Time, reason string) error {
+	args := er.Called(ctx, jobID, runAt, reason)
+	return args.Error(0)
+}
+
+func (er *MockEnrichmentRepo) FailEnrichmentJob(ctx context.Context, jobID int, reason string) error {
+	args := er.Called(ctx, jobID, reason)
+	return args.Error(0)
+}
+
+func (er *MockEnrichmentRepo) FindEnrichmentCache(ctx context.Context, hash string) (models.EnrichmentCacheEntry, error) {
+	args := er.Called(ctx, hash)
+	return args.Get(0).(models.EnrichmentCacheEntry), args.Error(1)
+}
+
+func (er *MockEnrichmentRepo) SaveEnrichmentCache(ctx context.Context, entry models.EnrichmentCacheEntry) error {
+	args := er.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (er *MockEnrichmentRepo) DeleteExpiredEnrichmentCache(ctx context.Context) (int64, error) {
+	args := er.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
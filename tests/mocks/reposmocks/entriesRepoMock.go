@@ -0,0 +1,59 @@
+package reposmocks
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type MockTimeEntriesRepo struct {
+	mock.Mock
+}
+
+func (er *MockTimeEntriesRepo) FindEntriesByTaskID(ctx context.Context, taskID int) ([]models.TimeEntry, error) {
+	args := er.Called(ctx, taskID)
+	return args.Get(0).([]models.TimeEntry), args.Error(1)
+}
+
+func (er *MockTimeEntriesRepo) CreateEntry(
+	ctx context.Context,
+	entry models.TimeEntry,
+	trackable []models.TaskStatus,
+) (models.TimeEntry, error) {
+	args := er.Called(ctx, entry, trackable)
+	return args.Get(0).(models.TimeEntry), args.Error(1)
+}
+
+func (er *MockTimeEntriesRepo) ImportEntry(ctx context.Context, entry models.TimeEntry) (models.TimeEntry, error) {
+	args := er.Called(ctx, entry)
+	return args.Get(0).(models.TimeEntry), args.Error(1)
+}
+
+func (er *MockTimeEntriesRepo) UpdateEntry(ctx context.Context, entry models.TimeEntry) (models.TimeEntry, error) {
+	args := er.Called(ctx, entry)
+	return args.Get(0).(models.TimeEntry), args.Error(1)
+}
+
+func (er *MockTimeEntriesRepo) DeleteEntry(ctx context.Context, entryID, usrID int, reason string) error {
+	args := er.Called(ctx, entryID, usrID, reason)
+	return args.Error(0)
+}
+
+func (er *MockTimeEntriesRepo) FindActiveTimers(
+	ctx context.Context,
+	filter models.ActiveTimerFilter,
+	pg, lim int,
+) ([]models.ActiveTimer, error) {
+	args := er.Called(ctx, filter, pg, lim)
+	return args.Get(0).([]models.ActiveTimer), args.Error(1)
+}
+
+func (er *MockTimeEntriesRepo) AutoStopTimers(
+	ctx context.Context,
+	rule models.AutoStopRule,
+	now time.Time,
+) ([]models.TimeEntry, error) {
+	args := er.Called(ctx, rule, now)
+	return args.Get(0).([]models.TimeEntry), args.Error(1)
+}
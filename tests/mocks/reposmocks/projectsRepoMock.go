@@ -0,0 +1,61 @@
+package reposmocks
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProjectRepo struct {
+	mock.Mock
+}
+
+func (pr *MockProjectRepo) CreateClient(ctx context.Context, name string) (models.Client, error) {
+	args := pr.Called(ctx, name)
+	return args.Get(0).(models.Client), args.Error(1)
+}
+
+func (pr *MockProjectRepo) FindClients(ctx context.Context) ([]models.Client, error) {
+	args := pr.Called(ctx)
+	return args.Get(0).([]models.Client), args.Error(1)
+}
+
+func (pr *MockProjectRepo) FindClientByID(ctx context.Context, id int) (models.Client, error) {
+	args := pr.Called(ctx, id)
+	return args.Get(0).(models.Client), args.Error(1)
+}
+
+func (pr *MockProjectRepo) UpdateClient(ctx context.Context, id int, name string) (models.Client, error) {
+	args := pr.Called(ctx, id, name)
+	return args.Get(0).(models.Client), args.Error(1)
+}
+
+func (pr *MockProjectRepo) DeleteClient(ctx context.Context, id int) error {
+	args := pr.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (pr *MockProjectRepo) CreateProject(ctx context.Context, req models.ProjectRequest) (models.Project, error) {
+	args := pr.Called(ctx, req)
+	return args.Get(0).(models.Project), args.Error(1)
+}
+
+func (pr *MockProjectRepo) FindProjects(ctx context.Context, filter models.ProjectFilter) ([]models.Project, error) {
+	args := pr.Called(ctx, filter)
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
+func (pr *MockProjectRepo) FindProjectByID(ctx context.Context, id int) (models.Project, error) {
+	args := pr.Called(ctx, id)
+	return args.Get(0).(models.Project), args.Error(1)
+}
+
+func (pr *MockProjectRepo) UpdateProject(ctx context.Context, id int, req models.ProjectRequest) (models.Project, error) {
+	args := pr.Called(ctx, id, req)
+	return args.Get(0).(models.Project), args.Error(1)
+}
+
+func (pr *MockProjectRepo) DeleteProject(ctx context.Context, id int) error {
+	args := pr.Called(ctx, id)
+	return args.Error(0)
+}
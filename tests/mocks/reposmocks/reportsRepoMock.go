@@ -0,0 +1,41 @@
+package reposmocks
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportsRepo struct {
+	mock.Mock
+}
+
+func (rr *MockReportsRepo) GetWorkload(ctx context.Context, filter models.ReportFilter) ([]models.TaskWorkload, error) {
+	args := rr.Called(ctx, filter)
+	return args.Get(0).([]models.TaskWorkload), args.Error(1)
+}
+
+func (rr *MockReportsRepo) GetTagWorkload(ctx context.Context, filter models.ReportFilter) ([]models.TaskWorkload, error) {
+	args := rr.Called(ctx, filter)
+	return args.Get(0).([]models.TaskWorkload), args.Error(1)
+}
+
+func (rr *MockReportsRepo) GetSummary(ctx context.Context, filter models.ReportFilter) ([]models.SummaryCell, error) {
+	args := rr.Called(ctx, filter)
+	return args.Get(0).([]models.SummaryCell), args.Error(1)
+}
+
+func (rr *MockReportsRepo) FindUserTimezone(ctx context.Context, usrID int) (string, error) {
+	args := rr.Called(ctx, usrID)
+	return args.String(0), args.Error(1)
+}
+
+func (rr *MockReportsRepo) GetTimesheet(ctx context.Context, filter models.ReportFilter) ([]models.TimesheetCell, error) {
+	args := rr.Called(ctx, filter)
+	return args.Get(0).([]models.TimesheetCell), args.Error(1)
+}
+
+func (rr *MockReportsRepo) FindUserByID(ctx context.Context, usrID int) (models.User, error) {
+	args := rr.Called(ctx, usrID)
+	return args.Get(0).(models.User), args.Error(1)
+}
@@ -0,0 +1,36 @@
+package reposmocks
+
+import (
+	"EMTask/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagRepo struct {
+	mock.Mock
+}
+
+func (tr *MockTagRepo) CreateTag(ctx context.Context, name string) (models.Tag, error) {
+	args := tr.Called(ctx, name)
+	return args.Get(0).(models.Tag), args.Error(1)
+}
+
+func (tr *MockTagRepo) FindTags(ctx context.Context) ([]models.Tag, error) {
+	args := tr.Called(ctx)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (tr *MockTagRepo) DeleteTag(ctx context.Context, id int) error {
+	args := tr.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (tr *MockTagRepo) AttachTag(ctx context.Context, taskID, tagID int) error {
+	args := tr.Called(ctx, taskID, tagID)
+	return args.Error(0)
+}
+
+func (tr *MockTagRepo) DetachTag(ctx context.Context, taskID, tagID int) error {
+	args := tr.Called(ctx, taskID, tagID)
+	return args.Error(0)
+}
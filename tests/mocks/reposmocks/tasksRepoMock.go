@@ -10,18 +10,85 @@ type MockTasksRepo struct {
 	mock.Mock
 }
 
-func (tr *MockTasksRepo) AddTask(ctx context.Context, name string, usrID int) (models.Task, error) {
-	args := tr.Called(ctx, name, usrID)
+func (tr *MockTasksRepo) AddTask(
+	ctx context.Context,
+	name string,
+	usrID int,
+	projectID, parentID *int,
+	estimate *int64,
+) (models.Task, error) {
+	args := tr.Called(ctx, name, usrID, projectID, parentID, estimate)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
+func (tr *MockTasksRepo) SetTaskProject(ctx context.Context, id int, projectID *int) error {
+	args := tr.Called(ctx, id, projectID)
+	return args.Error(0)
+}
+
+func (tr *MockTasksRepo) SetTaskEstimate(ctx context.Context, id int, estimate *int64) error {
+	args := tr.Called(ctx, id, estimate)
+	return args.Error(0)
+}
+
+func (tr *MockTasksRepo) FindOverBudgetTasks(ctx context.Context, threshold int) ([]models.TaskBudget, error) {
+	args := tr.Called(ctx, threshold)
+	return args.Get(0).([]models.TaskBudget), args.Error(1)
+}
+
+func (tr *MockTasksRepo) SetTaskStatus(
+	ctx context.Context,
+	id int,
+	to models.TaskStatus,
+	from []models.TaskStatus,
+	stopTimer bool,
+) (models.TaskStatus, error) {
+	args := tr.Called(ctx, id, to, from, stopTimer)
+	return args.Get(0).(models.TaskStatus), args.Error(1)
+}
+
+func (tr *MockTasksRepo) SetTaskParent(ctx context.Context, id int, parentID *int) error {
+	args := tr.Called(ctx, id, parentID)
+	return args.Error(0)
+}
+
+func (tr *MockTasksRepo) FindTaskTree(ctx context.Context, id int) ([]models.TaskTree, error) {
+	args := tr.Called(ctx, id)
+	return args.Get(0).([]models.TaskTree), args.Error(1)
+}
+
+func (tr *MockTasksRepo) AssignTask(ctx context.Context, id, usrID int) error {
+	args := tr.Called(ctx, id, usrID)
+	return args.Error(0)
+}
+
+func (tr *MockTasksRepo) UnassignTask(ctx context.Context, id, usrID int) error {
+	args := tr.Called(ctx, id, usrID)
+	return args.Error(0)
+}
+
+func (tr *MockTasksRepo) FindTaskAssignees(ctx context.Context, id int) ([]models.TaskAssignee, error) {
+	args := tr.Called(ctx, id)
+	return args.Get(0).([]models.TaskAssignee), args.Error(1)
+}
+
 func (tr *MockTasksRepo) FindTaskByID(ctx context.Context, id int) (models.Task, error) {
 	args := tr.Called(ctx, id)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
-func (tr *MockTasksRepo) FindTasksByUserID(ctx context.Context, usrID int, startTime, endTime string) ([]models.Task, error) {
-	args := tr.Called(ctx, usrID, startTime, endTime)
+func (tr *MockTasksRepo) FindTaskByName(ctx context.Context, usrID int, name string) (models.Task, error) {
+	args := tr.Called(ctx, usrID, name)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (tr *MockTasksRepo) FindTasksByUserID(
+	ctx context.Context,
+	usrID int,
+	startTime, endTime string,
+	filter models.TaskFilter,
+) ([]models.Task, error) {
+	args := tr.Called(ctx, usrID, startTime, endTime, filter)
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
@@ -30,17 +97,39 @@ func (tr *MockTasksRepo) DeleteTaskByID(ctx context.Context, id int) error {
 	return args.Error(0)
 }
 
-func (tr *MockTasksRepo) StartTimeTracker(ctx context.Context, id, usrID int) error {
-	args := tr.Called(ctx, id, usrID)
-	return args.Error(0)
+func (tr *MockTasksRepo) StartTimeTracker(
+	ctx context.Context,
+	id, usrID int,
+	from []models.TimerState,
+	trackable []models.TaskStatus,
+	policy models.TimerPolicy,
+) (models.TimerState, []int, error) {
+	args := tr.Called(ctx, id, usrID, from, trackable, policy)
+	return args.Get(0).(models.TimerState), args.Get(1).([]int), args.Error(2)
 }
 
-func (tr *MockTasksRepo) StopTimeTracker(ctx context.Context, id, usrID int) error {
-	args := tr.Called(ctx, id, usrID)
-	return args.Error(0)
+func (tr *MockTasksRepo) PauseTimeTracker(ctx context.Context, id, usrID int, from []models.TimerState) (models.TimerState, error) {
+	args := tr.Called(ctx, id, usrID, from)
+	return args.Get(0).(models.TimerState), args.Error(1)
+}
+
+func (tr *MockTasksRepo) ResumeTimeTracker(
+	ctx context.Context,
+	id, usrID int,
+	from []models.TimerState,
+	trackable []models.TaskStatus,
+	policy models.TimerPolicy,
+) (models.TimerState, []int, error) {
+	args := tr.Called(ctx, id, usrID, from, trackable, policy)
+	return args.Get(0).(models.TimerState), args.Get(1).([]int), args.Error(2)
+}
+
+func (tr *MockTasksRepo) StopTimeTracker(ctx context.Context, id, usrID int, from []models.TimerState) (models.TimerState, error) {
+	args := tr.Called(ctx, id, usrID, from)
+	return args.Get(0).(models.TimerState), args.Error(1)
 }
 
-func (tr *MockTasksRepo) GetAllTasks(ctx context.Context) ([]models.Task, error) {
-	args := tr.Called(ctx)
+func (tr *MockTasksRepo) GetAllTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	args := tr.Called(ctx, filter)
 	return args.Get(0).([]models.Task), args.Error(1)
 }
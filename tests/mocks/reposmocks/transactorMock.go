@@ -0,0 +1,20 @@
+package reposmocks
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactor - вызывает fn без транзакции, ошибку InTx можно задать через On
+type MockTransactor struct {
+	mock.Mock
+}
+
+func (t *MockTransactor) InTx(ctx context.Context, fn func(context.Context) error) error {
+	args := t.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+
+	return fn(ctx)
+}
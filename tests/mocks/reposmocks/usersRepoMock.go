@@ -20,6 +20,36 @@ func (repo *MockUserRepo) AddUser(ctx context.Context, user models.ServiceUser)
 	return args.Get(0).(int), args.Error(1)
 }
 
+func (repo *MockUserRepo) AddPendingUser(ctx context.Context, passport, timezone string) (int, error) {
+	args := repo.Called(ctx, passport, timezone)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (repo *MockUserRepo) FindUserByID(ctx context.Context, usrID int) (models.User, error) {
+	args := repo.Called(ctx, usrID)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (repo *MockUserRepo) LockUserByID(ctx context.Context, usrID int) (models.User, error) {
+	args := repo.Called(ctx, usrID)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (repo *MockUserRepo) ApplyUserRefresh(ctx context.Context, usrID int, changes []models.FieldChange) error {
+	args := repo.Called(ctx, usrID, changes)
+	return args.Error(0)
+}
+
+func (repo *MockUserRepo) FindUserByEmail(ctx context.Context, email string) (models.User, error) {
+	args := repo.Called(ctx, email)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (repo *MockUserRepo) FindUsersByFullName(ctx context.Context, fullName string) ([]models.User, error) {
+	args := repo.Called(ctx, fullName)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
 func (repo *MockUserRepo) UpdateUser(ctx context.Context, newUser models.APIResponse, usrID int) (models.User, error) {
 	args := repo.Called(ctx, newUser, usrID)
 	return args.Get(0).(models.User), args.Error(1)
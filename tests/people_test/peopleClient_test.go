@@ -0,0 +1,303 @@
+package people_test
+
+import (
+	"EMTask/pkg/people"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const mockPersonBody = `{
+	"surname":"Иванов",
+	"name":"Иван",
+	"patronymic":"Иванович",
+	"address":"г. Москва, ул. Ленина, д. 5, кв. 1"
+}`
+
+var mockPerson = people.Person{
+	Surname:    "Иванов",
+	Name:       "Иван",
+	Patronymic: "Иванович",
+	Address:    "г. Москва, ул. Ленина, д. 5, кв. 1",
+}
+
+func testConfig(baseURL string) people.Config {
+	return people.Config{
+		BaseURL:          baseURL,
+		MaxRetries:       2,
+		Backoff:          time.Millisecond,
+		MaxBackoff:       5 * time.Millisecond,
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+	}
+}
+
+// apiStub - People API, который отвечает статусами из statuses по очереди, последний повторяется
+func apiStub(t *testing.T, calls *atomic.Int32, statuses ...int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+
+		status := statuses[min(n, len(statuses))-1]
+
+		w.WriteHeader(status)
+
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(mockPersonBody))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClientInfo(t *testing.T) {
+	testCases := []struct {
+		id            int
+		name          string
+		statuses      []int
+		expected      people.Person
+		expectedErr   error
+		expectedCalls int32
+	}{
+		{
+			id:            1,
+			name:          "Success",
+			statuses:      []int{http.StatusOK},
+			expected:      mockPerson,
+			expectedCalls: 1,
+		},
+		{
+			id:            2,
+			name:          "Bad request is not retried",
+			statuses:      []int{http.StatusBadRequest},
+			expectedErr:   people.ErrBadRequest,
+			expectedCalls: 1,
+		},
+		{
+			id:            3,
+			name:          "Server error is retried",
+			statuses:      []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK},
+			expected:      mockPerson,
+			expectedCalls: 3,
+		},
+		{
+			id:            4,
+			name:          "Retries exhausted",
+			statuses:      []int{http.StatusInternalServerError},
+			expectedErr:   people.ErrUnavailable,
+			expectedCalls: 3,
+		},
+		{
+			id:            5,
+			name:          "Too many requests is retried",
+			statuses:      []int{http.StatusTooManyRequests, http.StatusOK},
+			expected:      mockPerson,
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			server := apiStub(t, &calls, tc.statuses...)
+
+			client := people.NewClient(testConfig(server.URL), server.Client())
+
+			person, err := client.Info(context.Background(), "1234", "567890")
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, person)
+			assert.Equal(t, tc.expectedCalls, calls.Load())
+		})
+	}
+}
+
+func TestClientInfoQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/info", r.URL.Path)
+		assert.Equal(t, "1234", r.URL.Query().Get("passportSerie"))
+		assert.Equal(t, "567890", r.URL.Query().Get("passportNumber"))
+
+		_, _ = w.Write([]byte(mockPersonBody))
+	}))
+	defer server.Close()
+
+	client := people.NewClient(testConfig(server.URL+"/"), server.Client())
+
+	_, err := client.Info(context.Background(), "1234", "567890")
+	assert.NoError(t, err)
+}
+
+func TestClientInvalidResponse(t *testing.T) {
+	testCases := []struct {
+		id   int
+		name string
+		body string
+	}{
+		{id: 1, name: "Broken JSON", body: `{Это не JSON}`},
+		{id: 2, name: "Missing required fields", body: `{"surname":"Иванов"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := people.NewClient(testConfig(server.URL), server.Client())
+
+			_, err := client.Info(context.Background(), "1234", "567890")
+			assert.ErrorIs(t, err, people.ErrInvalidResponse)
+		})
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	var calls atomic.Int32
+
+	server := apiStub(t, &calls, http.StatusInternalServerError)
+
+	cfg := testConfig(server.URL)
+	cfg.Backoff = time.Second
+	cfg.MaxBackoff = time.Second
+
+	client := people.NewClient(cfg, server.Client())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Info(ctx, "1234", "567890")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+
+	healthy := atomic.Bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(mockPersonBody))
+	}))
+	defer server.Close()
+
+	cfg := testConfig(server.URL)
+	cfg.MaxRetries = 0
+
+	client := people.NewClient(cfg, server.Client())
+
+	for range 2 {
+		_, err := client.Info(context.Background(), "1234", "567890")
+		assert.ErrorIs(t, err, people.ErrUnavailable)
+	}
+
+	_, err := client.Info(context.Background(), "1234", "567890")
+	assert.ErrorIs(t, err, people.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load(), "open circuit must not reach the API")
+
+	time.Sleep(cfg.OpenTimeout)
+
+	_, err = client.Info(context.Background(), "1234", "567890")
+	assert.ErrorIs(t, err, people.ErrUnavailable, "failed probe reopens the circuit")
+
+	_, err = client.Info(context.Background(), "1234", "567890")
+	assert.ErrorIs(t, err, people.ErrCircuitOpen)
+
+	healthy.Store(true)
+	time.Sleep(cfg.OpenTimeout)
+
+	person, err := client.Info(context.Background(), "1234", "567890")
+	assert.NoError(t, err)
+	assert.Equal(t, mockPerson, person)
+
+	_, err = client.Info(context.Background(), "1234", "567890")
+	assert.NoError(t, err, "successful probe closes the circuit")
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestClientBadRequestKeepsCircuitClosed(t *testing.T) {
+	var calls atomic.Int32
+
+	server := apiStub(t, &calls, http.StatusBadRequest)
+
+	client := people.NewClient(testConfig(server.URL), server.Client())
+
+	for range 5 {
+		_, err := client.Info(context.Background(), "1234", "567890")
+		assert.True(t, errors.Is(err, people.ErrBadRequest))
+	}
+
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestParseConfig(t *testing.T) {
+	testCases := []struct {
+		id          int
+		name        string
+		retries     string
+		backoff     string
+		threshold   string
+		openTimeout string
+		expected    people.Config
+		expectedErr error
+	}{
+		{
+			id:   1,
+			name: "Defaults",
+			expected: people.Config{
+				BaseURL:          "http://0.0.0.0:4010",
+				MaxRetries:       2,
+				Backoff:          100 * time.Millisecond,
+				MaxBackoff:       2 * time.Second,
+				FailureThreshold: 5,
+				OpenTimeout:      30 * time.Second,
+			},
+		},
+		{
+			id:          2,
+			name:        "Custom",
+			retries:     "0",
+			backoff:     "250ms",
+			threshold:   "3",
+			openTimeout: "1m",
+			expected: people.Config{
+				BaseURL:          "http://0.0.0.0:4010",
+				MaxRetries:       0,
+				Backoff:          250 * time.Millisecond,
+				MaxBackoff:       2 * time.Second,
+				FailureThreshold: 3,
+				OpenTimeout:      time.Minute,
+			},
+		},
+		{id: 3, name: "Negative retries", retries: "-1", expectedErr: people.ErrInvalidConfig},
+		{id: 4, name: "Invalid backoff", backoff: "быстро", expectedErr: people.ErrInvalidConfig},
+		{id: 5, name: "Zero threshold", threshold: "0", expectedErr: people.ErrInvalidConfig},
+		{id: 6, name: "Invalid timeout", openTimeout: "-5s", expectedErr: people.ErrInvalidConfig},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := people.ParseConfig("http://0.0.0.0:4010", tc.retries, tc.backoff, tc.threshold, tc.openTimeout)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
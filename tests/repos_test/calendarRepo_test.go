@@ -0,0 +1,135 @@
+package repos_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestSaveCalendarToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewCalendarRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.ExistCheck)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SaveCalendarToken)).
+		WithArgs(1, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.SaveCalendarToken(context.Background(), 1, "hash")
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.ExistCheck)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	err = repo.SaveCalendarToken(context.Background(), 2, "hash")
+	assert.ErrorIs(t, err, repos.ErrUsrNotExists)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteCalendarToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewCalendarRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteCalendarToken)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteCalendarToken)).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteCalendarToken(context.Background(), 1)
+	assert.NoError(t, err)
+
+	err = repo.DeleteCalendarToken(context.Background(), 2)
+	assert.ErrorIs(t, err, repos.ErrCalendarTokenNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindCalendarTokenHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewCalendarRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindCalendarTokenHash)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"token_hash"}).AddRow("hash"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindCalendarTokenHash)).
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+
+	tokenHash, err := repo.FindCalendarTokenHash(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", tokenHash)
+
+	_, err = repo.FindCalendarTokenHash(context.Background(), 2)
+	assert.ErrorIs(t, err, repos.ErrCalendarTokenNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindCalendarEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewCalendarRepository(db)
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	start := from.Add(9 * time.Hour)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindCalendarEvents)).
+		WithArgs(1, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "name", "source", "start_time", "end_time"}).
+			AddRow(7, 1, "написать тестовое", "manual", start, end).
+			AddRow(8, 2, "mockTask1", "tracker", end.Add(time.Hour), nil))
+
+	events, err := repo.FindCalendarEvents(context.Background(), models.CalendarFilter{UserID: 1, From: from, To: to})
+	if err != nil {
+		t.Fatalf("FindCalendarEvents Error: %s", err)
+	}
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, "написать тестовое", events[0].TaskName)
+	assert.Equal(t, end, *events[0].End)
+	assert.Nil(t, events[1].End)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,124 @@
+package repos_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/repos/queries"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestClaimEnrichmentJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewEnrichmentRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.ClaimEnrichmentJobs)).
+		WithArgs(10, float64(60)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "passport_number", "attempts"}).
+			AddRow(1, 7, "1234 567890", 1).
+			AddRow(2, 8, "2234 567890", 3))
+
+	jobs, err := repo.ClaimEnrichmentJobs(context.Background(), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.EnrichmentJob{
+		{ID: 1, UserID: 7, PassportNumber: "1234 567890", Attempts: 1},
+		{ID: 2, UserID: 8, PassportNumber: "2234 567890", Attempts: 3},
+	}, jobs)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFinishEnrichmentJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewEnrichmentRepository(db)
+
+	runAt := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.CompleteEnrichmentJob)).
+		WithArgs(1, mockAPIUser.Surname, mockAPIUser.Name, mockAPIUser.Patronymic, mockAPIUser.Address).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.RetryEnrichmentJob)).
+		WithArgs(2, runAt, "people api is unavailable").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.FailEnrichmentJob)).
+		WithArgs(3, "people api rejected the request").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CompleteEnrichmentJob(context.Background(), 1, mockAPIUser)
+	assert.NoError(t, err)
+
+	err = repo.RetryEnrichmentJob(context.Background(), 2, runAt, "people api is unavailable")
+	assert.NoError(t, err)
+
+	err = repo.FailEnrichmentJob(context.Background(), 3, "people api rejected the request")
+	assert.NoError(t, err)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestEnrichmentCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewEnrichmentRepository(db)
+
+	expiresAt := time.Date(2024, 5, 7, 12, 0, 0, 0, time.UTC)
+	entry := models.EnrichmentCacheEntry{
+		PassportHash: "a1b2",
+		Person:       mockAPIUser,
+		ExpiresAt:    expiresAt,
+	}
+	entry.Person.Timezone = ""
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.SaveEnrichmentCache)).
+		WithArgs("a1b2", entry.Person.Surname, entry.Person.Name, entry.Person.Patronymic, entry.Person.Address, false, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindEnrichmentCache)).
+		WithArgs("a1b2").
+		WillReturnRows(sqlmock.NewRows([]string{"surname", "name", "patronymic", "address", "not_found", "expires_at"}).
+			AddRow(entry.Person.Surname, entry.Person.Name, entry.Person.Patronymic, entry.Person.Address, false, expiresAt))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindEnrichmentCache)).
+		WithArgs("c3d4").
+		WillReturnRows(sqlmock.NewRows([]string{"surname", "name", "patronymic", "address", "not_found", "expires_at"}))
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteExpiredEnrichmentCache)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	err = repo.SaveEnrichmentCache(context.Background(), entry)
+	assert.NoError(t, err)
+
+	found, err := repo.FindEnrichmentCache(context.Background(), "a1b2")
+	assert.NoError(t, err)
+	assert.Equal(t, entry, found)
+
+	_, err = repo.FindEnrichmentCache(context.Background(), "c3d4")
+	assert.ErrorIs(t, err, repos.ErrEnrichmentCacheMiss)
+
+	deleted, err := repo.DeleteExpiredEnrichmentCache(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
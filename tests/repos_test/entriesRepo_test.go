@@ -0,0 +1,538 @@
+package repos_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFindEntriesByTaskID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindEntriesByTaskID)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"task_id",
+			"user_id",
+			"start_time",
+			"end_time",
+			"closed_by",
+			"source",
+			"reason",
+			"duration_seconds"}).
+			AddRow(1, 1, 1, start, end, "pause", "tracker", "", 5400).
+			AddRow(2, 1, 1, end.Add(time.Hour), nil, "", "tracker", "", 60).
+			AddRow(3, 1, 1, start.Add(-24*time.Hour), start.Add(-23*time.Hour), "stop", "manual", "забыл включить таймер", 3600))
+
+	entries, err := repo.FindEntriesByTaskID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindEntriesByTaskID Error: %s", err)
+	}
+
+	assert.Len(t, entries, 3)
+	assert.Equal(t, start, entries[0].StartTime)
+	assert.Equal(t, int64(5400), entries[0].DurationSeconds)
+	assert.Equal(t, "pause", entries[0].ClosedBy)
+	assert.Nil(t, entries[1].EndTime)
+	assert.Equal(t, "manual", entries[2].Source)
+	assert.Equal(t, "забыл включить таймер", entries[2].Reason)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// entryTrackable - статусы, в которых сервис разрешает добавлять время
+var entryTrackable = []models.TaskStatus{models.TaskTodo, models.TaskInProgress}
+
+func TestCreateEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.TaskInProgress))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.EntryOverlapCheck)).
+		WithArgs(1, 0, start, &end).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateEntry)).
+		WithArgs(1, 1, start, &end, "забыл включить таймер").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectExec(regexp.QuoteMeta(queries.CreateEntryAudit)).
+		WithArgs(5, 1, 1, "create", nil, nil, start, &end, "забыл включить таймер").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	entry, err := repo.CreateEntry(context.Background(), models.TimeEntry{
+		TaskID:    1,
+		UserID:    1,
+		StartTime: start,
+		EndTime:   &end,
+		Reason:    "забыл включить таймер",
+	}, entryTrackable)
+	if err != nil {
+		t.Fatalf("CreateEntry Error: %s", err)
+	}
+
+	assert.Equal(t, 5, entry.ID)
+	assert.Equal(t, "manual", entry.Source)
+	assert.Equal(t, int64(5400), entry.DurationSeconds)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateEntryOverlap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.TaskInProgress))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.EntryOverlapCheck)).
+		WithArgs(1, 0, start, &end).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateEntry(context.Background(), models.TimeEntry{
+		TaskID:    1,
+		UserID:    1,
+		StartTime: start,
+		EndTime:   &end,
+		Reason:    "забыл включить таймер",
+	}, entryTrackable)
+	assert.ErrorIs(t, err, repos.ErrEntryOverlap)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// TestCreateEntryTaskDone - в завершённую задачу время вручную не добавить, как и таймером
+func TestCreateEntryTaskDone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.TaskDone))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateEntry(context.Background(), models.TimeEntry{
+		TaskID:    1,
+		UserID:    1,
+		StartTime: start,
+		EndTime:   &end,
+		Reason:    "забыл включить таймер",
+	}, entryTrackable)
+	assert.ErrorIs(t, err, repos.ErrTaskStatusConflict)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// TestImportEntryInTx - юзер, задача и сессия импорта пишутся в одной транзакции
+func TestImportEntryInTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	transactor := repos.NewTransactor(db)
+	usersRepo := repos.NewUsersRepository(db)
+	tasksRepo := repos.NewTasksRepository(db)
+	entriesRepo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateUser)).
+		WithArgs("", "Petrov", "Ivan", "", "", "", "ivan@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.ExistCheck)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateTask)).
+		WithArgs("Code review", 2, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id", "project_id", "status", "parent_id",
+			"estimate_seconds", "remaining_seconds"}).
+			AddRow(7, "Code review", 2, nil, "todo", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.EntryOverlapCheck)).
+		WithArgs(2, 0, start, &end).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateImportedEntry)).
+		WithArgs(7, 2, start, &end, "импорт из toggl, строка 2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectCommit()
+
+	var entry models.TimeEntry
+
+	err = transactor.InTx(context.Background(), func(ctx context.Context) error {
+		userID, err := usersRepo.AddUser(ctx, models.ServiceUser{Surname: "Petrov", Name: "Ivan", Email: "ivan@example.com"})
+		if err != nil {
+			return err
+		}
+
+		task, err := tasksRepo.AddTask(ctx, "Code review", userID, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		entry, err = entriesRepo.ImportEntry(ctx, models.TimeEntry{
+			TaskID:    task.ID,
+			UserID:    userID,
+			StartTime: start,
+			EndTime:   &end,
+			Reason:    "импорт из toggl, строка 2",
+		})
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ImportEntry Error: %s", err)
+	}
+
+	assert.Equal(t, 9, entry.ID)
+	assert.Equal(t, "import", entry.Source)
+	assert.Equal(t, int64(5400), entry.DurationSeconds)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestImportEntryOverlapRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	transactor := repos.NewTransactor(db)
+	entriesRepo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.EntryOverlapCheck)).
+		WithArgs(1, 0, start, &end).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = transactor.InTx(context.Background(), func(ctx context.Context) error {
+		_, err := entriesRepo.ImportEntry(ctx, models.TimeEntry{TaskID: 1, UserID: 1, StartTime: start, EndTime: &end})
+		return err
+	})
+	assert.ErrorIs(t, err, repos.ErrEntryOverlap)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	oldStart := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	oldEnd := oldStart.Add(8 * time.Hour)
+	start := oldStart
+	end := start.Add(2 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserEntry)).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "start_time", "end_time"}).AddRow(2, oldStart, oldEnd))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.EntryOverlapCheck)).
+		WithArgs(1, 5, start, &end).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateEntry)).
+		WithArgs(5, start, &end, "таймер работал всю ночь").
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "closed_by", "source"}).AddRow(2, "stop", "tracker"))
+	mock.ExpectExec(regexp.QuoteMeta(queries.CreateEntryAudit)).
+		WithArgs(5, 2, 1, "update", oldStart, &oldEnd, start, &end, "таймер работал всю ночь").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	entry, err := repo.UpdateEntry(context.Background(), models.TimeEntry{
+		ID:        5,
+		UserID:    1,
+		StartTime: start,
+		EndTime:   &end,
+		Reason:    "таймер работал всю ночь",
+	})
+	if err != nil {
+		t.Fatalf("UpdateEntry Error: %s", err)
+	}
+
+	assert.Equal(t, 2, entry.TaskID)
+	assert.Equal(t, "tracker", entry.Source)
+	assert.Equal(t, int64(7200), entry.DurationSeconds)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateEntryRunning(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserEntry)).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "start_time", "end_time"}).AddRow(2, start, nil))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateEntry(context.Background(), models.TimeEntry{
+		ID:        5,
+		UserID:    1,
+		StartTime: start,
+		EndTime:   &end,
+		Reason:    "поправить начало",
+	})
+	assert.ErrorIs(t, err, repos.ErrEntryRunning)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserEntry)).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "start_time", "end_time"}).AddRow(2, start, end))
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteEntry)).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.CreateEntryAudit)).
+		WithArgs(5, 2, 1, "delete", start, &end, nil, nil, "дубль").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.DeleteEntry(context.Background(), 5, 1, "дубль")
+	if err != nil {
+		t.Fatalf("DeleteEntry Error: %s", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteEntryNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserEntry)).
+		WithArgs(5, 1).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	err = repo.DeleteEntry(context.Background(), 5, 1, "дубль")
+	assert.ErrorIs(t, err, repos.ErrEntryNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindActiveTimers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM time_entries te JOIN tasks t ON t.id = te.task_id " +
+		"JOIN users u ON u.id = te.user_id WHERE te.end_time IS NULL AND u.id = $1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"entry_id",
+			"task_id",
+			"task_name",
+			"user_id",
+			"surname",
+			"name",
+			"patronymic",
+			"start_time",
+			"elapsed_seconds"}).
+			AddRow(3, 1, "написать тестовое", 1, "Иванов", "Иван", "Иванович", start, 5400).
+			AddRow(4, 2, "mockTask1", 1, "Иванов", "Иван", "Иванович", start.Add(time.Hour), 1800))
+
+	timers, err := repo.FindActiveTimers(context.Background(), models.ActiveTimerFilter{UserID: 1}, 1, 10)
+	if err != nil {
+		t.Fatalf("FindActiveTimers Error: %s", err)
+	}
+
+	assert.Len(t, timers, 2)
+	assert.Equal(t, "Иванов", timers[0].Surname)
+	assert.Equal(t, int64(5400), timers[0].ElapsedSeconds)
+	assert.Equal(t, 2, timers[1].TaskID)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAutoStopTimers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	now := time.Date(2024, 7, 8, 9, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 7, 5, 17, 0, 0, 0, time.UTC)
+	deadline := time.Date(2024, 7, 5, 20, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.AutoStopTimers)).
+		WithArgs(int64(43200), "20:00", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id", "start_time", "end_time"}).
+			AddRow(7, 1, 1, start, deadline))
+
+	entries, err := repo.AutoStopTimers(
+		context.Background(),
+		models.AutoStopRule{MaxDuration: 12 * time.Hour, Cutoff: "20:00"},
+		now,
+	)
+	if err != nil {
+		t.Fatalf("AutoStopTimers Error: %s", err)
+	}
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "auto", entries[0].ClosedBy)
+	assert.Equal(t, int64(10800), entries[0].DurationSeconds)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAutoStopTimersCutoffOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTimeEntriesRepository(db)
+
+	now := time.Date(2024, 7, 8, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.AutoStopTimers)).
+		WithArgs(nil, "20:00", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "user_id", "start_time", "end_time"}))
+
+	entries, err := repo.AutoStopTimers(context.Background(), models.AutoStopRule{Cutoff: "20:00"}, now)
+	if err != nil {
+		t.Fatalf("AutoStopTimers Error: %s", err)
+	}
+
+	assert.Empty(t, entries)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,146 @@
+package repos_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/repos/queries"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestCreateClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewProjectsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateClient)).
+		WithArgs("ООО Ромашка").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "ООО Ромашка"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateClient)).
+		WithArgs("ооо ромашка").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	client, err := repo.CreateClient(context.Background(), "ООО Ромашка")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Client{ID: 5, Name: "ООО Ромашка"}, client)
+
+	_, err = repo.CreateClient(context.Background(), "ооо ромашка")
+	assert.ErrorIs(t, err, repos.ErrClientExists)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewProjectsRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteClient)).
+		WithArgs(5).
+		WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteClient)).
+		WithArgs(6).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(queries.DeleteClient)).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.DeleteClient(context.Background(), 5)
+	assert.ErrorIs(t, err, repos.ErrClientHasProjects)
+
+	err = repo.DeleteClient(context.Background(), 6)
+	assert.ErrorIs(t, err, repos.ErrClientNotFound)
+
+	err = repo.DeleteClient(context.Background(), 7)
+	assert.NoError(t, err)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewProjectsRepository(db)
+
+	clientID := 5
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateProject)).
+		WithArgs("Сайт", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "client_id", "client_name", "total_seconds"}).
+			AddRow(7, "Сайт", 5, "ООО Ромашка", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateProject)).
+		WithArgs("Внутренний", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "client_id", "client_name", "total_seconds"}).
+			AddRow(8, "Внутренний", nil, "", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateProject)).
+		WithArgs("Сайт", 6).
+		WillReturnError(&pq.Error{Code: "23503"})
+
+	project, err := repo.CreateProject(context.Background(), models.ProjectRequest{Name: "Сайт", ClientID: &clientID})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, project.ID)
+	assert.Equal(t, 5, *project.ClientID)
+	assert.Equal(t, "ООО Ромашка", project.ClientName)
+
+	project, err = repo.CreateProject(context.Background(), models.ProjectRequest{Name: "Внутренний"})
+	assert.NoError(t, err)
+	assert.Nil(t, project.ClientID)
+
+	otherClientID := 6
+
+	_, err = repo.CreateProject(context.Background(), models.ProjectRequest{Name: "Сайт", ClientID: &otherClientID})
+	assert.ErrorIs(t, err, repos.ErrClientNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindProjects(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewProjectsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindProjects)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "client_id", "client_name", "total_seconds"}).
+			AddRow(8, "Приложение", 5, "ООО Ромашка", 0).
+			AddRow(7, "Сайт", 5, "ООО Ромашка", 5400))
+
+	projects, err := repo.FindProjects(context.Background(), models.ProjectFilter{ClientID: 5})
+	if err != nil {
+		t.Fatalf("FindProjects Error: %s", err)
+	}
+
+	assert.Len(t, projects, 2)
+	assert.Equal(t, "Сайт", projects[1].Name)
+	assert.Equal(t, int64(5400), projects[1].TotalSeconds)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
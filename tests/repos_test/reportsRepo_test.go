@@ -0,0 +1,258 @@
+package repos_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/repos/queries"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGetWorkload(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewReportsRepository(db)
+
+	filter := models.ReportFilter{
+		UserID:   1,
+		From:     time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		ClientID: 5,
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetWorkload)).
+		WithArgs(filter.UserID, filter.From, filter.To, 0, 5).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"name",
+			"project_id",
+			"project_name",
+			"client_id",
+			"client_name",
+			"total_seconds",
+			"auto_stopped_entries"}).
+			AddRow(2, "task two", 7, "Сайт", 5, "ООО Ромашка", 9000, 1).
+			AddRow(1, "task one", 8, "Приложение", 5, "ООО Ромашка", 150, 0))
+
+	workload, err := repo.GetWorkload(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetWorkload Error: %s", err)
+	}
+
+	assert.Len(t, workload, 2)
+	assert.Equal(t, 2, workload[0].TaskID)
+	assert.Equal(t, int64(9000), workload[0].TotalSeconds)
+	assert.Equal(t, 1, workload[0].AutoStoppedEntries)
+	assert.Equal(t, 7, *workload[0].ProjectID)
+	assert.Equal(t, "ООО Ромашка", workload[0].ClientName)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetTagWorkload(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewReportsRepository(db)
+
+	filter := models.ReportFilter{
+		UserID:    1,
+		From:      time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		ProjectID: 7,
+		GroupBy:   models.ReportGroupTag,
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTagWorkload)).
+		WithArgs(filter.UserID, filter.From, filter.To, 7, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id", "tag_name", "total_seconds", "auto_stopped_entries"}).
+			AddRow(3, "bugfix", 9000, 1).
+			AddRow(nil, "", 150, 0))
+
+	workload, err := repo.GetTagWorkload(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetTagWorkload Error: %s", err)
+	}
+
+	assert.Len(t, workload, 2)
+	assert.Equal(t, 3, *workload[0].TagID)
+	assert.Equal(t, "bugfix", workload[0].Name)
+	assert.Equal(t, 1, workload[0].AutoStoppedEntries)
+	assert.Nil(t, workload[1].TagID)
+	assert.Equal(t, int64(150), workload[1].TotalSeconds)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewReportsRepository(db)
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter := models.ReportFilter{
+		UserID:   1,
+		From:     time.Date(2024, 7, 1, 0, 0, 0, 0, moscow),
+		To:       time.Date(2024, 7, 3, 0, 0, 0, 0, moscow),
+		Location: moscow,
+		Bucket:   models.ReportBucketDay,
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetSummary)).
+		WithArgs(filter.UserID, filter.From, filter.To, "day", "Europe/Moscow", 0, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"bucket_start",
+			"bucket_end",
+			"task_id",
+			"task_name",
+			"project_id",
+			"project_name",
+			"client_id",
+			"client_name",
+			"total_seconds"}).
+			AddRow(filter.From, filter.From.AddDate(0, 0, 1), 1, "task one", nil, "", nil, "", 9000).
+			AddRow(filter.From.AddDate(0, 0, 1), filter.To, 0, "", nil, "", nil, "", 0))
+
+	summary, err := repo.GetSummary(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetSummary Error: %s", err)
+	}
+
+	assert.Len(t, summary, 2)
+	assert.Equal(t, int64(9000), summary[0].TotalSeconds)
+	assert.Equal(t, 1, summary[0].TaskID)
+	assert.Nil(t, summary[0].ProjectID)
+	assert.True(t, filter.To.Equal(summary[1].End))
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindUserTimezone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewReportsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserTimezone)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"timezone"}).AddRow("Europe/Moscow"))
+
+	timezone, err := repo.FindUserTimezone(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindUserTimezone Error: %s", err)
+	}
+
+	assert.Equal(t, "Europe/Moscow", timezone)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportsFindUserByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewReportsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserByID)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}).
+			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва", "Europe/Moscow"))
+
+	user, err := repo.FindUserByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindUserByID Error: %s", err)
+	}
+
+	assert.Equal(t, "Иванов", user.Surname)
+	assert.Equal(t, "Europe/Moscow", user.Timezone)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserByID)).
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.FindUserByID(context.Background(), 2)
+	assert.ErrorIs(t, err, repos.ErrUsrNotExists)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetTimesheet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewReportsRepository(db)
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, moscow)
+	filter := models.ReportFilter{UserID: 1, From: monday, To: monday.AddDate(0, 0, 7), Location: moscow}
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimesheet)).
+		WithArgs(filter.UserID, filter.From, filter.To, "Europe/Moscow", 0, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"name",
+			"project_id",
+			"project_name",
+			"client_id",
+			"client_name",
+			"day_start",
+			"total_seconds"}).
+			AddRow(1, "task one", 7, "Сайт", nil, "", monday, 3600).
+			AddRow(1, "task one", 7, "Сайт", nil, "", monday.AddDate(0, 0, 1), 5400))
+
+	cells, err := repo.GetTimesheet(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetTimesheet Error: %s", err)
+	}
+
+	assert.Len(t, cells, 2)
+	assert.Equal(t, int64(5400), cells[1].TotalSeconds)
+	assert.True(t, monday.AddDate(0, 0, 1).Equal(cells[1].Day))
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
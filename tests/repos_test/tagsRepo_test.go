@@ -0,0 +1,101 @@
+package repos_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/repos"
+	"EMTask/internal/repos/queries"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestCreateTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTagsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateTag)).
+		WithArgs("bugfix").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "total_seconds"}).AddRow(3, "bugfix", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateTag)).
+		WithArgs("Bugfix").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	tag, err := repo.CreateTag(context.Background(), "bugfix")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Tag{ID: 3, Name: "bugfix"}, tag)
+
+	_, err = repo.CreateTag(context.Background(), "Bugfix")
+	assert.ErrorIs(t, err, repos.ErrTagExists)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAttachTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTagsRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.AttachTag)).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.AttachTag)).
+		WithArgs(2, 3).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "fk_task_tags_task"})
+	mock.ExpectExec(regexp.QuoteMeta(queries.AttachTag)).
+		WithArgs(1, 4).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "fk_task_tags_tag"})
+
+	err = repo.AttachTag(context.Background(), 1, 3)
+	assert.NoError(t, err)
+
+	err = repo.AttachTag(context.Background(), 2, 3)
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
+
+	err = repo.AttachTag(context.Background(), 1, 4)
+	assert.ErrorIs(t, err, repos.ErrTagNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDetachTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTagsRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.DetachTag)).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.DetachTag)).
+		WithArgs(1, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DetachTag(context.Background(), 1, 3)
+	assert.NoError(t, err)
+
+	err = repo.DetachTag(context.Background(), 1, 4)
+	assert.ErrorIs(t, err, repos.ErrTagNotAttached)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -1,10 +1,12 @@
 package repos_test
 
 import (
+	"EMTask/internal/models"
 	"EMTask/internal/repos"
 	"EMTask/internal/repos/queries"
 	"context"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"regexp"
 	"testing"
@@ -22,14 +24,48 @@ func TestAddTask(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(queries.ExistCheck)).WithArgs(1).
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 	mock.ExpectQuery(regexp.QuoteMeta(queries.CreateTask)).
-		WithArgs("task name", 1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(1, "task name", 1))
+		WithArgs("task name", 1, nil, nil, 7200).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id", "project_id", "status", "parent_id",
+			"estimate_seconds", "remaining_seconds"}).
+			AddRow(1, "task name", 1, nil, "todo", nil, 7200, 7200))
 
-	task, err := repo.AddTask(context.Background(), "task name", 1)
+	estimate := int64(7200)
+
+	task, err := repo.AddTask(context.Background(), "task name", 1, nil, nil, &estimate)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, task.ID)
 	assert.Equal(t, "task name", task.Name)
 	assert.Equal(t, 1, task.UserID)
+	assert.Nil(t, task.ProjectID)
+	assert.Equal(t, models.TaskTodo, task.Status)
+	assert.Equal(t, int64(7200), *task.RemainingSeconds)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindTaskByName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindTaskByName)).
+		WithArgs(1, "Task Name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(3, "task name", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindTaskByName)).
+		WithArgs(1, "other task").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}))
+
+	task, err := repo.FindTaskByName(context.Background(), 1, "Task Name")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, task.ID)
+	assert.Equal(t, "task name", task.Name)
+
+	_, err = repo.FindTaskByName(context.Background(), 1, "other task")
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -49,9 +85,19 @@ func TestFindTaskByID(t *testing.T) {
 			"id",
 			"name",
 			"user_id",
+			"user_full_name",
 			"start_time",
-			"end_time"}).
-			AddRow(1, "task name", 1, nil, nil))
+			"end_time",
+			"state",
+			"total_seconds",
+			"project_id",
+			"project_name",
+			"tags",
+			"status",
+			"parent_id",
+			"estimate_seconds",
+			"remaining_seconds"}).
+			AddRow(1, "task name", 1, "Иванов Иван Иванович", nil, nil, "idle", 4200, 3, "Сайт", "{bugfix,review}", "in_progress", nil, 3600, -600))
 
 	task, err := repo.FindTaskByID(context.Background(), 1)
 	if err != nil {
@@ -61,6 +107,12 @@ func TestFindTaskByID(t *testing.T) {
 	assert.Equal(t, 1, task.ID)
 	assert.Equal(t, "task name", task.Name)
 	assert.Equal(t, 1, task.UserID)
+	assert.Equal(t, 3, *task.ProjectID)
+	assert.Equal(t, "Сайт", task.ProjectName)
+	assert.Equal(t, []string{"bugfix", "review"}, task.Tags)
+	assert.Equal(t, models.TaskInProgress, task.Status)
+	assert.Equal(t, int64(3600), *task.EstimateSeconds)
+	assert.Equal(t, int64(-600), *task.RemainingSeconds)
 
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
@@ -77,16 +129,31 @@ func TestFindTasksByUserID(t *testing.T) {
 	repo := repos.NewTasksRepository(db)
 
 	mock.ExpectQuery(
-		regexp.QuoteMeta("SELECT id, name, user_id, start_time, end_time FROM tasks WHERE user_id = $1")).
+		regexp.QuoteMeta("WHERE s.task_id = t.id AND s.source = 'tracker' AND s.user_id = $1")+".*"+
+			regexp.QuoteMeta("FROM tasks t JOIN users u ON u.id = t.user_id "+
+				"LEFT JOIN time_entries te ON te.task_id = t.id AND te.user_id = $2 "+
+				"LEFT JOIN projects p ON p.id = t.project_id "+
+				"WHERE t.id IN (SELECT ta.task_id FROM task_assignees ta WHERE ta.user_id = $3) GROUP BY t.id, u.id, p.id")).
+		WithArgs(1, 1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{
 			"id",
 			"name",
 			"user_id",
+			"user_full_name",
 			"start_time",
-			"end_time"}).
-			AddRow(1, "task name", 1, nil, nil))
-
-	tasks, err := repo.FindTasksByUserID(context.Background(), 1, "", "")
+			"end_time",
+			"state",
+			"total_seconds",
+			"project_id",
+			"project_name",
+			"tags",
+			"status",
+			"parent_id",
+			"estimate_seconds",
+			"remaining_seconds"}).
+			AddRow(1, "task name", 1, "Иванов Иван Иванович", nil, nil, "idle", 0, nil, "", "{}", "todo", nil, nil, nil))
+
+	tasks, err := repo.FindTasksByUserID(context.Background(), 1, "", "", models.TaskFilter{})
 	if err != nil {
 		t.Fatalf("FindTaskByID Error: %s", err)
 	}
@@ -94,6 +161,104 @@ func TestFindTasksByUserID(t *testing.T) {
 	assert.Equal(t, 1, tasks[0].ID)
 	assert.Equal(t, "task name", tasks[0].Name)
 	assert.Equal(t, 1, tasks[0].UserID)
+	assert.Equal(t, "Иванов Иван Иванович", tasks[0].UserFullName)
+	assert.Empty(t, tasks[0].Tags)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindTasksByUserIDWithTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("LOWER(fg.name) = ANY($4)\n) = $5 GROUP BY t.id, u.id, p.id")).
+		WithArgs(1, 1, 1, pq.Array([]string{"bugfix", "review"}), 2).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"name",
+			"user_id",
+			"user_full_name",
+			"start_time",
+			"end_time",
+			"state",
+			"total_seconds",
+			"project_id",
+			"project_name",
+			"tags",
+			"status",
+			"parent_id",
+			"estimate_seconds",
+			"remaining_seconds"}).
+			AddRow(1, "task name", 1, "Иванов Иван Иванович", nil, nil, "idle", 0, nil, "", "{bugfix,meeting,review}", "in_progress", nil, nil, nil))
+
+	tasks, err := repo.FindTasksByUserID(
+		context.Background(),
+		1,
+		"",
+		"",
+		models.TaskFilter{Tags: []string{"bugfix", "review"}},
+	)
+	if err != nil {
+		t.Fatalf("FindTasksByUserID Error: %s", err)
+	}
+
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, []string{"bugfix", "meeting", "review"}, tasks[0].Tags)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindTasksByUserIDWithStatuses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE ta.user_id = $3) AND t.status IN ($4,$5) GROUP BY t.id, u.id, p.id")).
+		WithArgs(1, 1, 1, models.TaskTodo, models.TaskInProgress).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"name",
+			"user_id",
+			"user_full_name",
+			"start_time",
+			"end_time",
+			"state",
+			"total_seconds",
+			"project_id",
+			"project_name",
+			"tags",
+			"status",
+			"parent_id",
+			"estimate_seconds",
+			"remaining_seconds"}).
+			AddRow(1, "task name", 1, "Иванов Иван Иванович", nil, nil, "idle", 0, nil, "", "{}", "todo", nil, nil, nil))
+
+	tasks, err := repo.FindTasksByUserID(
+		context.Background(),
+		1,
+		"",
+		"",
+		models.TaskFilter{Statuses: []models.TaskStatus{models.TaskTodo, models.TaskInProgress}},
+	)
+	if err != nil {
+		t.Fatalf("FindTasksByUserID Error: %s", err)
+	}
+
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, models.TaskTodo, tasks[0].Status)
 
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
@@ -132,15 +297,225 @@ func TestStartTimeTracker(t *testing.T) {
 
 	repo := repos.NewTasksRepository(db)
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("todo"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimerState)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"state"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.StopOtherRunningTimers)).
+		WithArgs(sqlmock.AnyArg(), 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id"}).AddRow(2))
 	mock.ExpectExec(
 		regexp.QuoteMeta(queries.StartTimeTracker)).
 		WithArgs(sqlmock.AnyArg(), 1, 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.MarkTaskInProgress)).
+		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	state, stopped, err := repo.StartTimeTracker(
+		context.Background(),
+		1,
+		1,
+		[]models.TimerState{models.TimerIdle, models.TimerStopped},
+		[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+		models.TimerPolicySwitch,
+	)
+	if err != nil {
+		t.Fatalf("StartTimeTracker Error: %s", err)
+	}
+
+	assert.Equal(t, models.TimerIdle, state)
+	assert.Equal(t, []int{2}, stopped)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
 
-	err = repo.StartTimeTracker(context.Background(), 1, 1)
+func TestStartTimeTrackerConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
 	if err != nil {
-		t.Fatalf("DeleteTaskByID Error: %s", err)
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("todo"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimerState)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow("running"))
+	mock.ExpectRollback()
+
+	state, _, err := repo.StartTimeTracker(
+		context.Background(),
+		1,
+		1,
+		[]models.TimerState{models.TimerIdle, models.TimerStopped},
+		[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+		models.TimerPolicySwitch,
+	)
+	assert.ErrorIs(t, err, repos.ErrTimerConflict)
+	assert.Equal(t, models.TimerRunning, state)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
+}
+
+func TestStartTimeTrackerRejectPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("todo"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimerState)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow("stopped"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindOtherRunningTimers)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id"}).AddRow(2))
+	mock.ExpectRollback()
+
+	_, running, err := repo.StartTimeTracker(
+		context.Background(),
+		1,
+		1,
+		[]models.TimerState{models.TimerIdle, models.TimerStopped},
+		[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+		models.TimerPolicyReject,
+	)
+	assert.ErrorIs(t, err, repos.ErrAnotherTimerRunning)
+	assert.Equal(t, []int{2}, running)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStartTimeTrackerNotTrackable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("done"))
+	mock.ExpectRollback()
+
+	_, _, err = repo.StartTimeTracker(
+		context.Background(),
+		1,
+		1,
+		[]models.TimerState{models.TimerIdle, models.TimerStopped},
+		[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+		models.TimerPolicySwitch,
+	)
+	assert.ErrorIs(t, err, repos.ErrTaskStatusConflict)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPauseTimeTracker(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("todo"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimerState)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow("running"))
+	mock.ExpectExec(
+		regexp.QuoteMeta(queries.PauseTimeTracker)).
+		WithArgs(sqlmock.AnyArg(), 1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	state, err := repo.PauseTimeTracker(context.Background(), 1, 1, []models.TimerState{models.TimerRunning})
+	if err != nil {
+		t.Fatalf("PauseTimeTracker Error: %s", err)
+	}
+
+	assert.Equal(t, models.TimerRunning, state)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestResumeTimeTracker(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("todo"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimerState)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow("paused"))
+	mock.ExpectExec(
+		regexp.QuoteMeta(queries.StartTimeTracker)).
+		WithArgs(sqlmock.AnyArg(), 1, 1).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.MarkTaskInProgress)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	state, stopped, err := repo.ResumeTimeTracker(
+		context.Background(),
+		1,
+		1,
+		[]models.TimerState{models.TimerPaused},
+		[]models.TaskStatus{models.TaskTodo, models.TaskInProgress},
+		models.TimerPolicyParallel,
+	)
+	if err != nil {
+		t.Fatalf("ResumeTimeTracker Error: %s", err)
+	}
+
+	assert.Equal(t, models.TimerPaused, state)
+	assert.Empty(t, stopped)
 
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
@@ -156,16 +531,26 @@ func TestStopTimeTracker(t *testing.T) {
 
 	repo := repos.NewTasksRepository(db)
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserTaskStatus)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("todo"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetTimerState)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow("running"))
 	mock.ExpectExec(
 		regexp.QuoteMeta(queries.StopTimeTracker)).
 		WithArgs(sqlmock.AnyArg(), 1, 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
-	err = repo.StopTimeTracker(context.Background(), 1, 1)
+	state, err := repo.StopTimeTracker(context.Background(), 1, 1, []models.TimerState{models.TimerRunning, models.TimerPaused})
 	if err != nil {
-		t.Fatalf("DeleteTaskByID Error: %s", err)
+		t.Fatalf("StopTimeTracker Error: %s", err)
 	}
 
+	assert.Equal(t, models.TimerRunning, state)
+
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
@@ -182,15 +567,29 @@ func TestGetAllTasks(t *testing.T) {
 
 	mock.ExpectQuery(
 		regexp.QuoteMeta(queries.GetAllTasks)).
+		WithArgs(pq.Array([]string{"bugfix"}), pq.Array([]models.TaskStatus{models.TaskDone})).
 		WillReturnRows(sqlmock.NewRows([]string{
 			"id",
 			"name",
 			"user_id",
+			"user_full_name",
 			"start_time",
-			"end_time"}).
-			AddRow(1, "task name", 1, nil, nil))
-
-	tasks, err := repo.GetAllTasks(context.Background())
+			"end_time",
+			"state",
+			"total_seconds",
+			"project_id",
+			"project_name",
+			"tags",
+			"status",
+			"parent_id",
+			"estimate_seconds",
+			"remaining_seconds"}).
+			AddRow(1, "task name", 1, "Иванов Иван Иванович", nil, nil, "idle", 0, nil, "", "{bugfix}", "done", nil, nil, nil))
+
+	tasks, err := repo.GetAllTasks(
+		context.Background(),
+		models.TaskFilter{Tags: []string{"bugfix"}, Statuses: []models.TaskStatus{models.TaskDone}},
+	)
 	if err != nil {
 		t.Fatalf("DeleteTaskByID Error: %s", err)
 	}
@@ -199,6 +598,367 @@ func TestGetAllTasks(t *testing.T) {
 	assert.Equal(t, 1, tasks[0].ID)
 	assert.Equal(t, "task name", tasks[0].Name)
 	assert.Equal(t, 1, tasks[0].UserID)
+	assert.Equal(t, "Иванов Иван Иванович", tasks[0].UserFullName)
+	assert.Equal(t, []string{"bugfix"}, tasks[0].Tags)
+	assert.Equal(t, models.TaskDone, tasks[0].Status)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetTaskProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	projectID := 7
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskProject)).
+		WithArgs(1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskProject)).
+		WithArgs(1, 9).
+		WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskProject)).
+		WithArgs(2, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.SetTaskProject(context.Background(), 1, &projectID)
+	assert.NoError(t, err)
+
+	missingProjectID := 9
+
+	err = repo.SetTaskProject(context.Background(), 1, &missingProjectID)
+	assert.ErrorIs(t, err, repos.ErrProjectNotFound)
+
+	err = repo.SetTaskProject(context.Background(), 2, nil)
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetTaskStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockTaskStatus)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("in_progress"))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskStatus)).
+		WithArgs(1, models.TaskDone).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.StopTaskTimers)).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockTaskStatus)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("archived"))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockTaskStatus)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	mock.ExpectRollback()
+
+	from := []models.TaskStatus{models.TaskTodo, models.TaskInProgress}
+
+	status, err := repo.SetTaskStatus(context.Background(), 1, models.TaskDone, from, true)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskInProgress, status)
+
+	status, err = repo.SetTaskStatus(context.Background(), 2, models.TaskDone, from, true)
+	assert.ErrorIs(t, err, repos.ErrTaskStatusConflict)
+	assert.Equal(t, models.TaskArchived, status)
+
+	_, err = repo.SetTaskStatus(context.Background(), 3, models.TaskDone, from, true)
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAssignTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.AssignTask)).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.AssignTask)).
+		WithArgs(5, 2).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "fk_task_assignees_task"})
+	mock.ExpectExec(regexp.QuoteMeta(queries.AssignTask)).
+		WithArgs(1, 9).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "fk_task_assignees_user"})
+
+	err = repo.AssignTask(context.Background(), 1, 2)
+	assert.NoError(t, err)
+
+	err = repo.AssignTask(context.Background(), 5, 2)
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
+
+	err = repo.AssignTask(context.Background(), 1, 9)
+	assert.ErrorIs(t, err, repos.ErrUsrNotExists)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUnassignTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockTaskOwner)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.UnassignTask)).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.StopAssigneeTimer)).
+		WithArgs(sqlmock.AnyArg(), 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockTaskOwner)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockTaskOwner)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.UnassignTask)).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.UnassignTask(context.Background(), 1, 2)
+	assert.NoError(t, err)
+
+	err = repo.UnassignTask(context.Background(), 1, 1)
+	assert.ErrorIs(t, err, repos.ErrTaskOwnerAssignee)
+
+	err = repo.UnassignTask(context.Background(), 1, 3)
+	assert.ErrorIs(t, err, repos.ErrTaskNotAssigned)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindTaskAssignees(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindTaskAssignees)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "user_full_name", "total_seconds"}).
+			AddRow(1, "Иванов Иван Иванович", 3600).
+			AddRow(2, "Петров Пётр Петрович", 0))
+
+	assignees, err := repo.FindTaskAssignees(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindTaskAssignees Error: %s", err)
+	}
+
+	assert.Equal(t, []models.TaskAssignee{
+		{UserID: 1, UserFullName: "Иванов Иван Иванович", TotalSeconds: 3600},
+		{UserID: 2, UserFullName: "Петров Пётр Петрович", TotalSeconds: 0},
+	}, assignees)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetTaskParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	parentID, childID := 5, 7
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(queries.LockTaskHierarchy)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.TaskHasAncestor)).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskParent)).
+		WithArgs(1, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(queries.LockTaskHierarchy)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.TaskHasAncestor)).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(queries.LockTaskHierarchy)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.TaskHasAncestor)).
+		WithArgs(5, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskParent)).
+		WithArgs(2, 5).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "fk_tasks_parent"})
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(queries.LockTaskHierarchy)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskParent)).
+		WithArgs(3, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.SetTaskParent(context.Background(), 1, &parentID)
+	assert.NoError(t, err)
+
+	err = repo.SetTaskParent(context.Background(), 1, &childID)
+	assert.ErrorIs(t, err, repos.ErrTaskCycle)
+
+	err = repo.SetTaskParent(context.Background(), 2, &parentID)
+	assert.ErrorIs(t, err, repos.ErrParentTaskNotFound)
+
+	err = repo.SetTaskParent(context.Background(), 3, nil)
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindTaskTree(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindTaskTree)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "parent_id", "status", "total_seconds", "rollup_seconds"}).
+			AddRow(1, "эпик", nil, "in_progress", 3600, 4200).
+			AddRow(2, "подзадача", 1, "done", 600, 600))
+
+	nodes, err := repo.FindTaskTree(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindTaskTree Error: %s", err)
+	}
+
+	assert.Len(t, nodes, 2)
+	assert.Nil(t, nodes[0].ParentID)
+	assert.Equal(t, int64(4200), nodes[0].RollupSeconds)
+	assert.Equal(t, 1, *nodes[1].ParentID)
+	assert.Equal(t, models.TaskDone, nodes[1].Status)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetTaskEstimate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	estimate := int64(7200)
+
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskEstimate)).
+		WithArgs(1, 7200).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.SetTaskEstimate)).
+		WithArgs(2, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.SetTaskEstimate(context.Background(), 1, &estimate)
+	assert.NoError(t, err)
+
+	err = repo.SetTaskEstimate(context.Background(), 2, nil)
+	assert.ErrorIs(t, err, repos.ErrTaskNotFound)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindOverBudgetTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewTasksRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindOverBudgetTasks)).
+		WithArgs(80).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id", "user_full_name", "status",
+			"estimate_seconds", "total_seconds", "remaining_seconds", "used_percent"}).
+			AddRow(1, "task name", 1, "Иванов Иван Иванович", "in_progress", 3600, 4500, -900, 125.0).
+			AddRow(2, "ревью", 1, "Иванов Иван Иванович", "todo", 3600, 3240, 360, 90.0))
+
+	tasks, err := repo.FindOverBudgetTasks(context.Background(), 80)
+	if err != nil {
+		t.Fatalf("FindOverBudgetTasks Error: %s", err)
+	}
+
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, int64(-900), tasks[0].RemainingSeconds)
+	assert.Equal(t, 125.0, tasks[0].UsedPercent)
+	assert.Equal(t, models.TaskTodo, tasks[1].Status)
 
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
@@ -5,7 +5,9 @@ import (
 	"EMTask/internal/repos"
 	"EMTask/internal/repos/queries"
 	"context"
+	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"regexp"
 	"testing"
 )
@@ -17,6 +19,7 @@ var mockUser = models.User{
 	Name:           "Иван",
 	Patronymic:     "Иванович",
 	Address:        "г. Москва, ул. Ленина, д. 5, кв. 1",
+	Timezone:       "Europe/Moscow",
 }
 
 var mockServiceUser = models.ServiceUser{
@@ -25,6 +28,7 @@ var mockServiceUser = models.ServiceUser{
 	Name:        "Иван",
 	Patronymic:  "Иванович",
 	Address:     "г. Москва, ул. Ленина, д. 5, кв. 1",
+	Timezone:    "Europe/Moscow",
 }
 
 var mockAPIUser = models.APIResponse{
@@ -32,6 +36,7 @@ var mockAPIUser = models.APIResponse{
 	Name:       "Иван",
 	Patronymic: "Иванович",
 	Address:    "г. Москва, ул. Ленина, д. 5, кв. 1",
+	Timezone:   "Europe/Moscow",
 }
 
 func TestGetAllUsers(t *testing.T) {
@@ -43,12 +48,16 @@ func TestGetAllUsers(t *testing.T) {
 
 	repo := repos.NewUsersRepository(db)
 
-	mock.ExpectQuery("SELECT id, passport_number, surname, name, patronymic, address FROM users").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address"}).
-			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1").
-			AddRow(2, "2234 567890", "Иванов", "Виктор", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, passport_number, surname, name, patronymic, address, timezone, " +
+		"enrichment_status, COALESCE(enrichment_error, '') FROM users WHERE enrichment_status = $1")).
+		WithArgs(models.EnrichmentDone).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "passport_number", "surname", "name", "patronymic", "address", "timezone", "enrichment_status", "enrichment_error",
+		}).
+			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1", "UTC", "done", "").
+			AddRow(2, "2234 567890", "Иванов", "Виктор", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1", "Europe/Moscow", "done", ""))
 
-	users, err := repo.GetAllUsers(context.Background(), models.UserFilter{}, 1, 10)
+	users, err := repo.GetAllUsers(context.Background(), models.UserFilter{EnrichmentStatus: models.EnrichmentDone}, 1, 10)
 	if err != nil {
 		t.Fatalf("error fetching users: %s", err)
 	}
@@ -82,6 +91,8 @@ func TestAddUser(t *testing.T) {
 			mockUser.Name,
 			mockUser.Patronymic,
 			mockUser.Address,
+			mockUser.Timezone,
+			"",
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"userID"}).AddRow(1))
 
@@ -102,6 +113,222 @@ func TestAddUser(t *testing.T) {
 	}
 }
 
+func TestAddPendingUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewUsersRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.CreatePendingUser)).
+		WithArgs(mockUser.PassportNumber, mockUser.Timezone).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	userID, err := repo.AddPendingUser(context.Background(), mockUser.PassportNumber, mockUser.Timezone)
+	if err != nil {
+		t.Fatalf("AddPendingUser Error: %s", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	if userID != mockUser.ID {
+		t.Errorf("unexpected ID: got %v, want %v", userID, mockUser.ID)
+	}
+}
+
+func TestFindUserByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewUsersRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserByID)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}).
+			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1", "Europe/Moscow"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserByID)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}))
+
+	user, err := repo.FindUserByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FindUserByID Error: %s", err)
+	}
+
+	if user != mockUser {
+		t.Errorf("unexpected user: got %+v, want %+v", user, mockUser)
+	}
+
+	_, err = repo.FindUserByID(context.Background(), 2)
+	if !errors.Is(err, repos.ErrUsrNotExists) {
+		t.Errorf("unexpected error: got %v, want %v", err, repos.ErrUsrNotExists)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLockUserByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewUsersRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserByID)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}).
+			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1", "Europe/Moscow"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockUserByID)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}))
+
+	user, err := repo.LockUserByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("LockUserByID Error: %s", err)
+	}
+
+	if user != mockUser {
+		t.Errorf("unexpected user: got %+v, want %+v", user, mockUser)
+	}
+
+	_, err = repo.LockUserByID(context.Background(), 2)
+	if !errors.Is(err, repos.ErrUsrNotExists) {
+		t.Errorf("unexpected error: got %v, want %v", err, repos.ErrUsrNotExists)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestApplyUserRefresh(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewUsersRepository(db)
+
+	changes := []models.FieldChange{
+		{Field: "surname", Old: "Петров", New: "Иванов"},
+		{Field: "address", Old: "г. Тверь", New: "г. Москва, ул. Ленина, д. 5, кв. 1"},
+	}
+
+	updateUser := regexp.QuoteMeta("UPDATE users SET enrichment_status = $1, enrichment_error = $2, " +
+		"surname = $3, address = $4 WHERE id = $5")
+
+	mock.ExpectExec(updateUser).
+		WithArgs(models.EnrichmentDone, nil, "Иванов", "г. Москва, ул. Ленина, д. 5, кв. 1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(queries.InsertUserChanges)).
+		WithArgs(
+			1,
+			pq.Array([]string{"surname", "address"}),
+			pq.Array([]string{"Петров", "г. Тверь"}),
+			pq.Array([]string{"Иванов", "г. Москва, ул. Ленина, д. 5, кв. 1"}),
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(updateUser).
+		WithArgs(models.EnrichmentDone, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.ApplyUserRefresh(context.Background(), 1, changes)
+	if err != nil {
+		t.Fatalf("ApplyUserRefresh Error: %s", err)
+	}
+
+	err = repo.ApplyUserRefresh(context.Background(), 2, changes)
+	if !errors.Is(err, repos.ErrUsrNotExists) {
+		t.Errorf("unexpected error: got %v, want %v", err, repos.ErrUsrNotExists)
+	}
+
+	err = repo.ApplyUserRefresh(context.Background(), 1, []models.FieldChange{{Field: "passport_number"}})
+	if err == nil {
+		t.Errorf("expected error for a field that is not refreshed")
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindUserByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewUsersRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserByEmail)).
+		WithArgs("Ivan@Example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}).
+			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1", "Europe/Moscow"))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUserByEmail)).
+		WithArgs("nobody@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}))
+
+	user, err := repo.FindUserByEmail(context.Background(), "Ivan@Example.com")
+	if err != nil {
+		t.Fatalf("FindUserByEmail Error: %s", err)
+	}
+
+	if user != mockUser {
+		t.Errorf("unexpected user: got %+v, want %+v", user, mockUser)
+	}
+
+	_, err = repo.FindUserByEmail(context.Background(), "nobody@example.com")
+	if !errors.Is(err, repos.ErrUsrNotExists) {
+		t.Errorf("unexpected error: got %v, want %v", err, repos.ErrUsrNotExists)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindUsersByFullName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error %s", err)
+	}
+	defer db.Close()
+
+	repo := repos.NewUsersRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.FindUsersByFullName)).
+		WithArgs("Иван Иванов").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "passport_number", "surname", "name", "patronymic", "address", "timezone"}).
+			AddRow(1, "1234 567890", "Иванов", "Иван", "Иванович", "г. Москва, ул. Ленина, д. 5, кв. 1", "Europe/Moscow").
+			AddRow(3, "3234 567890", "Иванов", "Иван", "Петрович", "г. Москва, ул. Ленина, д. 7, кв. 2", "UTC"))
+
+	users, err := repo.FindUsersByFullName(context.Background(), "Иван Иванов")
+	if err != nil {
+		t.Fatalf("FindUsersByFullName Error: %s", err)
+	}
+
+	if len(users) != 2 || users[0].ID != 1 || users[1].ID != 3 {
+		t.Errorf("unexpected users: %+v", users)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestUpdateUser(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -118,6 +345,7 @@ func TestUpdateUser(t *testing.T) {
 			mockUser.Name,
 			mockUser.Patronymic,
 			mockUser.Address,
+			mockUser.Timezone,
 		).
 		WillReturnRows(
 			sqlmock.NewRows([]string{
@@ -127,6 +355,9 @@ func TestUpdateUser(t *testing.T) {
 				"name",
 				"patronymic",
 				"address",
+				"timezone",
+				"enrichment_status",
+				"enrichment_error",
 			}).AddRow(
 				mockUser.ID,
 				mockUser.PassportNumber,
@@ -134,6 +365,9 @@ func TestUpdateUser(t *testing.T) {
 				mockUser.Name,
 				mockUser.Patronymic,
 				mockUser.Address,
+				mockUser.Timezone,
+				"done",
+				"",
 			))
 
 	user, err := repo.UpdateUser(
@@ -152,6 +386,10 @@ func TestUpdateUser(t *testing.T) {
 	if user.ID != mockUser.ID {
 		t.Errorf("unexpected ID: got %v, want %v", user.ID, mockUser.ID)
 	}
+
+	if user.EnrichmentStatus != models.EnrichmentDone {
+		t.Errorf("unexpected enrichment status: got %v, want %v", user.EnrichmentStatus, models.EnrichmentDone)
+	}
 }
 
 func TestDeleteUser(t *testing.T) {
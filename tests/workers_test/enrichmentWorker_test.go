@@ -0,0 +1,194 @@
+package workers_test
+
+import (
+	"EMTask/internal/enrichers"
+	"EMTask/internal/models"
+	"EMTask/internal/workers"
+	"EMTask/pkg/people"
+	"EMTask/tests/mocks/reposmocks"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// fakeEnricher - провайдер, который отвечает person или err и запоминает запрошенные паспорта
+type fakeEnricher struct {
+	person models.APIResponse
+	err    error
+	calls  []string
+}
+
+func (fe *fakeEnricher) Enrich(_ context.Context, passport string) (models.APIResponse, error) {
+	fe.calls = append(fe.calls, passport)
+	return fe.person, fe.err
+}
+
+func TestParseEnrichmentConfig(t *testing.T) {
+	testCases := []struct {
+		id          int
+		name        string
+		workers     string
+		maxAttempts string
+		interval    string
+		backoff     string
+		expected    workers.EnrichmentConfig
+		expectedErr error
+	}{
+		{
+			id:   1,
+			name: "Defaults",
+			expected: workers.EnrichmentConfig{
+				Workers:     2,
+				MaxAttempts: 5,
+				Interval:    time.Second,
+				Backoff:     10 * time.Second,
+			},
+		},
+		{
+			id:          2,
+			name:        "Custom",
+			workers:     "4",
+			maxAttempts: "3",
+			interval:    "500ms",
+			backoff:     "1m",
+			expected: workers.EnrichmentConfig{
+				Workers:     4,
+				MaxAttempts: 3,
+				Interval:    500 * time.Millisecond,
+				Backoff:     time.Minute,
+			},
+		},
+		{id: 3, name: "Zero workers", workers: "0", expectedErr: workers.ErrInvalidEnrichmentConfig},
+		{id: 4, name: "Invalid attempts", maxAttempts: "пять", expectedErr: workers.ErrInvalidEnrichmentConfig},
+		{id: 5, name: "Invalid interval", interval: "0s", expectedErr: workers.ErrInvalidEnrichmentConfig},
+		{id: 6, name: "Invalid backoff", backoff: "-1s", expectedErr: workers.ErrInvalidEnrichmentConfig},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := workers.ParseEnrichmentConfig(tc.workers, tc.maxAttempts, tc.interval, tc.backoff)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func TestEnrichmentWorkerPoll(t *testing.T) {
+	person := models.APIResponse{
+		Surname:    "Иванов",
+		Name:       "Иван",
+		Patronymic: "Иванович",
+		Address:    "г. Москва, ул. Ленина, д. 5, кв. 1",
+	}
+
+	cfg := workers.EnrichmentConfig{Workers: 1, MaxAttempts: 3, Interval: time.Second, Backoff: 10 * time.Second}
+
+	testCases := []struct {
+		id          int
+		name        string
+		job         models.EnrichmentJob
+		apiErr      error
+		expectCall  string
+		expectDelay time.Duration
+	}{
+		{
+			id:         1,
+			name:       "Success",
+			job:        models.EnrichmentJob{ID: 1, UserID: 7, PassportNumber: "1234 567890", Attempts: 1},
+			expectCall: "CompleteEnrichmentJob",
+		},
+		{
+			id:          2,
+			name:        "Unavailable is retried",
+			job:         models.EnrichmentJob{ID: 1, UserID: 7, PassportNumber: "1234 567890", Attempts: 1},
+			apiErr:      people.ErrUnavailable,
+			expectCall:  "RetryEnrichmentJob",
+			expectDelay: 10 * time.Second,
+		},
+		{
+			id:          3,
+			name:        "Backoff doubles",
+			job:         models.EnrichmentJob{ID: 1, UserID: 7, PassportNumber: "1234 567890", Attempts: 2},
+			apiErr:      people.ErrCircuitOpen,
+			expectCall:  "RetryEnrichmentJob",
+			expectDelay: 20 * time.Second,
+		},
+		{
+			id:         4,
+			name:       "Attempts exhausted",
+			job:        models.EnrichmentJob{ID: 1, UserID: 7, PassportNumber: "1234 567890", Attempts: 3},
+			apiErr:     people.ErrUnavailable,
+			expectCall: "FailEnrichmentJob",
+		},
+		{
+			id:         5,
+			name:       "Not found is not retried",
+			job:        models.EnrichmentJob{ID: 1, UserID: 7, PassportNumber: "1234 567890", Attempts: 1},
+			apiErr:     enrichers.ErrPersonNotFound,
+			expectCall: "FailEnrichmentJob",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			zapLogger, err := zap.NewProduction()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repo := new(reposmocks.MockEnrichmentRepo)
+			api := &fakeEnricher{person: person, err: tc.apiErr}
+
+			repo.On("ClaimEnrichmentJobs", mock.Anything, 10, 6*time.Minute).Return([]models.EnrichmentJob{tc.job}, nil)
+			repo.On("CompleteEnrichmentJob", mock.Anything, tc.job.ID, person).Return(nil)
+			repo.On("RetryEnrichmentJob", mock.Anything, tc.job.ID, mock.AnythingOfType("time.Time"), mock.Anything).Return(nil)
+			repo.On("FailEnrichmentJob", mock.Anything, tc.job.ID, mock.Anything).Return(nil)
+
+			worker := workers.NewEnrichmentWorker(repo, api, cfg, zapLogger.Sugar())
+
+			before := time.Now()
+
+			n, err := worker.Poll(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, n)
+			assert.Equal(t, []string{"1234 567890"}, api.calls)
+
+			for _, method := range []string{"CompleteEnrichmentJob", "RetryEnrichmentJob", "FailEnrichmentJob"} {
+				if method == tc.expectCall {
+					repo.AssertNumberOfCalls(t, method, 1)
+				} else {
+					repo.AssertNotCalled(t, method)
+				}
+			}
+
+			if tc.expectCall == "RetryEnrichmentJob" {
+				runAt := repo.Calls[1].Arguments.Get(2).(time.Time)
+				assert.WithinDuration(t, before.Add(tc.expectDelay), runAt, time.Second)
+				assert.Equal(t, tc.apiErr.Error(), repo.Calls[1].Arguments.Get(3))
+			}
+		})
+	}
+}
+
+func TestEnrichmentWorkerClaimError(t *testing.T) {
+	zapLogger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := new(reposmocks.MockEnrichmentRepo)
+	claimErr := errors.New("Эта ошибка ломает базу")
+
+	repo.On("ClaimEnrichmentJobs", mock.Anything, 10, 6*time.Minute).Return([]models.EnrichmentJob(nil), claimErr)
+
+	worker := workers.NewEnrichmentWorker(repo, &fakeEnricher{}, workers.EnrichmentConfig{Workers: 1}, zapLogger.Sugar())
+
+	n, err := worker.Poll(context.Background())
+	assert.ErrorIs(t, err, claimErr)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,38 @@
+package workers_test
+
+import (
+	"EMTask/internal/workers"
+	"EMTask/tests/mocks/reposmocks"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestCachePurgerPurge(t *testing.T) {
+	zapLogger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := new(reposmocks.MockEnrichmentRepo)
+	purgeErr := errors.New("Эта ошибка ломает базу")
+
+	ctxType := mock.AnythingOfType("*context.timerCtx")
+
+	repo.On("DeleteExpiredEnrichmentCache", ctxType).Return(int64(3), nil).Once()
+	repo.On("DeleteExpiredEnrichmentCache", ctxType).Return(int64(0), purgeErr).Once()
+
+	purger := workers.NewCachePurger(repo, zapLogger.Sugar())
+
+	n, err := purger.Purge(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	_, err = purger.Purge(context.Background())
+	assert.ErrorIs(t, err, purgeErr)
+
+	repo.AssertExpectations(t)
+}
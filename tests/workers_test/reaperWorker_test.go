@@ -0,0 +1,110 @@
+package workers_test
+
+import (
+	"EMTask/internal/models"
+	"EMTask/internal/workers"
+	"EMTask/tests/mocks/reposmocks"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestParseAutoStopRule(t *testing.T) {
+	testCases := []struct {
+		id               int
+		name             string
+		maxDuration      string
+		cutoff           string
+		interval         string
+		expectedRule     models.AutoStopRule
+		expectedInterval time.Duration
+		expectedError    error
+	}{
+		{
+			id:               1,
+			name:             "Disabled",
+			expectedInterval: 5 * time.Minute,
+		},
+		{
+			id:               2,
+			name:             "Max duration and cutoff",
+			maxDuration:      "12h",
+			cutoff:           "20:00",
+			interval:         "1m",
+			expectedRule:     models.AutoStopRule{MaxDuration: 12 * time.Hour, Cutoff: "20:00"},
+			expectedInterval: time.Minute,
+		},
+		{
+			id:            3,
+			name:          "Invalid max duration",
+			maxDuration:   "12 часов",
+			expectedError: workers.ErrInvalidReaperConfig,
+		},
+		{
+			id:            4,
+			name:          "Invalid cutoff",
+			cutoff:        "25:00",
+			expectedError: workers.ErrInvalidReaperConfig,
+		},
+		{
+			id:            5,
+			name:          "Invalid interval",
+			interval:      "0s",
+			expectedError: workers.ErrInvalidReaperConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, interval, err := workers.ParseAutoStopRule(tc.maxDuration, tc.cutoff, tc.interval)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.expectedRule, rule)
+			assert.Equal(t, tc.expectedInterval, interval)
+		})
+	}
+}
+
+func TestTimerReaperSweep(t *testing.T) {
+	zapLogger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule := models.AutoStopRule{MaxDuration: 12 * time.Hour}
+	end := time.Date(2024, 7, 6, 5, 0, 0, 0, time.UTC)
+	stopped := []models.TimeEntry{
+		{ID: 7, TaskID: 1, UserID: 1, StartTime: end.Add(-12 * time.Hour), EndTime: &end, ClosedBy: "auto"},
+	}
+
+	mockEntriesRepo := new(reposmocks.MockTimeEntriesRepo)
+	mockEntriesRepo.On(
+		"AutoStopTimers",
+		mock.AnythingOfType("*context.timerCtx"),
+		rule,
+		mock.AnythingOfType("time.Time"),
+	).Return(stopped, nil).Once()
+	mockEntriesRepo.On(
+		"AutoStopTimers",
+		mock.AnythingOfType("*context.timerCtx"),
+		rule,
+		mock.AnythingOfType("time.Time"),
+	).Return([]models.TimeEntry(nil), errors.New("эта ошибка ломает repo")).Once()
+
+	reaper := workers.NewTimerReaper(mockEntriesRepo, rule, time.Minute, zapLogger.Sugar())
+
+	assert.True(t, reaper.Enabled())
+
+	entries, err := reaper.Sweep(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, stopped, entries)
+
+	_, err = reaper.Sweep(context.Background())
+	assert.Error(t, err)
+
+	mockEntriesRepo.AssertNumberOfCalls(t, "AutoStopTimers", 2)
+}